@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/danhigham/telecharm/internal/config"
 	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/notify"
 	"github.com/danhigham/telecharm/internal/state"
 	"github.com/danhigham/telecharm/internal/telegram"
 	"github.com/danhigham/telecharm/internal/ui"
@@ -24,15 +26,26 @@ func main() {
 	cfgPath := filepath.Join(cfgDir, "config.yaml")
 
 	cfg, err := config.Load(cfgPath)
+	if err == nil {
+		err = ui.CheckKeys(cfg.Keys)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config from %s: %v\n", cfgPath, err)
-		fmt.Fprintf(os.Stderr, "\nCreate the config file with:\n")
-		fmt.Fprintf(os.Stderr, "  mkdir -p %s\n", cfgDir)
-		fmt.Fprintf(os.Stderr, "  cat > %s << 'EOF'\n", cfgPath)
-		fmt.Fprintf(os.Stderr, "telegram:\n  api_id: YOUR_API_ID\n  api_hash: \"YOUR_API_HASH\"\nEOF\n")
-		fmt.Fprintf(os.Stderr, "\nGet API credentials from https://my.telegram.org\n")
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "\nCreate the config file with:\n")
+			fmt.Fprintf(os.Stderr, "  mkdir -p %s\n", cfgDir)
+			fmt.Fprintf(os.Stderr, "  cat > %s << 'EOF'\n", cfgPath)
+			fmt.Fprintf(os.Stderr, "telegram:\n  api_id: YOUR_API_ID\n  api_hash: \"YOUR_API_HASH\"\nEOF\n")
+			fmt.Fprintf(os.Stderr, "\nGet API credentials from https://my.telegram.org\n")
+		}
 		os.Exit(1)
 	}
+	theme, err := config.LoadTheme(filepath.Join(cfgDir, "themes"), cfg.ThemeName())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load theme: %v\n", err)
+		os.Exit(1)
+	}
+	ui.SetTheme(theme)
 
 	// Setup logging to file
 	logPath := filepath.Join(cfgDir, "telecharm.log")
@@ -75,6 +88,13 @@ func main() {
 	// Wire drawFunc so store changes trigger re-render
 	store.SetDrawFunc(app.DrawFunc())
 
+	// Notify about messages arriving in background chats
+	notifier := notify.New(cfg.Notifications, notify.NewBackend(cfg.Notifications.NotifyMethod(), app.WriteRaw))
+	notifier.SetOnError(func(err error) {
+		logger.Warn("notification failed", zap.Error(err))
+	})
+	store.SetNotifyFunc(notifier.OnMessage)
+
 	// Wire auth callbacks to send messages into Bubble Tea
 	authFlow.OnPhoneRequested = func() {
 		app.Send(ui.AuthRequestMsg{Stage: domain.AuthStatePhone})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,8 +13,16 @@ type Config struct {
 	Telegram TelegramConfig `yaml:"telegram"`
 	LogLevel string         `yaml:"log_level"`
 	Bubbles  *bool          `yaml:"bubbles,omitempty"`
+	Theme    string         `yaml:"theme,omitempty"` // built-in or themes/<name>.yaml
+
+	Notifications NotificationsConfig `yaml:"notifications,omitempty"`
+	Keys          KeysConfig          `yaml:"keys,omitempty"`
+	RecentEmoji   []string            `yaml:"recent_emoji,omitempty"`
 }
 
+// maxRecentEmoji caps the recently used emoji list.
+const maxRecentEmoji = 24
+
 // BubblesEnabled returns the bubbles preference, defaulting to true.
 func (c *Config) BubblesEnabled() bool {
 	if c.Bubbles == nil {
@@ -27,6 +36,17 @@ func (c *Config) SetBubbles(v bool) {
 	c.Bubbles = &v
 }
 
+// AddRecentEmoji moves an emoji to the front of the recently used list.
+func (c *Config) AddRecentEmoji(e string) {
+	recent := []string{e}
+	for _, r := range c.RecentEmoji {
+		if r != e && len(recent) < maxRecentEmoji {
+			recent = append(recent, r)
+		}
+	}
+	c.RecentEmoji = recent
+}
+
 // Save writes the config to the given path.
 func (c *Config) Save(path string) error {
 	data, err := yaml.Marshal(c)
@@ -36,6 +56,89 @@ func (c *Config) Save(path string) error {
 	return os.WriteFile(path, data, 0600)
 }
 
+// Notification methods accepted in NotificationsConfig.Method.
+const (
+	NotifyBell   = "bell"   // terminal bell; tmux flags the window
+	NotifyOSC9   = "osc9"   // iTerm2, WezTerm, kitty, Windows Terminal
+	NotifyOSC777 = "osc777" // urxvt, foot, Ghostty
+	NotifyDBus   = "dbus"   // org.freedesktop.Notifications
+	NotifyNone   = "none"
+)
+
+// NotificationsConfig controls alerts for incoming messages.
+type NotificationsConfig struct {
+	Method       string     `yaml:"method,omitempty"`
+	MentionsOnly bool       `yaml:"mentions_only,omitempty"`
+	QuietHours   QuietHours `yaml:"quiet_hours,omitempty"`
+}
+
+// NotifyMethod returns the configured method. Notifications are opt-in,
+// so the default is none.
+func (n NotificationsConfig) NotifyMethod() string {
+	if n.Method == "" {
+		return NotifyNone
+	}
+	return n.Method
+}
+
+// QuietHours is a daily window, in local "15:04" time, during which no
+// notifications are emitted. The window may wrap past midnight.
+type QuietHours struct {
+	Start string `yaml:"start,omitempty"`
+	End   string `yaml:"end,omitempty"`
+}
+
+// Active reports whether t falls inside the quiet window. An unset or
+// invalid window is never active.
+func (q QuietHours) Active(t time.Time) bool {
+	if q.Start == "" || q.End == "" {
+		return false
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return false
+	}
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// parseClock parses "15:04" into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (n NotificationsConfig) validate() error {
+	switch n.NotifyMethod() {
+	case NotifyBell, NotifyOSC9, NotifyOSC777, NotifyDBus, NotifyNone:
+	default:
+		return fmt.Errorf("notifications.method: unknown method %q", n.Method)
+	}
+	q := n.QuietHours
+	if (q.Start == "") != (q.End == "") {
+		return fmt.Errorf("notifications.quiet_hours: both start and end are required")
+	}
+	if q.Start != "" {
+		if _, err := parseClock(q.Start); err != nil {
+			return fmt.Errorf("notifications.quiet_hours.start: %w", err)
+		}
+		if _, err := parseClock(q.End); err != nil {
+			return fmt.Errorf("notifications.quiet_hours.end: %w", err)
+		}
+	}
+	return nil
+}
+
 type TelegramConfig struct {
 	APIID   int    `yaml:"api_id"`
 	APIHash string `yaml:"api_hash"`
@@ -64,5 +167,12 @@ func Load(path string) (*Config, error) {
 		cfg.LogLevel = "info"
 	}
 
+	if err := cfg.Notifications.validate(); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if err := cfg.Keys.validate(); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
 	return &cfg, nil
 }
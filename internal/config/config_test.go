@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/danhigham/telecharm/internal/config"
 )
@@ -50,3 +51,189 @@ func TestConfigDir(t *testing.T) {
 		t.Error("Dir() returned empty string")
 	}
 }
+
+func TestLoadConfig_Notifications(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	content := []byte(`notifications:
+  method: osc777
+  mentions_only: true
+  quiet_hours:
+    start: "22:00"
+    end: "07:30"
+`)
+	if err := os.WriteFile(cfgPath, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	n := cfg.Notifications
+	if n.NotifyMethod() != config.NotifyOSC777 {
+		t.Errorf("NotifyMethod() = %q, want %q", n.NotifyMethod(), config.NotifyOSC777)
+	}
+	if !n.MentionsOnly {
+		t.Error("MentionsOnly = false, want true")
+	}
+
+	day := func(h, m int) time.Time { return time.Date(2024, 1, 1, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{day(21, 59), false},
+		{day(22, 0), true},
+		{day(3, 0), true},
+		{day(7, 29), true},
+		{day(7, 30), false},
+		{day(12, 0), false},
+	}
+	for _, tt := range tests {
+		if got := n.QuietHours.Active(tt.t); got != tt.want {
+			t.Errorf("QuietHours.Active(%s) = %v, want %v", tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfig_NotificationsDefaults(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("log_level: info\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	// Notifications are opt-in.
+	n := cfg.Notifications
+	if n.NotifyMethod() != config.NotifyNone {
+		t.Errorf("NotifyMethod() = %q, want %q", n.NotifyMethod(), config.NotifyNone)
+	}
+	if n.QuietHours.Active(time.Now()) {
+		t.Error("unset quiet hours should never be active")
+	}
+}
+
+func TestLoadConfig_InvalidNotifications(t *testing.T) {
+	for name, content := range map[string]string{
+		"method":      "notifications:\n  method: carrier-pigeon\n",
+		"quiet start": "notifications:\n  quiet_hours:\n    start: \"25:00\"\n    end: \"07:00\"\n",
+		"quiet half":  "notifications:\n  quiet_hours:\n    start: \"22:00\"\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(cfgPath, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := config.Load(cfgPath); err == nil {
+				t.Error("expected error for invalid notifications config")
+			}
+		})
+	}
+}
+
+func TestAddRecentEmoji(t *testing.T) {
+	var cfg config.Config
+	for _, e := range []string{"👍", "🎉", "🔥", "👍"} {
+		cfg.AddRecentEmoji(e)
+	}
+	want := []string{"👍", "🔥", "🎉"}
+	if len(cfg.RecentEmoji) != len(want) {
+		t.Fatalf("RecentEmoji = %v, want %v", cfg.RecentEmoji, want)
+	}
+	for i := range want {
+		if cfg.RecentEmoji[i] != want[i] {
+			t.Errorf("RecentEmoji = %v, want %v", cfg.RecentEmoji, want)
+			break
+		}
+	}
+
+	for i := 0; i < 50; i++ {
+		cfg.AddRecentEmoji(string(rune('a' + i)))
+	}
+	if len(cfg.RecentEmoji) != 24 {
+		t.Errorf("len(RecentEmoji) = %d, want 24", len(cfg.RecentEmoji))
+	}
+}
+
+func TestLoadConfig_Keys(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte(`keys:
+  preset: vim
+  toggle-sidebar: Ctrl+S
+  scroll-down: [j, "Ctrl+E", PgDn]
+  react: []
+`)
+	if err := os.WriteFile(cfgPath, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	k := cfg.Keys
+	if k.PresetName() != config.KeysVim {
+		t.Errorf("PresetName() = %q, want %q", k.PresetName(), config.KeysVim)
+	}
+	want := map[string][]string{
+		"toggle-sidebar": {"ctrl+s"},
+		"scroll-down":    {"j", "ctrl+e", "pgdown"},
+		"react":          {},
+	}
+	if len(k.Bindings) != len(want) {
+		t.Fatalf("Bindings = %v, want %v", k.Bindings, want)
+	}
+	for name, keys := range want {
+		got := k.Bindings[name]
+		if len(got) != len(keys) {
+			t.Errorf("Bindings[%q] = %v, want %v", name, got, keys)
+			continue
+		}
+		for i := range keys {
+			if got[i] != keys[i] {
+				t.Errorf("Bindings[%q] = %v, want %v", name, got, keys)
+				break
+			}
+		}
+	}
+}
+
+func TestLoadConfig_InvalidKeys(t *testing.T) {
+	for name, content := range map[string]string{
+		"preset":    "keys:\n  preset: nano\n",
+		"empty key": "keys:\n  quit: [\"\"]\n",
+		"mapping":   "keys:\n  quit:\n    key: q\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(cfgPath, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := config.Load(cfgPath); err == nil {
+				t.Error("expected error for invalid keys config")
+			}
+		})
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := map[string]string{
+		"P":         "P",
+		"Ctrl+B":    "ctrl+b",
+		"Escape":    "esc",
+		"shift+Tab": "shift+tab",
+		"alt+<":     "alt+<",
+		" ":         "space",
+	}
+	for in, want := range tests {
+		if got := config.NormalizeKey(in); got != want {
+			t.Errorf("NormalizeKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
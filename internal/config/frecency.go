@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// frecencyHalfLife is how long it takes a visit to lose half its weight.
+	frecencyHalfLife = 72 * time.Hour
+	// maxFrecencyChats caps how many chats are remembered on disk.
+	maxFrecencyChats = 200
+)
+
+// ChatVisits records how often and how recently a chat was opened.
+type ChatVisits struct {
+	Count int       `yaml:"count"`
+	Last  time.Time `yaml:"last"`
+}
+
+// Frecency ranks chats by a mix of how frequently and how recently they
+// were opened. It is kept in its own file next to the config and is safe
+// for concurrent use.
+type Frecency struct {
+	mu    sync.Mutex
+	chats map[int64]ChatVisits
+}
+
+// LoadFrecency reads visit history from path. A missing file yields an
+// empty history; on a parse error the empty history is returned with the
+// error.
+func LoadFrecency(path string) (*Frecency, error) {
+	f := &Frecency{chats: make(map[int64]ChatVisits)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, fmt.Errorf("read frecency: %w", err)
+	}
+	var chats map[int64]ChatVisits
+	if err := yaml.Unmarshal(data, &chats); err != nil {
+		return f, fmt.Errorf("parse frecency: %w", err)
+	}
+	for id, v := range chats {
+		f.chats[id] = v
+	}
+	return f, nil
+}
+
+// Visit records that a chat was opened at t.
+func (f *Frecency) Visit(chatID int64, t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v := f.chats[chatID]
+	v.Count++
+	v.Last = t
+	f.chats[chatID] = v
+}
+
+// Score rates a chat at time now: each visit counts for 1, decayed by the
+// time since the latest visit. Chats never opened score 0.
+func (f *Frecency) Score(chatID int64, now time.Time) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.chats[chatID]
+	if !ok {
+		return 0
+	}
+	age := now.Sub(v.Last)
+	if age < 0 {
+		age = 0
+	}
+	return float64(v.Count) * math.Pow(0.5, float64(age)/float64(frecencyHalfLife))
+}
+
+// Save writes the history to path, keeping only the most recently opened
+// chats.
+func (f *Frecency) Save(path string) error {
+	f.mu.Lock()
+	ids := make([]int64, 0, len(f.chats))
+	for id := range f.chats {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return f.chats[ids[i]].Last.After(f.chats[ids[j]].Last) })
+	if len(ids) > maxFrecencyChats {
+		for _, id := range ids[maxFrecencyChats:] {
+			delete(f.chats, id)
+		}
+	}
+	data, err := yaml.Marshal(f.chats)
+	f.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshal frecency: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
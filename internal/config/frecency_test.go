@@ -0,0 +1,62 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danhigham/telecharm/internal/config"
+)
+
+func TestFrecency_Score(t *testing.T) {
+	f, err := config.LoadFrecency(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFrecency: %v", err)
+	}
+	now := time.Now()
+	f.Visit(1, now.Add(-6*24*time.Hour)) // often, but long ago
+	f.Visit(1, now.Add(-6*24*time.Hour))
+	f.Visit(1, now.Add(-6*24*time.Hour))
+	f.Visit(2, now) // once, just now
+
+	if s := f.Score(3, now); s != 0 {
+		t.Errorf("Score(unvisited) = %v, want 0", s)
+	}
+	if s1, s2 := f.Score(1, now), f.Score(2, now); s2 <= s1 {
+		t.Errorf("Score(recent) = %v, want more than Score(stale) = %v", s2, s1)
+	}
+}
+
+func TestFrecency_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frecency.yaml")
+	f, _ := config.LoadFrecency(path)
+	now := time.Now()
+	f.Visit(42, now)
+	f.Visit(42, now)
+	if err := f.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := config.LoadFrecency(path)
+	if err != nil {
+		t.Fatalf("LoadFrecency: %v", err)
+	}
+	if got, want := loaded.Score(42, now), f.Score(42, now); got != want {
+		t.Errorf("Score after reload = %v, want %v", got, want)
+	}
+}
+
+func TestFrecency_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frecency.yaml")
+	if err := os.WriteFile(path, []byte("not: [valid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := config.LoadFrecency(path)
+	if err == nil {
+		t.Fatal("expected an error for a corrupt file")
+	}
+	if f == nil || f.Score(1, time.Now()) != 0 {
+		t.Error("expected an empty history alongside the error")
+	}
+}
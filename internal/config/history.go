@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxHistory caps how many sent messages are remembered on disk.
+const maxHistory = 1000
+
+// HistoryEntry is a sent message remembered for recall in the input.
+type HistoryEntry struct {
+	Chat int64  `yaml:"chat"`
+	Text string `yaml:"text"`
+}
+
+// History remembers sent messages, oldest first, for recall in the input.
+// It is kept in its own file next to the config and is safe for
+// concurrent use.
+type History struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+}
+
+// LoadHistory reads sent messages from path. A missing file yields an
+// empty history; on a parse error the empty history is returned with the
+// error.
+func LoadHistory(path string) (*History, error) {
+	h := &History{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("read history: %w", err)
+	}
+	var entries []HistoryEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return h, fmt.Errorf("parse history: %w", err)
+	}
+	h.entries = entries
+	return h, nil
+}
+
+// Add records text sent to a chat as the newest entry. An earlier copy of
+// the same text in the same chat is dropped, so repeats don't crowd out
+// older messages.
+func (h *History) Add(chatID int64, text string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	kept := h.entries[:0]
+	for _, e := range h.entries {
+		if e.Chat != chatID || e.Text != text {
+			kept = append(kept, e)
+		}
+	}
+	h.entries = append(kept, HistoryEntry{Chat: chatID, Text: text})
+	if n := len(h.entries) - maxHistory; n > 0 {
+		h.entries = append([]HistoryEntry(nil), h.entries[n:]...)
+	}
+}
+
+// Entries returns the texts sent to a chat, oldest first. A chatID of 0
+// returns the texts sent anywhere.
+func (h *History) Entries(chatID int64) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var texts []string
+	for _, e := range h.entries {
+		if chatID == 0 || e.Chat == chatID {
+			texts = append(texts, e.Text)
+		}
+	}
+	return texts
+}
+
+// Save writes the history to path.
+func (h *History) Save(path string) error {
+	h.mu.Lock()
+	data, err := yaml.Marshal(h.entries)
+	h.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshal history: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,63 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/danhigham/telecharm/internal/config"
+)
+
+func TestHistory_Entries(t *testing.T) {
+	h, err := config.LoadHistory(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	h.Add(1, "/start")
+	h.Add(2, "hello")
+	h.Add(1, "/status")
+	h.Add(1, "/start") // a repeat moves to the end
+
+	if got, want := h.Entries(1), []string{"/status", "/start"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries(1) = %q, want %q", got, want)
+	}
+	if got, want := h.Entries(0), []string{"hello", "/status", "/start"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries(0) = %q, want %q", got, want)
+	}
+	if got := h.Entries(3); len(got) != 0 {
+		t.Errorf("Entries(3) = %q, want none", got)
+	}
+}
+
+func TestHistory_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.yaml")
+	h, _ := config.LoadHistory(path)
+	h.Add(42, "line one\nline two")
+	h.Add(7, "ping")
+	if err := h.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := config.LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if got, want := loaded.Entries(0), h.Entries(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries after reload = %q, want %q", got, want)
+	}
+}
+
+func TestHistory_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.yaml")
+	if err := os.WriteFile(path, []byte("not: [valid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	h, err := config.LoadHistory(path)
+	if err == nil {
+		t.Fatal("expected an error for a corrupt file")
+	}
+	if h == nil || len(h.Entries(0)) != 0 {
+		t.Error("expected an empty history alongside the error")
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Key binding presets accepted in KeysConfig.Preset.
+const (
+	KeysDefault = "default"
+	KeysVim     = "vim"
+	KeysEmacs   = "emacs"
+)
+
+// KeysConfig rebinds actions. Bindings maps an action name, as listed in
+// the command palette, to the keys that trigger it; they replace the
+// preset's keys for that action, and an empty list unbinds it.
+//
+//	keys:
+//	  preset: vim
+//	  toggle-sidebar: ctrl+s
+//	  scroll-down: [j, ctrl+e]
+type KeysConfig struct {
+	Preset   string             `yaml:"preset,omitempty"`
+	Bindings map[string]KeyList `yaml:",inline"`
+}
+
+// KeyPresets rebind actions for people used to other editors. Actions a
+// preset doesn't list keep their default keys.
+var KeyPresets = map[string]map[string]KeyList{
+	KeysDefault: {},
+	KeysVim: {
+		"scroll-down":   {"j", "ctrl+e"},
+		"scroll-up":     {"k", "ctrl+y"},
+		"select-next":   {"down", "J"},
+		"select-prev":   {"up", "K"},
+		"scroll-top":    {"home"},
+		"scroll-bottom": {"end", "G"},
+	},
+	KeysEmacs: {
+		"select-next":     {"down", "ctrl+n"},
+		"select-prev":     {"up", "ctrl+p"},
+		"page-down":       {"pgdown", "ctrl+v"},
+		"page-up":         {"pgup", "alt+v"},
+		"scroll-top":      {"home", "alt+<"},
+		"scroll-bottom":   {"end", "alt+>"},
+		"search-chat":     {"/", "ctrl+s"},
+		"focus-chat-list": {"esc", "ctrl+g"},
+	},
+}
+
+// PresetName returns the configured preset, defaulting to "default".
+func (k KeysConfig) PresetName() string {
+	if k.Preset == "" {
+		return KeysDefault
+	}
+	return k.Preset
+}
+
+func (k KeysConfig) validate() error {
+	if _, ok := KeyPresets[k.PresetName()]; !ok {
+		return fmt.Errorf("keys.preset: unknown preset %q", k.Preset)
+	}
+	for action, keys := range k.Bindings {
+		for _, key := range keys {
+			if key == "" {
+				return fmt.Errorf("keys.%s: empty key", action)
+			}
+		}
+	}
+	return nil
+}
+
+// KeyList is one or more keys, written in YAML as a single string or a
+// list. Keys are normalized to the form the terminal reports them in,
+// e.g. "Ctrl+B" becomes "ctrl+b".
+type KeyList []string
+
+// UnmarshalYAML accepts a scalar or a sequence of scalars.
+func (l *KeyList) UnmarshalYAML(node *yaml.Node) error {
+	var keys []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Value != "" {
+			keys = []string{node.Value}
+		}
+	case yaml.SequenceNode:
+		if err := node.Decode(&keys); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("line %d: want a key or a list of keys", node.Line)
+	}
+	for i, k := range keys {
+		keys[i] = NormalizeKey(k)
+	}
+	*l = keys
+	return nil
+}
+
+// keyAliases maps alternative spellings to the names the terminal uses.
+var keyAliases = map[string]string{
+	"escape": "esc",
+	"return": "enter",
+	"pgdn":   "pgdown",
+	"pageup": "pgup",
+	"pagedn": "pgdown",
+	" ":      "space",
+}
+
+// NormalizeKey converts a key as a person might write it into the form
+// of tea.KeyMsg.String(): modifiers and named keys are lowercased, while
+// a lone character keeps its case, since "P" is shift+p.
+func NormalizeKey(key string) string {
+	if key == " " {
+		return "space"
+	}
+	key = strings.TrimSpace(key)
+	if utf8.RuneCountInString(key) == 1 {
+		return key
+	}
+	parts := strings.Split(key, "+")
+	for i, p := range parts {
+		p = strings.ToLower(p)
+		if alias, ok := keyAliases[p]; ok {
+			p = alias
+		}
+		parts[i] = p
+	}
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in theme names accepted in Config.Theme.
+const (
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeNoColor      = "no-color"
+)
+
+// Theme holds every color the UI uses. A color is a "#RRGGBB" or "#RGB"
+// hex value, an ANSI color number from 0 to 255, or empty for the
+// terminal's default.
+type Theme struct {
+	// Glamour is the glamour style for rendered markdown: a built-in
+	// style name such as "dark" or "light", or a path to a JSON style.
+	Glamour string `yaml:"glamour"`
+
+	Dim      string `yaml:"dim"`      // timestamps, separators, unfocused borders
+	Subtle   string `yaml:"subtle"`   // secondary text such as reactions
+	Accent   string `yaml:"accent"`   // the selected item in lists and menus
+	Error    string `yaml:"error"`    // error messages
+	Mention  string `yaml:"mention"`  // mentions of us and the "@" badge
+	Outgoing string `yaml:"outgoing"` // our name in flat mode
+	Incoming string `yaml:"incoming"` // other senders' names in flat mode
+	Forward  string `yaml:"forward"`  // "Forwarded from" headers
+
+	SentBubble     string `yaml:"sent_bubble"`
+	ReceivedBubble string `yaml:"received_bubble"`
+	SelectedBubble string `yaml:"selected_bubble"`
+
+	// Border is the gradient of focused pane and overlay borders; a
+	// single color gives a solid border.
+	Border []string `yaml:"border,flow"`
+
+	StatusBar     string `yaml:"status_bar"`      // status bar background
+	StatusText    string `yaml:"status_text"`     // text on the status bar
+	StatusPill    string `yaml:"status_pill"`     // connection pill while connected
+	StatusPillOff string `yaml:"status_pill_off"` // connection pill otherwise
+	StatusClock   string `yaml:"status_clock"`
+	StatusUser    string `yaml:"status_user"`
+	PillText      string `yaml:"pill_text"` // text on the pills
+
+	TableBorder  string `yaml:"table_border"` // also table headers
+	TableOddRow  string `yaml:"table_odd_row"`
+	TableEvenRow string `yaml:"table_even_row"`
+}
+
+// builtinThemes are the themes available without a theme file.
+var builtinThemes = map[string]Theme{
+	ThemeDark: {
+		Glamour:        "dark",
+		Dim:            "240",
+		Subtle:         "250",
+		Accent:         "#8C6161",
+		Error:          "#FF6B6B",
+		Mention:        "#E5C07B",
+		Outgoing:       "2",
+		Incoming:       "4",
+		Forward:        "#61AFEF",
+		SentBubble:     "#7B5EA7",
+		ReceivedBubble: "#8C6161",
+		SelectedBubble: "#5FD7FF",
+		Border:         []string{"#FF6B9D", "#9B59B6", "#3498DB", "#2ECC71", "#FF6B9D"},
+		StatusBar:      "#353533",
+		StatusText:     "#FFFFFF",
+		StatusPill:     "#FF5FAF",
+		StatusPillOff:  "#6C5098",
+		StatusClock:    "#6124DF",
+		StatusUser:     "#7B5EA7",
+		PillText:       "#FFFFFF",
+		TableBorder:    "99",
+		TableOddRow:    "245",
+		TableEvenRow:   "241",
+	},
+	ThemeLight: {
+		Glamour:        "light",
+		Dim:            "245",
+		Subtle:         "240",
+		Accent:         "#A0405A",
+		Error:          "#C62828",
+		Mention:        "#B26A00",
+		Outgoing:       "#2E7D32",
+		Incoming:       "#1565C0",
+		Forward:        "#1E6FB8",
+		SentBubble:     "#7B5EA7",
+		ReceivedBubble: "#A0405A",
+		SelectedBubble: "#0087AF",
+		Border:         []string{"#D6336C", "#7048E8", "#1C7ED6", "#2F9E44", "#D6336C"},
+		StatusBar:      "#E4E4E4",
+		StatusText:     "#1C1C1C",
+		StatusPill:     "#D6336C",
+		StatusPillOff:  "#9E8CC2",
+		StatusClock:    "#7048E8",
+		StatusUser:     "#7B5EA7",
+		PillText:       "#FFFFFF",
+		TableBorder:    "#7048E8",
+		TableOddRow:    "238",
+		TableEvenRow:   "242",
+	},
+	ThemeHighContrast: {
+		Glamour:        "dark",
+		Dim:            "250",
+		Subtle:         "255",
+		Accent:         "11",
+		Error:          "9",
+		Mention:        "13",
+		Outgoing:       "10",
+		Incoming:       "14",
+		Forward:        "14",
+		SentBubble:     "10",
+		ReceivedBubble: "14",
+		SelectedBubble: "11",
+		Border:         []string{"15"},
+		StatusBar:      "0",
+		StatusText:     "15",
+		StatusPill:     "10",
+		StatusPillOff:  "9",
+		StatusClock:    "12",
+		StatusUser:     "13",
+		PillText:       "0",
+		TableBorder:    "15",
+		TableOddRow:    "15",
+		TableEvenRow:   "250",
+	},
+	ThemeNoColor: {
+		Glamour: "notty",
+	},
+}
+
+// BuiltinTheme returns a copy of a built-in theme.
+func BuiltinTheme(name string) (Theme, bool) {
+	t, ok := builtinThemes[name]
+	t.Border = append([]string(nil), t.Border...)
+	return t, ok
+}
+
+// ThemeName returns the configured theme, defaulting to dark.
+func (c *Config) ThemeName() string {
+	if c.Theme == "" {
+		return ThemeDark
+	}
+	return c.Theme
+}
+
+// themeFile is the YAML form of a user theme: a built-in base with some
+// colors overridden.
+type themeFile struct {
+	Base  string `yaml:"base"`
+	Theme `yaml:",inline"`
+}
+
+// LoadTheme returns the named theme: a built-in one, or dir/<name>.yaml.
+// A theme file starts from its base theme (dark unless it sets base:)
+// and overrides the colors it lists.
+func LoadTheme(dir, name string) (Theme, error) {
+	if t, ok := BuiltinTheme(name); ok {
+		return t, nil
+	}
+
+	path := filepath.Join(dir, name+".yaml")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Theme{}, fmt.Errorf("unknown theme %q (no built-in theme or %s)", name, path)
+	}
+	if err != nil {
+		return Theme{}, fmt.Errorf("read theme: %w", err)
+	}
+
+	var base struct {
+		Base string `yaml:"base"`
+	}
+	if err := yaml.Unmarshal(data, &base); err != nil {
+		return Theme{}, fmt.Errorf("parse theme %s: %w", path, err)
+	}
+	if base.Base == "" {
+		base.Base = ThemeDark
+	}
+	start, ok := BuiltinTheme(base.Base)
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %s: unknown base theme %q", path, base.Base)
+	}
+
+	f := themeFile{Theme: start}
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return Theme{}, fmt.Errorf("parse theme %s: %w", path, err)
+	}
+	if err := f.Theme.validate(); err != nil {
+		return Theme{}, fmt.Errorf("theme %s: %w", path, err)
+	}
+	return f.Theme, nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor reports whether s is a color a theme accepts.
+func validColor(s string) bool {
+	if s == "" || hexColor.MatchString(s) {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// validate checks every color in the theme.
+func (t Theme) validate() error {
+	v := reflect.ValueOf(t)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Name == "Glamour" {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		switch f := v.Field(i).Interface().(type) {
+		case string:
+			if !validColor(f) {
+				return fmt.Errorf("%s: invalid color %q", name, f)
+			}
+		case []string:
+			for _, c := range f {
+				if !validColor(c) {
+					return fmt.Errorf("%s: invalid color %q", name, c)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danhigham/telecharm/internal/config"
+)
+
+func TestLoadTheme_Builtin(t *testing.T) {
+	for _, name := range []string{config.ThemeDark, config.ThemeLight, config.ThemeHighContrast, config.ThemeNoColor} {
+		if _, err := config.LoadTheme(t.TempDir(), name); err != nil {
+			t.Errorf("LoadTheme(%q) error: %v", name, err)
+		}
+	}
+}
+
+func TestLoadTheme_File(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte(`base: light
+accent: "#FF8800"
+border: ["#111", "#222222"]
+`)
+	if err := os.WriteFile(filepath.Join(dir, "sunset.yaml"), content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := config.LoadTheme(dir, "sunset")
+	if err != nil {
+		t.Fatalf("LoadTheme() error: %v", err)
+	}
+	light, _ := config.BuiltinTheme(config.ThemeLight)
+	if got.Accent != "#FF8800" {
+		t.Errorf("Accent = %q, want %q", got.Accent, "#FF8800")
+	}
+	if len(got.Border) != 2 || got.Border[1] != "#222222" {
+		t.Errorf("Border = %v, want [#111 #222222]", got.Border)
+	}
+	if got.Glamour != light.Glamour || got.Dim != light.Dim {
+		t.Errorf("unset colors should come from the base theme, got glamour %q dim %q", got.Glamour, got.Dim)
+	}
+}
+
+func TestLoadTheme_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"color": "accent: orange\n",
+		"ansi":  "dim: \"256\"\n",
+		"base":  "base: solarized\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := config.LoadTheme(dir, "bad"); err == nil {
+				t.Error("expected error for invalid theme")
+			}
+		})
+	}
+
+	if _, err := config.LoadTheme(t.TempDir(), "missing"); err == nil {
+		t.Error("expected error for a missing theme")
+	}
+}
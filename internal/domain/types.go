@@ -3,12 +3,21 @@ package domain
 import "time"
 
 type ChatInfo struct {
-	ID          int64
-	Title       string
-	UnreadCount int
-	LastMessage string
-	LastTime    time.Time
-	Peer        interface{} // holds tg.InputPeerClass for sending
+	ID             int64
+	Title          string
+	Username       string // public username without the "@"; may be empty
+	UnreadCount    int
+	UnreadMentions int
+	LastMessage    string
+	LastTime       time.Time
+	MuteUntil      time.Time   // zero when notifications are enabled
+	Draft          string      // unsent text saved for the chat; may be empty
+	Peer           interface{} // holds tg.InputPeerClass for sending
+}
+
+// Muted reports whether notifications for the chat are currently silenced.
+func (c ChatInfo) Muted() bool {
+	return c.MuteUntil.After(time.Now())
 }
 
 type Message struct {
@@ -20,6 +29,159 @@ type Message struct {
 	HasMarkdown bool // true if Text contains markdown from Telegram entities
 	Timestamp   time.Time
 	Out         bool // true if sent by us
+	Mentioned   bool // true if the message mentions us or replies to us
+	Reactions   []Reaction
+	// ForwardedFrom names the original sender of a forwarded message, or
+	// is empty for messages that weren't forwarded.
+	ForwardedFrom string
+	// Buttons is the inline keyboard a bot attached under the message, in
+	// rows.
+	Buttons [][]Button
+	// ReplyKeyboard is set on messages that change the chat's reply
+	// keyboard, offered as quick replies.
+	ReplyKeyboard *ReplyKeyboard
+	// Poll is set on messages carrying a poll; Text holds its question.
+	Poll *Poll
+	// Kind tells service messages, such as a member joining, from those
+	// people send. Text describes a service message's action.
+	Kind MessageKind
+	// Event sets apart the service messages that are handled specially,
+	// such as missed calls, from the rest.
+	Event ServiceEvent
+}
+
+// MessageKind says whether a message was sent by someone or records an
+// event in the chat.
+type MessageKind int
+
+const (
+	// MessageRegular is a message someone sent.
+	MessageRegular MessageKind = iota
+	// MessageService records an event, like a member joining or leaving,
+	// a title change, a pin or a call.
+	MessageService
+)
+
+// ServiceEvent classifies a service message's action.
+type ServiceEvent int
+
+const (
+	// EventOther is any other action: membership, title and photo
+	// changes, screenshots, auto-delete settings and so on.
+	EventOther ServiceEvent = iota
+	// EventMissedCall is a call to us that we didn't answer.
+	EventMissedCall
+	// EventVideoChatStarted is a group video chat starting.
+	EventVideoChatStarted
+	// EventPin is a message being pinned.
+	EventPin
+)
+
+// Poll is a poll or quiz and its results so far.
+type Poll struct {
+	ID       int64
+	Question string
+	Options  []PollOption
+	Closed   bool
+	Multiple bool // several options may be chosen
+	Quiz     bool // one option is correct
+	Public   bool // voters are visible to everyone
+	// HasResults is set once the vote counts are known to us, which is
+	// after we vote or the poll closes.
+	HasResults bool
+	Voters     int
+}
+
+// Voted reports whether we've voted in the poll.
+func (p Poll) Voted() bool {
+	for _, o := range p.Options {
+		if o.Chosen {
+			return true
+		}
+	}
+	return false
+}
+
+// PollOption is one answer to a poll.
+type PollOption struct {
+	Text    string
+	Option  []byte // identifies the answer when voting
+	Voters  int
+	Chosen  bool // we chose it
+	Correct bool // the answer to a quiz, once revealed
+}
+
+// ButtonKind says what pressing a keyboard button does.
+type ButtonKind int
+
+const (
+	// ButtonText sends the button's text as a message.
+	ButtonText ButtonKind = iota
+	// ButtonCallback sends the button's data to the bot, which answers
+	// with a notification and often edits the message.
+	ButtonCallback
+	// ButtonURL opens the button's URL.
+	ButtonURL
+	// ButtonCopy copies the button's data to the clipboard.
+	ButtonCopy
+	// ButtonUnsupported covers buttons telecharm can't press, such as
+	// payments, games, web apps and location requests.
+	ButtonUnsupported
+)
+
+// Button is a button on a keyboard sent by a bot.
+type Button struct {
+	Text string
+	Kind ButtonKind
+	Data []byte // callback data, or the text to copy
+	URL  string
+}
+
+// ReplyKeyboard is a keyboard a bot offers in place of typing. Its buttons
+// send their text.
+type ReplyKeyboard struct {
+	Rows      [][]Button
+	SingleUse bool // hidden once we reply
+	Hide      bool // removes the keyboard an earlier message set
+}
+
+// Reaction is the tally for one reaction on a message.
+type Reaction struct {
+	Emoji  string // emoticon; custom emoji are shown as a placeholder
+	Count  int
+	Chosen bool // true if we reacted with it
+}
+
+// Participant is a member of a chat, as offered for @mention completion.
+type Participant struct {
+	ID       int64
+	Name     string
+	Username string // without the leading "@"; empty if the user has none
+	Bot      bool
+}
+
+// BotCommand is a command a bot in a chat accepts, offered for slash
+// completion.
+type BotCommand struct {
+	Command     string // without the leading "/"; may end in "@botname"
+	Description string
+}
+
+// InlineResult is one answer to an inline bot query.
+type InlineResult struct {
+	ID          string
+	Type        string // e.g. "article", "gif" or "photo"
+	Title       string
+	Description string
+	Text        string // the message text it sends, if any
+}
+
+// Mention links a span of outgoing text to a user, for users that can't be
+// mentioned by @username.
+type Mention struct {
+	Offset int // byte offset into the message text
+	Length int // length in bytes
+	UserID int64
 }
 
 type AuthState int
@@ -0,0 +1,98 @@
+// Package emoji maps :shortcode: names to emoji using an embedded table.
+package emoji
+
+import (
+	_ "embed"
+	"sort"
+	"strings"
+)
+
+//go:embed emoji.txt
+var table string
+
+// Emoji is a single shortcode entry. An emoji may have several shortcodes.
+type Emoji struct {
+	Code string // shortcode without colons, e.g. "thumbsup"
+	Char string
+}
+
+var (
+	all    []Emoji
+	byCode map[string]string
+)
+
+func init() {
+	byCode = make(map[string]string)
+	for _, line := range strings.Split(table, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		code, char, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if _, dup := byCode[code]; dup {
+			continue
+		}
+		byCode[code] = char
+		all = append(all, Emoji{Code: code, Char: char})
+	}
+}
+
+// Lookup returns the emoji for a shortcode (without colons).
+func Lookup(code string) (string, bool) {
+	char, ok := byCode[strings.ToLower(code)]
+	return char, ok
+}
+
+// Search returns up to limit emoji whose shortcode matches query. Prefix
+// matches come before substring matches, shorter codes first within each.
+// Each emoji appears once, under its best-ranked shortcode.
+func Search(query string, limit int) []Emoji {
+	q := strings.ToLower(query)
+
+	type scored struct {
+		e     Emoji
+		score int
+	}
+	var matches []scored
+	for _, e := range all {
+		switch {
+		case strings.HasPrefix(e.Code, q):
+			matches = append(matches, scored{e, 0})
+		case strings.Contains(e.Code, q):
+			matches = append(matches, scored{e, 1})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return len(matches[i].e.Code) < len(matches[j].e.Code)
+	})
+
+	seen := make(map[string]bool)
+	var out []Emoji
+	for _, m := range matches {
+		if seen[m.e.Char] {
+			continue
+		}
+		seen[m.e.Char] = true
+		out = append(out, m.e)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+// CodeFor returns the first shortcode listed for an emoji, or "" if the
+// emoji isn't in the table.
+func CodeFor(char string) string {
+	for _, e := range all {
+		if e.Char == char {
+			return e.Code
+		}
+	}
+	return ""
+}
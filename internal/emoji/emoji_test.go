@@ -0,0 +1,59 @@
+package emoji_test
+
+import (
+	"testing"
+
+	"github.com/danhigham/telecharm/internal/emoji"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+		ok   bool
+	}{
+		{"thumbsup", "👍", true},
+		{"+1", "👍", true},
+		{"Tada", "🎉", true},
+		{"grinning_face", "😀", true},
+		{"not_an_emoji", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := emoji.Lookup(tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	results := emoji.Search("thumbs", 5)
+	if len(results) == 0 || results[0].Char != "👍" {
+		t.Fatalf("Search(thumbs) = %v, want 👍 first", results)
+	}
+
+	// Prefix matches rank above substring matches.
+	results = emoji.Search("heart", 0)
+	if results[0].Code != "heart" {
+		t.Errorf("Search(heart)[0] = %q, want %q", results[0].Code, "heart")
+	}
+
+	// Each emoji appears once even with several matching codes.
+	seen := make(map[string]bool)
+	for _, e := range emoji.Search("", 0) {
+		if seen[e.Char] {
+			t.Fatalf("duplicate emoji %s (%s) in results", e.Char, e.Code)
+		}
+		seen[e.Char] = true
+	}
+
+	if got := emoji.Search("smile", 3); len(got) != 3 {
+		t.Errorf("Search(smile, 3) returned %d results, want 3", len(got))
+	}
+}
+
+func TestCodeFor(t *testing.T) {
+	if got := emoji.CodeFor("🔥"); got != "fire" {
+		t.Errorf("CodeFor(🔥) = %q, want %q", got, "fire")
+	}
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DBus delivers desktop notifications via org.freedesktop.Notifications.
+// It shells out to gdbus (part of GLib, present on most Linux desktops)
+// rather than speaking the D-Bus wire protocol itself.
+type DBus struct {
+	appName string
+	timeout int // expiry in milliseconds
+	start   func(name string, args ...string) error
+}
+
+// NewDBus creates a D-Bus backend using the session bus.
+func NewDBus() *DBus {
+	return &DBus{
+		appName: "telecharm",
+		timeout: 5000,
+		start:   startDetached,
+	}
+}
+
+// Notify calls org.freedesktop.Notifications.Notify. The call is not
+// awaited so a slow notification daemon never stalls update handling.
+func (d *DBus) Notify(n Notification) error {
+	if err := d.start("gdbus", d.Args(n)...); err != nil {
+		return fmt.Errorf("dbus notify: %w", err)
+	}
+	return nil
+}
+
+// Args returns the gdbus arguments for n. Notify's signature is
+// (app_name, replaces_id, app_icon, summary, body, actions, hints, expire_timeout).
+func (d *DBus) Args(n Notification) []string {
+	return []string{
+		"call", "--session",
+		"--dest", "org.freedesktop.Notifications",
+		"--object-path", "/org/freedesktop/Notifications",
+		"--method", "org.freedesktop.Notifications.Notify",
+		gvariantString(d.appName),
+		"0",
+		gvariantString(""),
+		gvariantString(n.Title),
+		gvariantString(n.Body),
+		"[]",
+		"{}",
+		fmt.Sprint(d.timeout),
+	}
+}
+
+// gvariantString quotes s as a GVariant text-format string so gdbus never
+// misparses message text (e.g. "42" or "[1]") as another type.
+func gvariantString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "'", `\'`)
+	return "'" + s + "'"
+}
+
+// startDetached starts a command and reaps it in the background.
+func startDetached(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package notify alerts the user about incoming messages while telecharm is
+// not in view: via the terminal (bell, OSC 9, OSC 777) or a desktop
+// notification over D-Bus.
+package notify
+
+import (
+	"strings"
+	"time"
+
+	"github.com/danhigham/telecharm/internal/config"
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// maxBodyLen caps the message preview in a notification, in runes.
+const maxBodyLen = 200
+
+// Notification is a single alert ready to be delivered by a Backend.
+type Notification struct {
+	ChatID int64
+	Title  string
+	Body   string
+}
+
+// Backend delivers notifications to the user.
+type Backend interface {
+	Notify(n Notification) error
+}
+
+// Notifier applies the configured rules to incoming messages and forwards
+// the ones that pass to a Backend.
+type Notifier struct {
+	backend Backend
+	cfg     config.NotificationsConfig
+	now     func() time.Time
+	onError func(error)
+}
+
+// New creates a Notifier. A nil backend disables notifications.
+func New(cfg config.NotificationsConfig, backend Backend) *Notifier {
+	return &Notifier{
+		backend: backend,
+		cfg:     cfg,
+		now:     time.Now,
+	}
+}
+
+// SetOnError registers a callback for backend failures, e.g. for logging.
+func (n *Notifier) SetOnError(fn func(error)) {
+	n.onError = fn
+}
+
+// SetClock overrides the time source used for quiet hours.
+func (n *Notifier) SetClock(now func() time.Time) {
+	n.now = now
+}
+
+// OnMessage is suitable for state.Store.SetNotifyFunc. The store has already
+// filtered out our own messages, the active chat and muted chats.
+func (n *Notifier) OnMessage(msg domain.Message, chat domain.ChatInfo) {
+	if n.backend == nil {
+		return
+	}
+	if n.cfg.MentionsOnly && !msg.Mentioned {
+		return
+	}
+	if msg.Kind == domain.MessageService && !notifiesService(msg) {
+		return
+	}
+	if n.cfg.QuietHours.Active(n.now()) {
+		return
+	}
+
+	if err := n.backend.Notify(Build(msg, chat)); err != nil && n.onError != nil {
+		n.onError(err)
+	}
+}
+
+// notifiesService reports whether a service message is worth an alert.
+// Missed calls, video chats starting and pins are, as in Telegram's own
+// apps, and so is any event that mentions us, such as being added to a
+// group. Joins, leaves, renames and the like are not.
+func notifiesService(msg domain.Message) bool {
+	switch msg.Event {
+	case domain.EventMissedCall, domain.EventVideoChatStarted, domain.EventPin:
+		return true
+	}
+	return msg.Mentioned
+}
+
+// Build formats a message into a notification. In group chats the sender's
+// name prefixes the body so the alert reads "Chat: Sender: text"; service
+// messages already name who acted.
+func Build(msg domain.Message, chat domain.ChatInfo) Notification {
+	body := strings.Join(strings.Fields(msg.Text), " ")
+	if r := []rune(body); len(r) > maxBodyLen {
+		body = string(r[:maxBodyLen-1]) + "…"
+	}
+	if msg.SenderName != "" && msg.SenderName != chat.Title && msg.Kind != domain.MessageService {
+		body = msg.SenderName + ": " + body
+	}
+	return Notification{
+		ChatID: chat.ID,
+		Title:  chat.Title,
+		Body:   body,
+	}
+}
+
+// NewBackend returns the backend for a config.Notify* method. Terminal
+// backends emit their escape sequences through write, which must hand them
+// to the terminal without interleaving with the UI's own output.
+func NewBackend(method string, write func(seq string)) Backend {
+	switch method {
+	case config.NotifyBell:
+		return NewTerminal(Bell, write)
+	case config.NotifyOSC9:
+		return NewTerminal(OSC9, write)
+	case config.NotifyOSC777:
+		return NewTerminal(OSC777, write)
+	case config.NotifyDBus:
+		return NewDBus()
+	default:
+		return nil
+	}
+}
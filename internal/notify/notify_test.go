@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danhigham/telecharm/internal/config"
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// stubBackend records notifications instead of delivering them.
+type stubBackend struct {
+	got []Notification
+	err error
+}
+
+func (s *stubBackend) Notify(n Notification) error {
+	s.got = append(s.got, n)
+	return s.err
+}
+
+func TestNotifier_Rules(t *testing.T) {
+	chat := domain.ChatInfo{ID: 7, Title: "Ops"}
+	plain := domain.Message{ChatID: 7, SenderName: "Alice", Text: "deploy done"}
+	mention := domain.Message{ChatID: 7, SenderName: "Bob", Text: "@me look", Mentioned: true}
+	joined := domain.Message{ChatID: 7, Text: "Carol joined", Kind: domain.MessageService}
+	added := domain.Message{ChatID: 7, Text: "Carol added you", Kind: domain.MessageService, Mentioned: true}
+	missed := domain.Message{ChatID: 7, Text: "Missed call", Kind: domain.MessageService, Event: domain.EventMissedCall}
+	noon := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	night := time.Date(2024, 1, 1, 23, 0, 0, 0, time.Local)
+	quiet := config.QuietHours{Start: "22:00", End: "07:00"}
+
+	tests := []struct {
+		name string
+		cfg  config.NotificationsConfig
+		now  time.Time
+		msg  domain.Message
+		want int
+	}{
+		{"default", config.NotificationsConfig{}, noon, plain, 1},
+		{"mentions only skips plain", config.NotificationsConfig{MentionsOnly: true}, noon, plain, 0},
+		{"mentions only keeps mention", config.NotificationsConfig{MentionsOnly: true}, noon, mention, 1},
+		{"outside quiet hours", config.NotificationsConfig{QuietHours: quiet}, noon, plain, 1},
+		{"inside quiet hours", config.NotificationsConfig{QuietHours: quiet}, night, mention, 0},
+		{"service event skipped", config.NotificationsConfig{}, noon, joined, 0},
+		{"service mention kept", config.NotificationsConfig{}, noon, added, 1},
+		{"missed call kept", config.NotificationsConfig{}, noon, missed, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &stubBackend{}
+			n := New(tt.cfg, b)
+			n.SetClock(func() time.Time { return tt.now })
+			n.OnMessage(tt.msg, chat)
+			if len(b.got) != tt.want {
+				t.Errorf("notifications = %d, want %d", len(b.got), tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifier_OnError(t *testing.T) {
+	b := &stubBackend{err: errors.New("no daemon")}
+	n := New(config.NotificationsConfig{}, b)
+	var got error
+	n.SetOnError(func(err error) { got = err })
+	n.OnMessage(domain.Message{Text: "hi"}, domain.ChatInfo{Title: "Alice"})
+	if got == nil {
+		t.Error("expected backend error to reach OnError")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	group := domain.ChatInfo{ID: 1, Title: "Ops"}
+	n := Build(domain.Message{SenderName: "Alice", Text: "line one\nline two"}, group)
+	if n.Title != "Ops" || n.Body != "Alice: line one line two" {
+		t.Errorf("Build() = %+v", n)
+	}
+
+	dm := domain.ChatInfo{ID: 2, Title: "Alice"}
+	n = Build(domain.Message{SenderName: "Alice", Text: "hi"}, dm)
+	if n.Body != "hi" {
+		t.Errorf("Body = %q, want %q", n.Body, "hi")
+	}
+
+	n = Build(domain.Message{SenderName: "Alice", Text: "Alice joined", Kind: domain.MessageService}, group)
+	if n.Body != "Alice joined" {
+		t.Errorf("service Body = %q, want %q", n.Body, "Alice joined")
+	}
+
+	n = Build(domain.Message{Text: strings.Repeat("x", 500)}, dm)
+	if got := len([]rune(n.Body)); got != maxBodyLen {
+		t.Errorf("body length = %d, want %d", got, maxBodyLen)
+	}
+}
+
+func TestTerminal_Sequence(t *testing.T) {
+	n := Notification{Title: "Ops;team", Body: "hi\x07there"}
+
+	tests := []struct {
+		kind TerminalKind
+		tmux bool
+		want string
+	}{
+		{Bell, false, "\a"},
+		{Bell, true, "\a"},
+		{OSC9, false, "\x1b]9;Ops;team: hi there\a"},
+		{OSC777, false, "\x1b]777;notify;Ops,team;hi there\a"},
+		{OSC9, true, "\x1bPtmux;\x1b\x1b]9;Ops;team: hi there\a\x1b\\"},
+	}
+	for _, tt := range tests {
+		term := &Terminal{kind: tt.kind, tmux: tt.tmux}
+		if got := term.Sequence(n); got != tt.want {
+			t.Errorf("Sequence(kind=%d, tmux=%v) = %q, want %q", tt.kind, tt.tmux, got, tt.want)
+		}
+	}
+}
+
+func TestDBus_Notify(t *testing.T) {
+	d := NewDBus()
+	var name string
+	var args []string
+	d.start = func(n string, a ...string) error {
+		name, args = n, a
+		return nil
+	}
+
+	if err := d.Notify(Notification{Title: "Ops", Body: "it's 42"}); err != nil {
+		t.Fatalf("Notify() error: %v", err)
+	}
+	if name != "gdbus" {
+		t.Errorf("command = %q, want gdbus", name)
+	}
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"--method org.freedesktop.Notifications.Notify",
+		"'telecharm' 0 '' 'Ops' 'it\\'s 42' [] {} 5000",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("args %q missing %q", joined, want)
+		}
+	}
+
+	d.start = func(string, ...string) error { return errors.New("not found") }
+	if err := d.Notify(Notification{}); err == nil {
+		t.Error("expected error when gdbus cannot start")
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	write := func(string) {}
+	if NewBackend(config.NotifyNone, write) != nil {
+		t.Error("none should have no backend")
+	}
+	if _, ok := NewBackend(config.NotifyDBus, write).(*DBus); !ok {
+		t.Error("dbus should return a DBus backend")
+	}
+	if term, ok := NewBackend(config.NotifyOSC777, write).(*Terminal); !ok || term.kind != OSC777 {
+		t.Error("osc777 should return an OSC777 terminal backend")
+	}
+}
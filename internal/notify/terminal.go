@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"os"
+	"strings"
+)
+
+// TerminalKind selects the escape sequence a Terminal backend emits.
+type TerminalKind int
+
+const (
+	Bell   TerminalKind = iota // BEL; most terminals flash or beep, tmux flags the window
+	OSC9                       // ESC ] 9 ; body BEL
+	OSC777                     // ESC ] 777 ; notify ; title ; body BEL
+)
+
+// Terminal delivers notifications as terminal escape sequences.
+type Terminal struct {
+	kind  TerminalKind
+	write func(seq string)
+	tmux  bool
+}
+
+// NewTerminal creates a terminal backend. Inside tmux, OSC sequences are
+// wrapped in a DCS passthrough so they reach the outer terminal (this needs
+// "set -g allow-passthrough on").
+func NewTerminal(kind TerminalKind, write func(seq string)) *Terminal {
+	return &Terminal{
+		kind:  kind,
+		write: write,
+		tmux:  os.Getenv("TMUX") != "",
+	}
+}
+
+// Notify writes the escape sequence for n.
+func (t *Terminal) Notify(n Notification) error {
+	t.write(t.Sequence(n))
+	return nil
+}
+
+// Sequence returns the raw bytes that Notify would write.
+func (t *Terminal) Sequence(n Notification) string {
+	var seq string
+	switch t.kind {
+	case OSC9:
+		text := n.Body
+		if n.Title != "" {
+			text = n.Title + ": " + text
+		}
+		seq = "\x1b]9;" + sanitize(text) + "\a"
+	case OSC777:
+		// Fields are ';'-separated, so the title must not contain one.
+		title := strings.ReplaceAll(sanitize(n.Title), ";", ",")
+		seq = "\x1b]777;notify;" + title + ";" + sanitize(n.Body) + "\a"
+	default:
+		// tmux handles BEL itself; no passthrough needed.
+		return "\a"
+	}
+	if t.tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// sanitize strips control characters that would terminate or corrupt an
+// OSC sequence.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return ' '
+		}
+		return r
+	}, s)
+}
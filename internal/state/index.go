@@ -0,0 +1,306 @@
+package state
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// Query is a parsed local search query. Words must all appear in a
+// message, the last one as a prefix so results update as the user types;
+// quoted phrases must appear as consecutive words. from: matches part of
+// the sender's name, and before:/after: take a YYYY-MM-DD date.
+type Query struct {
+	Words   []string
+	Phrases [][]string
+	From    string
+	Before  time.Time // exclusive
+	After   time.Time // inclusive
+	prefix  bool      // the last word is still being typed
+}
+
+// queryDateLayout is the date format of before: and after:.
+const queryDateLayout = "2006-01-02"
+
+// ParseQuery parses a search query such as
+// `deploy "on call" from:ada after:2024-05-01`. Filters with unparseable
+// values are treated as plain words.
+func ParseQuery(q string) Query {
+	var query Query
+	rest := q
+	for {
+		open := strings.IndexByte(rest, '"')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[open+1:], '"')
+		var phrase string
+		if end < 0 {
+			phrase, rest = rest[open+1:], rest[:open]
+		} else {
+			phrase, rest = rest[open+1:open+1+end], rest[:open]+" "+rest[open+2+end:]
+		}
+		if words := tokenize(phrase); len(words) > 0 {
+			query.Phrases = append(query.Phrases, words)
+		}
+	}
+
+	for _, field := range strings.Fields(rest) {
+		key, value, ok := strings.Cut(field, ":")
+		if ok && value != "" {
+			switch strings.ToLower(key) {
+			case "from":
+				query.From = strings.ToLower(value)
+				continue
+			case "before":
+				if t, err := time.ParseInLocation(queryDateLayout, value, time.Local); err == nil {
+					query.Before = t
+					continue
+				}
+			case "after":
+				if t, err := time.ParseInLocation(queryDateLayout, value, time.Local); err == nil {
+					query.After = t
+					continue
+				}
+			}
+		}
+		query.Words = append(query.Words, tokenize(field)...)
+	}
+	// Only a trailing word without a space after it can be half-typed.
+	query.prefix = len(query.Words) > 0 && !strings.HasSuffix(q, " ") && !strings.HasSuffix(q, "\"")
+	return query
+}
+
+// Empty reports whether the query matches nothing in particular.
+func (q Query) Empty() bool {
+	return len(q.Words) == 0 && len(q.Phrases) == 0 && q.From == "" &&
+		q.Before.IsZero() && q.After.IsZero()
+}
+
+// Terms returns the words and phrases to highlight in matching text.
+func (q Query) Terms() []string {
+	terms := append([]string(nil), q.Words...)
+	for _, p := range q.Phrases {
+		terms = append(terms, strings.Join(p, " "))
+	}
+	return terms
+}
+
+// tokenize lowercases text and splits it into words of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// docKey identifies a message across chats.
+type docKey struct {
+	chatID int64
+	id     int
+}
+
+// indexedDoc is what the index keeps about a message.
+type indexedDoc struct {
+	words  []string
+	sender string // lowercased
+	time   time.Time
+}
+
+// index is an inverted index from words to the cached messages
+// containing them. It is guarded by the Store's mutex.
+type index struct {
+	postings map[string]map[docKey]struct{}
+	docs     map[docKey]indexedDoc
+	chats    map[int64]map[int]struct{} // message IDs indexed per chat
+}
+
+func newIndex() *index {
+	return &index{
+		postings: make(map[string]map[docKey]struct{}),
+		docs:     make(map[docKey]indexedDoc),
+		chats:    make(map[int64]map[int]struct{}),
+	}
+}
+
+// add indexes a message, replacing any previous version of it.
+func (x *index) add(msg domain.Message) {
+	if msg.ID == 0 {
+		return // not yet confirmed by the server
+	}
+	key := docKey{msg.ChatID, msg.ID}
+	x.remove(key)
+	doc := indexedDoc{
+		words:  tokenize(msg.Text),
+		sender: strings.ToLower(msg.SenderName),
+		time:   msg.Timestamp,
+	}
+	x.docs[key] = doc
+	for _, w := range doc.words {
+		if x.postings[w] == nil {
+			x.postings[w] = make(map[docKey]struct{})
+		}
+		x.postings[w][key] = struct{}{}
+	}
+	if x.chats[msg.ChatID] == nil {
+		x.chats[msg.ChatID] = make(map[int]struct{})
+	}
+	x.chats[msg.ChatID][msg.ID] = struct{}{}
+}
+
+// remove drops a message from the index.
+func (x *index) remove(key docKey) {
+	doc, ok := x.docs[key]
+	if !ok {
+		return
+	}
+	for _, w := range doc.words {
+		if docs := x.postings[w]; docs != nil {
+			delete(docs, key)
+			if len(docs) == 0 {
+				delete(x.postings, w)
+			}
+		}
+	}
+	delete(x.docs, key)
+	delete(x.chats[key.chatID], key.id)
+}
+
+// setChat re-indexes a chat to match its cached messages.
+func (x *index) setChat(chatID int64, msgs []domain.Message) {
+	for id := range x.chats[chatID] {
+		x.remove(docKey{chatID, id})
+	}
+	for _, msg := range msgs {
+		x.add(msg)
+	}
+}
+
+// search returns the messages matching q, in chatID or in all chats when
+// chatID is 0, in no particular order.
+func (x *index) search(q Query, chatID int64) []docKey {
+	// Narrow the candidates with the rarest posting list among the
+	// query's words, then check each candidate fully.
+	var candidates map[docKey]struct{}
+	words := append([]string(nil), q.Words...)
+	if q.prefix {
+		words = words[:len(words)-1]
+	}
+	for _, p := range q.Phrases {
+		words = append(words, p...)
+	}
+	for _, w := range words {
+		docs := x.postings[w]
+		if candidates == nil || len(docs) < len(candidates) {
+			candidates = docs
+		}
+	}
+
+	var keys []docKey
+	check := func(key docKey) {
+		if (chatID == 0 || key.chatID == chatID) && x.matches(x.docs[key], q) {
+			keys = append(keys, key)
+		}
+	}
+	if candidates != nil || len(words) > 0 {
+		for key := range candidates {
+			check(key)
+		}
+	} else {
+		for key := range x.docs {
+			check(key)
+		}
+	}
+	return keys
+}
+
+// matches checks a message against every part of a query.
+func (x *index) matches(doc indexedDoc, q Query) bool {
+	if q.From != "" && !strings.Contains(doc.sender, q.From) {
+		return false
+	}
+	if !q.Before.IsZero() && !doc.time.Before(q.Before) {
+		return false
+	}
+	if !q.After.IsZero() && doc.time.Before(q.After) {
+		return false
+	}
+	for i, w := range q.Words {
+		prefix := q.prefix && i == len(q.Words)-1
+		if !containsWord(doc.words, w, prefix) {
+			return false
+		}
+	}
+	for _, p := range q.Phrases {
+		if !containsPhrase(doc.words, p) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsWord(words []string, w string, prefix bool) bool {
+	for _, word := range words {
+		if word == w || (prefix && strings.HasPrefix(word, w)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPhrase(words, phrase []string) bool {
+outer:
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		for j, w := range phrase {
+			if words[i+j] != w {
+				continue outer
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Search finds cached messages matching a query (see ParseQuery), newest
+// first, in one chat or in all chats when chatID is 0. Everything happens
+// in memory, so it is fast enough to run on every keystroke and works
+// offline.
+func (s *Store) Search(chatID int64, query string, limit int) []domain.Message {
+	q := ParseQuery(query)
+	if q.Empty() {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := s.index.search(q, chatID)
+	sort.Slice(keys, func(i, j int) bool {
+		ti, tj := s.index.docs[keys[i]].time, s.index.docs[keys[j]].time
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return keys[i].id > keys[j].id
+	})
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	byChat := make(map[int64]map[int]domain.Message)
+	out := make([]domain.Message, 0, len(keys))
+	for _, key := range keys {
+		byID, ok := byChat[key.chatID]
+		if !ok {
+			byID = make(map[int]domain.Message)
+			for _, msg := range s.messages[key.chatID] {
+				byID[msg.ID] = msg
+			}
+			byChat[key.chatID] = byID
+		}
+		if msg, ok := byID[key.id]; ok {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package state_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/state"
+)
+
+func indexedStore() *state.Store {
+	s := state.New(nil)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.Local) }
+	s.SetMessages(1, []domain.Message{
+		{ID: 1, ChatID: 1, SenderName: "Ada", Text: "Deploy is on call tonight", Timestamp: day(1)},
+		{ID: 2, ChatID: 1, SenderName: "Bob", Text: "Who is on call?", Timestamp: day(2)},
+		{ID: 3, ChatID: 1, SenderName: "Ada", Text: "Call me, deployment failed", Timestamp: day(3)},
+	})
+	s.SetMessages(2, []domain.Message{
+		{ID: 1, ChatID: 2, SenderName: "Cy", Text: "call later", Timestamp: day(4)},
+	})
+	return s
+}
+
+func TestStore_Search(t *testing.T) {
+	s := indexedStore()
+
+	tests := []struct {
+		name   string
+		chatID int64
+		query  string
+		want   []int
+	}{
+		{"word newest first", 1, "call", []int{3, 2, 1}},
+		{"all chats", 0, "call ", []int{1, 3, 2, 1}},
+		{"prefix while typing", 1, "depl", []int{3, 1}},
+		{"no prefix after space", 1, "depl ", []int{}},
+		{"phrase", 1, `"on call"`, []int{2, 1}},
+		{"phrase order matters", 1, `"call on"`, []int{}},
+		{"sender", 1, "call from:ad", []int{3, 1}},
+		{"sender only", 1, "from:bob", []int{2}},
+		{"after", 1, "call after:2024-05-02", []int{3, 2}},
+		{"before", 1, "call before:2024-05-02", []int{1}},
+		{"empty", 1, "  ", []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := msgIDs(s.Search(tt.chatID, tt.query, 0)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_Search_FollowsCache(t *testing.T) {
+	s := indexedStore()
+
+	s.OnNewMessage(domain.Message{ID: 4, ChatID: 1, Text: "new call", Timestamp: time.Now()})
+	if got := msgIDs(s.Search(1, "call", 1)); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("after new message = %v, want [4]", got)
+	}
+
+	s.SetMessages(1, history(1, 10))
+	if got := s.Search(1, "call", 0); len(got) != 0 {
+		t.Errorf("after replacing the cache = %v, want nothing", msgIDs(got))
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q := state.ParseQuery(`Deploy "On  Call" from:Ada after:2024-05-01 before:soon`)
+	if want := []string{"deploy", "before", "soon"}; !reflect.DeepEqual(q.Words, want) {
+		t.Errorf("Words = %v, want %v", q.Words, want)
+	}
+	if want := [][]string{{"on", "call"}}; !reflect.DeepEqual(q.Phrases, want) {
+		t.Errorf("Phrases = %v, want %v", q.Phrases, want)
+	}
+	if q.From != "ada" {
+		t.Errorf("From = %q, want %q", q.From, "ada")
+	}
+	if want := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local); !q.After.Equal(want) {
+		t.Errorf("After = %v, want %v", q.After, want)
+	}
+	if !q.Before.IsZero() {
+		t.Errorf("Before = %v, want zero for an unparseable date", q.Before)
+	}
+}
@@ -20,17 +20,32 @@ type Store struct {
 	mu         sync.RWMutex
 	chatList   []domain.ChatInfo
 	messages   map[int64][]domain.Message
+	gaps       map[int64]map[int]bool // IDs followed by missing history
+	mentions   map[int64][]int        // unread mention message IDs, ascending
+	members    map[int64][]domain.Participant
+	commands   map[int64][]domain.BotCommand
+	reactions  map[int64][]string         // allowed reactions; nil entry = any
+	pins       map[int64][]domain.Message // pinned messages, newest first
+	index      *index                     // full-text index of messages
 	typing     map[int64]*typingInfo
 	activeChat int64
 	authState  domain.AuthState
 	drawFunc   func()
+	notifyFunc func(msg domain.Message, chat domain.ChatInfo)
 }
 
 func New(drawFunc func()) *Store {
 	return &Store{
-		messages: make(map[int64][]domain.Message),
-		typing:   make(map[int64]*typingInfo),
-		drawFunc: drawFunc,
+		messages:  make(map[int64][]domain.Message),
+		gaps:      make(map[int64]map[int]bool),
+		mentions:  make(map[int64][]int),
+		members:   make(map[int64][]domain.Participant),
+		commands:  make(map[int64][]domain.BotCommand),
+		reactions: make(map[int64][]string),
+		pins:      make(map[int64][]domain.Message),
+		index:     newIndex(),
+		typing:    make(map[int64]*typingInfo),
+		drawFunc:  drawFunc,
 	}
 }
 
@@ -40,6 +55,14 @@ func (s *Store) SetDrawFunc(f func()) {
 	s.drawFunc = f
 }
 
+// SetNotifyFunc registers a callback for incoming messages that deserve a
+// notification: not sent by us, not in the active chat, and not muted.
+func (s *Store) SetNotifyFunc(f func(msg domain.Message, chat domain.ChatInfo)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifyFunc = f
+}
+
 func (s *Store) draw() {
 	if s.drawFunc != nil {
 		s.drawFunc()
@@ -64,15 +87,33 @@ func (s *Store) OnNewMessage(msg domain.Message) {
 
 	msgs = append(msgs, msg)
 	if len(msgs) > maxMessages {
+		for _, old := range msgs[:len(msgs)-maxMessages] {
+			s.index.remove(docKey{old.ChatID, old.ID})
+			delete(s.gaps[old.ChatID], old.ID)
+		}
 		msgs = msgs[len(msgs)-maxMessages:]
 	}
 	s.messages[msg.ChatID] = msgs
+	s.index.add(msg)
+
+	mentioned := msg.Mentioned && !msg.Out && msg.ID != 0
+	if mentioned {
+		s.mentions[msg.ChatID] = append(s.mentions[msg.ChatID], msg.ID)
+	}
 
 	// Update chat list: bump unread count and move to top
+	var notifyChat *domain.ChatInfo
 	for i, c := range s.chatList {
 		if c.ID == msg.ChatID {
 			if msg.ChatID != s.activeChat {
 				s.chatList[i].UnreadCount++
+				if !msg.Out && !c.Muted() {
+					chat := s.chatList[i]
+					notifyChat = &chat
+				}
+			}
+			if mentioned {
+				s.chatList[i].UnreadMentions++
 			}
 			s.chatList[i].LastMessage = msg.Text
 			s.chatList[i].LastTime = msg.Timestamp
@@ -80,8 +121,13 @@ func (s *Store) OnNewMessage(msg domain.Message) {
 		}
 	}
 	s.sortChatList()
+	notify := s.notifyFunc
 	s.mu.Unlock()
 	s.draw()
+
+	if notify != nil && notifyChat != nil {
+		notify(msg, *notifyChat)
+	}
 }
 
 func (s *Store) OnChatListUpdate(chats []domain.ChatInfo) {
@@ -92,6 +138,22 @@ func (s *Store) OnChatListUpdate(chats []domain.ChatInfo) {
 	s.draw()
 }
 
+// EnsureChat adds a chat to the chat list if it isn't there yet, such as a
+// public channel opened from search results.
+func (s *Store) EnsureChat(chat domain.ChatInfo) {
+	s.mu.Lock()
+	for _, c := range s.chatList {
+		if c.ID == chat.ID {
+			s.mu.Unlock()
+			return
+		}
+	}
+	s.chatList = append(s.chatList, chat)
+	s.sortChatList()
+	s.mu.Unlock()
+	s.draw()
+}
+
 func (s *Store) OnMessageRead(chatID int64, maxID int) {
 	s.mu.Lock()
 	for i, c := range s.chatList {
@@ -104,6 +166,309 @@ func (s *Store) OnMessageRead(chatID int64, maxID int) {
 	s.draw()
 }
 
+// OnNotifySettings records a chat's mute state, whether changed locally or
+// on another device.
+func (s *Store) OnNotifySettings(chatID int64, muteUntil time.Time) {
+	s.mu.Lock()
+	for i, c := range s.chatList {
+		if c.ID == chatID {
+			s.chatList[i].MuteUntil = muteUntil
+			break
+		}
+	}
+	s.mu.Unlock()
+	s.draw()
+}
+
+// OnDraft records a chat's draft, whether saved here or on another device.
+func (s *Store) OnDraft(chatID int64, text string) {
+	s.mu.Lock()
+	changed := false
+	for i, c := range s.chatList {
+		if c.ID == chatID {
+			changed = c.Draft != text
+			s.chatList[i].Draft = text
+			break
+		}
+	}
+	s.mu.Unlock()
+	if changed {
+		s.draw()
+	}
+}
+
+// GetDraft returns a chat's draft, or "" if it has none.
+func (s *Store) GetDraft(chatID int64) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.chatList {
+		if c.ID == chatID {
+			return c.Draft
+		}
+	}
+	return ""
+}
+
+// SetUnreadMentions replaces the unread mention IDs for a chat, as fetched
+// from the server, and syncs the chat's mention count to match.
+func (s *Store) SetUnreadMentions(chatID int64, ids []int) {
+	s.mu.Lock()
+	sorted := append([]int(nil), ids...)
+	sort.Ints(sorted)
+	s.mentions[chatID] = sorted
+	for i, c := range s.chatList {
+		if c.ID == chatID {
+			s.chatList[i].UnreadMentions = len(sorted)
+			break
+		}
+	}
+	s.mu.Unlock()
+	s.draw()
+}
+
+// NextUnreadMention returns the oldest unread mention in a chat.
+func (s *Store) NextUnreadMention(chatID int64) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := s.mentions[chatID]
+	if len(ids) == 0 {
+		return 0, false
+	}
+	return ids[0], true
+}
+
+// MarkMentionRead drops a mention from the unread set and returns how many
+// unread mentions remain in the chat.
+func (s *Store) MarkMentionRead(chatID int64, msgID int) int {
+	s.mu.Lock()
+	ids := s.mentions[chatID]
+	for i, id := range ids {
+		if id == msgID {
+			s.mentions[chatID] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	remaining := len(s.mentions[chatID])
+	for i, c := range s.chatList {
+		if c.ID == chatID {
+			if c.UnreadMentions > 0 {
+				s.chatList[i].UnreadMentions--
+			}
+			// Never report fewer mentions than we still know about.
+			if n := len(s.mentions[chatID]); s.chatList[i].UnreadMentions < n {
+				s.chatList[i].UnreadMentions = n
+			}
+			remaining = s.chatList[i].UnreadMentions
+			break
+		}
+	}
+	s.mu.Unlock()
+	s.draw()
+	return remaining
+}
+
+// SetParticipants caches a chat's members for mention completion. A nil
+// slice is cached too, so chats we can't list aren't fetched repeatedly.
+func (s *Store) SetParticipants(chatID int64, ps []domain.Participant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[chatID] = ps
+}
+
+// GetParticipants returns a chat's cached members and whether they have
+// been fetched.
+func (s *Store) GetParticipants(chatID int64) ([]domain.Participant, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ps, ok := s.members[chatID]
+	return ps, ok
+}
+
+// SetBotCommands caches the commands of a chat's bots for slash
+// completion. Like participants, a nil slice is cached too.
+func (s *Store) SetBotCommands(chatID int64, cmds []domain.BotCommand) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands[chatID] = cmds
+}
+
+// GetBotCommands returns a chat's cached bot commands and whether they
+// have been fetched.
+func (s *Store) GetBotCommands(chatID int64) ([]domain.BotCommand, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cmds, ok := s.commands[chatID]
+	return cmds, ok
+}
+
+// OnMessageEdited replaces a cached message with its edited version.
+// Edits to messages that aren't cached are dropped; they load with the
+// history.
+func (s *Store) OnMessageEdited(msg domain.Message) {
+	s.mu.Lock()
+	msgs := s.messages[msg.ChatID]
+	found := false
+	for i := range msgs {
+		if msgs[i].ID == msg.ID {
+			msgs[i] = msg
+			found = true
+			s.index.remove(docKey{msg.ChatID, msg.ID})
+			s.index.add(msg)
+			if i == len(msgs)-1 {
+				for j, c := range s.chatList {
+					if c.ID == msg.ChatID {
+						s.chatList[j].LastMessage = msg.Text
+						break
+					}
+				}
+			}
+			break
+		}
+	}
+	s.mu.Unlock()
+	if found {
+		s.draw()
+	}
+}
+
+// OnPollUpdated replaces a poll on the cached messages carrying it. A
+// poll forwarded to several chats appears in each.
+func (s *Store) OnPollUpdated(poll domain.Poll) {
+	s.mu.Lock()
+	found := false
+	for _, msgs := range s.messages {
+		for i := range msgs {
+			if msgs[i].Poll != nil && msgs[i].Poll.ID == poll.ID {
+				p := poll
+				msgs[i].Poll = &p
+				found = true
+			}
+		}
+	}
+	s.mu.Unlock()
+	if found {
+		s.draw()
+	}
+}
+
+// GetReplyKeyboard returns the reply keyboard a bot last set in a chat,
+// or nil if there is none, it was removed, or it was single-use and we
+// have replied since.
+func (s *Store) GetReplyKeyboard(chatID int64) [][]domain.Button {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	msgs := s.messages[chatID]
+	replied := false
+	for i := len(msgs) - 1; i >= 0; i-- {
+		kb := msgs[i].ReplyKeyboard
+		if kb == nil {
+			replied = replied || msgs[i].Out
+			continue
+		}
+		if kb.Hide || (kb.SingleUse && replied) {
+			return nil
+		}
+		return kb.Rows
+	}
+	return nil
+}
+
+// OnMessageReactions replaces the reaction tallies on a cached message.
+func (s *Store) OnMessageReactions(chatID int64, msgID int, reactions []domain.Reaction) {
+	s.mu.Lock()
+	msgs := s.messages[chatID]
+	found := false
+	for i := range msgs {
+		if msgs[i].ID == msgID {
+			msgs[i].Reactions = reactions
+			found = true
+			break
+		}
+	}
+	s.mu.Unlock()
+	if found {
+		s.draw()
+	}
+}
+
+// SetAvailableReactions caches the reactions allowed in a chat. A nil slice
+// means any reaction is allowed.
+func (s *Store) SetAvailableReactions(chatID int64, emoji []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reactions[chatID] = emoji
+}
+
+// GetAvailableReactions returns a chat's cached allowed reactions and
+// whether they have been fetched.
+func (s *Store) GetAvailableReactions(chatID int64) ([]string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	emoji, ok := s.reactions[chatID]
+	return emoji, ok
+}
+
+// SetPinnedMessages caches a chat's pinned messages, newest first. An
+// empty slice is cached too, so chats without pins aren't fetched again.
+func (s *Store) SetPinnedMessages(chatID int64, msgs []domain.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pins[chatID] = msgs
+}
+
+// GetPinnedMessages returns a chat's cached pinned messages and whether
+// they have been fetched.
+func (s *Store) GetPinnedMessages(chatID int64) ([]domain.Message, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	msgs, ok := s.pins[chatID]
+	return msgs, ok
+}
+
+// OnPinnedMessages applies a pin or unpin. Unpinned messages are dropped
+// from the cache; newly pinned ones are added when their content is
+// cached, otherwise the chat's pins are invalidated so they get fetched
+// again.
+func (s *Store) OnPinnedMessages(chatID int64, msgIDs []int, pinned bool) {
+	s.mu.Lock()
+	pins, ok := s.pins[chatID]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+
+	ids := make(map[int]bool, len(msgIDs))
+	for _, id := range msgIDs {
+		ids[id] = true
+	}
+	kept := make([]domain.Message, 0, len(pins)+len(msgIDs))
+	for _, p := range pins {
+		if !ids[p.ID] {
+			kept = append(kept, p)
+		}
+	}
+
+	if pinned {
+		found := 0
+		for _, msg := range s.messages[chatID] {
+			if ids[msg.ID] {
+				kept = append(kept, msg)
+				found++
+			}
+		}
+		if found < len(ids) {
+			delete(s.pins, chatID)
+			s.mu.Unlock()
+			s.draw()
+			return
+		}
+		sort.Slice(kept, func(i, j int) bool { return kept[i].ID > kept[j].ID })
+	}
+	s.pins[chatID] = kept
+	s.mu.Unlock()
+	s.draw()
+}
+
 func (s *Store) OnUserStatus(userID int64, online bool) {
 	// Future: update online indicators
 }
@@ -165,6 +530,20 @@ func (s *Store) GetChatList() []domain.ChatInfo {
 	return out
 }
 
+// GetTotalUnread returns the number of unread messages across all chats,
+// ignoring chats whose notifications are muted.
+func (s *Store) GetTotalUnread() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	total := 0
+	for _, c := range s.chatList {
+		if !c.Muted() {
+			total += c.UnreadCount
+		}
+	}
+	return total
+}
+
 func (s *Store) GetMessages(chatID int64) []domain.Message {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -177,10 +556,124 @@ func (s *Store) GetMessages(chatID int64) []domain.Message {
 func (s *Store) SetMessages(chatID int64, msgs []domain.Message) {
 	s.mu.Lock()
 	s.messages[chatID] = msgs
+	delete(s.gaps, chatID)
+	s.index.setChat(chatID, msgs)
 	s.mu.Unlock()
 	s.draw()
 }
 
+// historySpan is a contiguous run of cached history. tailGap marks that
+// newer messages may be missing after its last message.
+type historySpan struct {
+	msgs    []domain.Message
+	tailGap bool
+}
+
+// InsertHistory merges a contiguous run of history, such as a window
+// fetched around a jump target, into a chat's cache. The cache may hold
+// several disjoint runs; a gap marker after the last message of a run
+// records that the messages between it and the next run are missing.
+// reachedLatest reports that the run ends at the chat's newest message.
+func (s *Store) InsertHistory(chatID int64, run []domain.Message, reachedLatest bool) {
+	if len(run) == 0 {
+		return
+	}
+	s.mu.Lock()
+	existing := s.messages[chatID]
+	gaps := s.gaps[chatID]
+	lo, hi := run[0].ID, run[len(run)-1].ID
+
+	// Split the cache into its contiguous runs.
+	var spans []historySpan
+	start := 0
+	for i, msg := range existing {
+		if gaps[msg.ID] || i == len(existing)-1 {
+			spans = append(spans, historySpan{msgs: existing[start : i+1], tailGap: gaps[msg.ID]})
+			start = i + 1
+		}
+	}
+
+	// Runs overlapping the new one by ID are part of the same stretch of
+	// history, so they merge with it.
+	byID := make(map[int]domain.Message, len(run))
+	merged := historySpan{tailGap: !reachedLatest}
+	end := hi
+	var out []historySpan
+	for _, sp := range spans {
+		first, last := sp.msgs[0].ID, sp.msgs[len(sp.msgs)-1].ID
+		if first > hi || last < lo {
+			out = append(out, sp)
+			continue
+		}
+		for _, msg := range sp.msgs {
+			byID[msg.ID] = msg
+		}
+		switch {
+		case last > end:
+			end = last
+			merged.tailGap = sp.tailGap
+		case last == end:
+			merged.tailGap = merged.tailGap && sp.tailGap
+		}
+	}
+	for _, msg := range run {
+		byID[msg.ID] = msg
+	}
+	for _, msg := range byID {
+		merged.msgs = append(merged.msgs, msg)
+	}
+	sort.Slice(merged.msgs, func(i, j int) bool { return merged.msgs[i].ID < merged.msgs[j].ID })
+	out = append(out, merged)
+	sort.Slice(out, func(i, j int) bool { return out[i].msgs[0].ID < out[j].msgs[0].ID })
+
+	var msgs []domain.Message
+	gaps = make(map[int]bool)
+	for i, sp := range out {
+		msgs = append(msgs, sp.msgs...)
+		// Only the newest run can end at the chat's latest message.
+		if sp.tailGap || i < len(out)-1 {
+			gaps[sp.msgs[len(sp.msgs)-1].ID] = true
+		}
+	}
+
+	// Over the limit, drop whichever end is farther from the new run.
+	if len(msgs) > maxMessages {
+		mid := sort.Search(len(msgs), func(i int) bool { return msgs[i].ID >= lo }) + len(run)/2
+		if mid < len(msgs)/2 {
+			msgs = msgs[:maxMessages]
+			gaps[msgs[len(msgs)-1].ID] = true
+		} else {
+			msgs = msgs[len(msgs)-maxMessages:]
+		}
+		kept := make(map[int]bool, len(gaps))
+		for _, msg := range msgs {
+			if gaps[msg.ID] {
+				kept[msg.ID] = true
+			}
+		}
+		gaps = kept
+	}
+
+	s.messages[chatID] = msgs
+	s.gaps[chatID] = gaps
+	s.index.setChat(chatID, msgs)
+	s.mu.Unlock()
+	s.draw()
+}
+
+// GetGaps returns, in ascending order, the IDs of cached messages that
+// are followed by missing history.
+func (s *Store) GetGaps(chatID int64) []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids []int
+	for id := range s.gaps[chatID] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
 // PrependMessages adds older messages to the front of the existing slice,
 // deduplicating by message ID and respecting maxMessages.
 func (s *Store) PrependMessages(chatID int64, msgs []domain.Message) {
@@ -203,9 +696,13 @@ func (s *Store) PrependMessages(chatID int64, msgs []domain.Message) {
 
 	combined := append(unique, existing...)
 	if len(combined) > maxMessages {
+		for _, old := range combined[:len(combined)-maxMessages] {
+			delete(s.gaps[chatID], old.ID)
+		}
 		combined = combined[len(combined)-maxMessages:]
 	}
 	s.messages[chatID] = combined
+	s.index.setChat(chatID, combined)
 	s.mu.Unlock()
 }
 
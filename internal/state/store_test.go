@@ -1,6 +1,7 @@
 package state_test
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -102,3 +103,363 @@ func TestStore_MessageLimit(t *testing.T) {
 		t.Errorf("messages = %d, want <= 500", len(msgs))
 	}
 }
+
+func TestStore_OnNotifySettings(t *testing.T) {
+	s := state.New(nil)
+
+	s.OnChatListUpdate([]domain.ChatInfo{
+		{ID: 1, Title: "Alice", UnreadCount: 2},
+		{ID: 2, Title: "Noisy group", UnreadCount: 40},
+	})
+
+	if got := s.GetTotalUnread(); got != 42 {
+		t.Errorf("GetTotalUnread() = %d, want 42", got)
+	}
+
+	s.OnNotifySettings(2, time.Now().Add(time.Hour))
+	if got := s.GetTotalUnread(); got != 2 {
+		t.Errorf("GetTotalUnread() after mute = %d, want 2", got)
+	}
+
+	s.OnNotifySettings(2, time.Time{})
+	if got := s.GetTotalUnread(); got != 42 {
+		t.Errorf("GetTotalUnread() after unmute = %d, want 42", got)
+	}
+}
+
+func TestStore_NotifyFunc(t *testing.T) {
+	s := state.New(nil)
+
+	var notified []int
+	s.SetNotifyFunc(func(msg domain.Message, chat domain.ChatInfo) {
+		notified = append(notified, msg.ID)
+	})
+
+	s.OnChatListUpdate([]domain.ChatInfo{
+		{ID: 1, Title: "Alice"},
+		{ID: 2, Title: "Bob"},
+		{ID: 3, Title: "Noisy group", MuteUntil: time.Now().Add(time.Hour)},
+	})
+	s.SetActiveChat(1)
+
+	s.OnNewMessage(domain.Message{ID: 10, ChatID: 1, Text: "active chat"})
+	s.OnNewMessage(domain.Message{ID: 11, ChatID: 2, Text: "background chat"})
+	s.OnNewMessage(domain.Message{ID: 12, ChatID: 2, Text: "sent by us", Out: true})
+	s.OnNewMessage(domain.Message{ID: 13, ChatID: 3, Text: "muted chat"})
+	s.OnNewMessage(domain.Message{ID: 11, ChatID: 2, Text: "duplicate"})
+
+	if len(notified) != 1 || notified[0] != 11 {
+		t.Errorf("notified = %v, want [11]", notified)
+	}
+}
+
+func TestStore_UnreadMentions(t *testing.T) {
+	s := state.New(nil)
+
+	s.OnChatListUpdate([]domain.ChatInfo{{ID: 1, Title: "Ops"}})
+	s.SetUnreadMentions(1, []int{30, 10})
+
+	s.OnNewMessage(domain.Message{ID: 40, ChatID: 1, Text: "@me", Mentioned: true})
+	s.OnNewMessage(domain.Message{ID: 41, ChatID: 1, Text: "not for me"})
+	s.OnNewMessage(domain.Message{ID: 42, ChatID: 1, Text: "my reply", Mentioned: true, Out: true})
+
+	if got := s.GetChatList()[0].UnreadMentions; got != 3 {
+		t.Fatalf("UnreadMentions = %d, want 3", got)
+	}
+
+	var order []int
+	for {
+		id, ok := s.NextUnreadMention(1)
+		if !ok {
+			break
+		}
+		order = append(order, id)
+		s.MarkMentionRead(1, id)
+	}
+	if len(order) != 3 || order[0] != 10 || order[1] != 30 || order[2] != 40 {
+		t.Errorf("mention order = %v, want [10 30 40]", order)
+	}
+	if got := s.GetChatList()[0].UnreadMentions; got != 0 {
+		t.Errorf("UnreadMentions after reading = %d, want 0", got)
+	}
+}
+
+func TestStore_OnMessageReactions(t *testing.T) {
+	s := state.New(nil)
+
+	s.SetMessages(100, []domain.Message{
+		{ID: 1, ChatID: 100, Text: "first"},
+		{ID: 2, ChatID: 100, Text: "second"},
+	})
+
+	s.OnMessageReactions(100, 2, []domain.Reaction{{Emoji: "👍", Count: 3, Chosen: true}})
+
+	msgs := s.GetMessages(100)
+	if len(msgs[0].Reactions) != 0 {
+		t.Errorf("message 1 reactions = %v, want none", msgs[0].Reactions)
+	}
+	if r := msgs[1].Reactions; len(r) != 1 || r[0].Emoji != "👍" || r[0].Count != 3 || !r[0].Chosen {
+		t.Errorf("message 2 reactions = %v, want [👍 3 chosen]", r)
+	}
+
+	// Unknown messages are ignored.
+	s.OnMessageReactions(100, 99, []domain.Reaction{{Emoji: "🔥", Count: 1}})
+	if got := len(s.GetMessages(100)); got != 2 {
+		t.Errorf("messages = %d, want 2", got)
+	}
+}
+
+func TestStore_OnPinnedMessages(t *testing.T) {
+	s := state.New(nil)
+
+	s.SetMessages(100, []domain.Message{
+		{ID: 1, ChatID: 100, Text: "first"},
+		{ID: 2, ChatID: 100, Text: "second"},
+		{ID: 3, ChatID: 100, Text: "third"},
+	})
+
+	// Updates for chats whose pins were never fetched are ignored.
+	s.OnPinnedMessages(100, []int{1}, true)
+	if _, ok := s.GetPinnedMessages(100); ok {
+		t.Fatal("pins cached before being fetched")
+	}
+
+	s.SetPinnedMessages(100, []domain.Message{{ID: 1, ChatID: 100, Text: "first"}})
+
+	// Pinning a cached message inserts it newest first.
+	s.OnPinnedMessages(100, []int{3}, true)
+	pins, _ := s.GetPinnedMessages(100)
+	if len(pins) != 2 || pins[0].ID != 3 || pins[1].ID != 1 {
+		t.Errorf("pins = %v, want IDs [3 1]", pins)
+	}
+
+	s.OnPinnedMessages(100, []int{3}, false)
+	pins, _ = s.GetPinnedMessages(100)
+	if len(pins) != 1 || pins[0].ID != 1 {
+		t.Errorf("pins after unpin = %v, want IDs [1]", pins)
+	}
+
+	// Pinning a message we don't have invalidates the cache.
+	s.OnPinnedMessages(100, []int{42}, true)
+	if _, ok := s.GetPinnedMessages(100); ok {
+		t.Error("pins still cached after pinning an unknown message")
+	}
+}
+
+func msgIDs(msgs []domain.Message) []int {
+	ids := make([]int, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func history(chatID int64, ids ...int) []domain.Message {
+	msgs := make([]domain.Message, len(ids))
+	for i, id := range ids {
+		msgs[i] = domain.Message{ID: id, ChatID: chatID}
+	}
+	return msgs
+}
+
+func TestStore_InsertHistory(t *testing.T) {
+	s := state.New(nil)
+	s.SetMessages(100, history(100, 90, 91, 92))
+
+	// A window far back in history is separated from the live run by a gap.
+	s.InsertHistory(100, history(100, 10, 11, 12), false)
+	if got := msgIDs(s.GetMessages(100)); !reflect.DeepEqual(got, []int{10, 11, 12, 90, 91, 92}) {
+		t.Errorf("messages = %v", got)
+	}
+	if got := s.GetGaps(100); !reflect.DeepEqual(got, []int{12}) {
+		t.Errorf("gaps = %v, want [12]", got)
+	}
+
+	// Loading forward from the window without reaching the live run
+	// extends the window and moves the gap.
+	s.InsertHistory(100, history(100, 12, 13, 14), false)
+	if got := s.GetGaps(100); !reflect.DeepEqual(got, []int{14}) {
+		t.Errorf("gaps after extending = %v, want [14]", got)
+	}
+
+	// Overlapping the live run closes the gap.
+	s.InsertHistory(100, history(100, 14, 50, 90), false)
+	if got := msgIDs(s.GetMessages(100)); !reflect.DeepEqual(got, []int{10, 11, 12, 13, 14, 50, 90, 91, 92}) {
+		t.Errorf("messages after closing = %v", got)
+	}
+	if got := s.GetGaps(100); len(got) != 0 {
+		t.Errorf("gaps after closing = %v, want none", got)
+	}
+}
+
+func TestStore_InsertHistory_TailGap(t *testing.T) {
+	s := state.New(nil)
+
+	// A window that doesn't reach the latest message ends in a gap, which
+	// stays put as live messages arrive after it.
+	s.InsertHistory(100, history(100, 10, 11), false)
+	s.OnNewMessage(domain.Message{ID: 99, ChatID: 100})
+	if got := s.GetGaps(100); !reflect.DeepEqual(got, []int{11}) {
+		t.Errorf("gaps = %v, want [11]", got)
+	}
+
+	// Reaching the latest message with no overlap still leaves a gap
+	// between the runs.
+	s.SetMessages(200, nil)
+	s.InsertHistory(200, history(200, 10, 11), false)
+	s.InsertHistory(200, history(200, 50, 51), true)
+	if got := s.GetGaps(200); !reflect.DeepEqual(got, []int{11}) {
+		t.Errorf("gaps = %v, want [11]", got)
+	}
+
+	// SetMessages replaces the cache and its gaps.
+	s.SetMessages(200, history(200, 60))
+	if got := s.GetGaps(200); len(got) != 0 {
+		t.Errorf("gaps after SetMessages = %v, want none", got)
+	}
+}
+
+func TestStore_TrimDropsGaps(t *testing.T) {
+	s := state.New(nil)
+	s.InsertHistory(100, history(100, 10, 11), false)
+
+	// Live messages push the window, and its gap, out of the cache.
+	for id := 1000; id < 1500; id++ {
+		s.OnNewMessage(domain.Message{ID: id, ChatID: 100})
+	}
+	if got := s.GetGaps(100); len(got) != 0 {
+		t.Errorf("gaps = %v, want none", got)
+	}
+}
+
+func TestStore_EnsureChat(t *testing.T) {
+	s := state.New(nil)
+	s.OnChatListUpdate([]domain.ChatInfo{
+		{ID: 1, Title: "Existing", UnreadCount: 4, LastTime: time.Now()},
+	})
+
+	// Known chats are left untouched.
+	s.EnsureChat(domain.ChatInfo{ID: 1, Title: "Renamed"})
+	chats := s.GetChatList()
+	if len(chats) != 1 || chats[0].Title != "Existing" || chats[0].UnreadCount != 4 {
+		t.Errorf("chats = %+v, want the original chat", chats)
+	}
+
+	s.EnsureChat(domain.ChatInfo{ID: 2, Title: "Public channel"})
+	chats = s.GetChatList()
+	if len(chats) != 2 || chats[1].ID != 2 {
+		t.Errorf("chats = %+v, want the new chat appended", chats)
+	}
+}
+
+func TestStore_Drafts(t *testing.T) {
+	draws := 0
+	s := state.New(func() { draws++ })
+	s.OnChatListUpdate([]domain.ChatInfo{
+		{ID: 1, Title: "Alice", Draft: "from the phone"},
+		{ID: 2, Title: "Bob"},
+	})
+	draws = 0
+
+	if got := s.GetDraft(1); got != "from the phone" {
+		t.Errorf("GetDraft(1) = %q, want the draft from the dialog list", got)
+	}
+
+	s.OnDraft(2, "see you")
+	s.OnDraft(2, "see you")
+	if got := s.GetDraft(2); got != "see you" {
+		t.Errorf("GetDraft(2) = %q, want %q", got, "see you")
+	}
+	if draws != 1 {
+		t.Errorf("draws = %d, want 1 for an unchanged draft", draws)
+	}
+
+	s.OnDraft(1, "")
+	if got := s.GetDraft(1); got != "" {
+		t.Errorf("GetDraft(1) after clearing = %q, want empty", got)
+	}
+
+	// Drafts for chats not in the list are ignored.
+	s.OnDraft(3, "lost")
+	if got := s.GetDraft(3); got != "" {
+		t.Errorf("GetDraft(3) = %q, want empty", got)
+	}
+}
+
+func TestStore_OnMessageEdited(t *testing.T) {
+	s := state.New(nil)
+	s.OnChatListUpdate([]domain.ChatInfo{{ID: 100, Title: "Deploy bot"}})
+	s.SetMessages(100, []domain.Message{
+		{ID: 1, ChatID: 100, Text: "Deploy?"},
+		{ID: 2, ChatID: 100, Text: "Pick an environment"},
+	})
+
+	s.OnMessageEdited(domain.Message{ID: 2, ChatID: 100, Text: "Deploying to prod"})
+	msgs := s.GetMessages(100)
+	if msgs[1].Text != "Deploying to prod" {
+		t.Errorf("edited text = %q, want %q", msgs[1].Text, "Deploying to prod")
+	}
+	if got := s.GetChatList()[0].LastMessage; got != "Deploying to prod" {
+		t.Errorf("LastMessage = %q, want the edited text", got)
+	}
+	if hits := s.Search(100, "deploying", 10); len(hits) != 1 {
+		t.Errorf("search for the edited text found %d messages, want 1", len(hits))
+	}
+
+	// Edits to messages that aren't cached are dropped.
+	s.OnMessageEdited(domain.Message{ID: 9, ChatID: 100, Text: "old"})
+	if got := len(s.GetMessages(100)); got != 2 {
+		t.Errorf("messages = %d, want 2", got)
+	}
+}
+
+func TestStore_ReplyKeyboard(t *testing.T) {
+	s := state.New(nil)
+	yesNo := [][]domain.Button{{{Text: "Yes"}, {Text: "No"}}}
+
+	s.SetMessages(100, []domain.Message{
+		{ID: 1, ChatID: 100, Text: "Continue?", ReplyKeyboard: &domain.ReplyKeyboard{Rows: yesNo}},
+		{ID: 2, ChatID: 100, Text: "(still waiting)"},
+	})
+	if got := s.GetReplyKeyboard(100); !reflect.DeepEqual(got, yesNo) {
+		t.Errorf("keyboard = %v, want %v", got, yesNo)
+	}
+
+	s.OnNewMessage(domain.Message{ID: 3, ChatID: 100, Text: "Done", ReplyKeyboard: &domain.ReplyKeyboard{Hide: true}})
+	if got := s.GetReplyKeyboard(100); got != nil {
+		t.Errorf("keyboard after hide = %v, want none", got)
+	}
+
+	s.SetMessages(200, []domain.Message{
+		{ID: 1, ChatID: 200, Text: "Pick", ReplyKeyboard: &domain.ReplyKeyboard{Rows: yesNo, SingleUse: true}},
+	})
+	if s.GetReplyKeyboard(200) == nil {
+		t.Error("single-use keyboard hidden before replying")
+	}
+	s.OnNewMessage(domain.Message{ID: 2, ChatID: 200, Text: "Yes", Out: true})
+	if got := s.GetReplyKeyboard(200); got != nil {
+		t.Errorf("single-use keyboard after replying = %v, want none", got)
+	}
+}
+
+func TestStore_OnPollUpdated(t *testing.T) {
+	s := state.New(nil)
+	poll := &domain.Poll{ID: 7, Question: "Lunch?", Options: []domain.PollOption{{Text: "Pizza"}, {Text: "Salad"}}}
+	s.SetMessages(100, []domain.Message{{ID: 1, ChatID: 100, Poll: poll}})
+	s.SetMessages(200, []domain.Message{{ID: 5, ChatID: 200, Poll: poll}, {ID: 6, ChatID: 200, Text: "hi"}})
+
+	updated := *poll
+	updated.Options = []domain.PollOption{{Text: "Pizza", Voters: 3, Chosen: true}, {Text: "Salad", Voters: 1}}
+	updated.HasResults, updated.Voters = true, 4
+	s.OnPollUpdated(updated)
+
+	for _, chatID := range []int64{100, 200} {
+		got := s.GetMessages(chatID)[0].Poll
+		if got == nil || got.Voters != 4 || !got.Options[0].Chosen {
+			t.Errorf("chat %d poll = %+v, want the update", chatID, got)
+		}
+	}
+	if got := s.GetMessages(200)[1].Poll; got != nil {
+		t.Errorf("message without a poll got %+v", got)
+	}
+}
@@ -2,6 +2,8 @@ package telegram
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/danhigham/telecharm/internal/domain"
 )
@@ -14,14 +16,154 @@ type EventHandler interface {
 	OnUserStatus(userID int64, online bool)
 	OnUserTyping(chatID int64, userName string)
 	OnUserTypingStop(chatID int64)
+	OnNotifySettings(chatID int64, muteUntil time.Time)
+	OnMessageReactions(chatID int64, msgID int, reactions []domain.Reaction)
+	OnPinnedMessages(chatID int64, msgIDs []int, pinned bool)
+	OnDraft(chatID int64, text string)
+	// OnMessageEdited replaces a message, e.g. when a bot updates its
+	// keyboard.
+	OnMessageEdited(msg domain.Message)
+	// OnPollUpdated replaces a poll, e.g. with new vote counts, on every
+	// message carrying it.
+	OnPollUpdated(poll domain.Poll)
 }
 
+// HistoryQuery selects a window of chat history the way messages.getHistory
+// does: from the first message older than OffsetID (or sent before
+// OffsetDate), shifted by AddOffset. A negative AddOffset reaches newer
+// messages, so OffsetID X with AddOffset -Limit/2 centers the window on X.
+type HistoryQuery struct {
+	OffsetID   int
+	OffsetDate time.Time
+	AddOffset  int
+	Limit      int
+}
+
+// SearchFilter narrows a message search to one kind of content.
+type SearchFilter int
+
+const (
+	SearchAll SearchFilter = iota
+	SearchPhotos
+	SearchVideos
+	SearchDocuments
+	SearchLinks
+	SearchVoice
+)
+
+// String returns the filter's display name.
+func (f SearchFilter) String() string {
+	switch f {
+	case SearchPhotos:
+		return "Photos"
+	case SearchVideos:
+		return "Videos"
+	case SearchDocuments:
+		return "Files"
+	case SearchLinks:
+		return "Links"
+	case SearchVoice:
+		return "Voice"
+	default:
+		return "All"
+	}
+}
+
+// GlobalSearchResults groups the hits of a search across all chats.
+type GlobalSearchResults struct {
+	Chats    []domain.ChatInfo // chats we're in whose name matches
+	Public   []domain.ChatInfo // public users, groups and channels by username
+	Messages []domain.Message  // messages in any chat, newest first
+	Titles   map[int64]string  // titles of the chats the messages are in
+}
+
+// CallbackAnswer is a bot's reply to a callback button press.
+type CallbackAnswer struct {
+	Message string // may be empty
+	Alert   bool   // show Message as an alert rather than a notification
+	URL     string // a URL to open, e.g. for games
+}
+
+// InlineResults are an inline bot's answers to a query, such as
+// "@gif cats".
+type InlineResults struct {
+	QueryID     int64 // identifies the query when sending a result
+	Results     []domain.InlineResult
+	NextOffset  string // fetches more results; empty when there are none
+	Placeholder string // the bot's hint for what to type
+}
+
+// ErrNotInlineBot is returned by GetInlineResults for a username that
+// isn't a bot accepting inline queries.
+var ErrNotInlineBot = errors.New("not an inline bot")
+
 // Client is the interface for Telegram operations.
 type Client interface {
 	Run(ctx context.Context) error
-	SendMessage(ctx context.Context, chatID int64, text string) (domain.Message, error)
+	// SendMessage sends text, linking each mention span to its user.
+	SendMessage(ctx context.Context, chatID int64, text string, mentions []domain.Mention) (domain.Message, error)
 	GetHistory(ctx context.Context, chatID int64, limit int, offsetID int) ([]domain.Message, error)
+	// GetHistoryWindow returns the messages selected by q, oldest first.
+	GetHistoryWindow(ctx context.Context, chatID int64, q HistoryQuery) ([]domain.Message, error)
 	GetDialogs(ctx context.Context) ([]domain.ChatInfo, error)
 	MarkAsRead(ctx context.Context, chatID int64, maxID int) error
+	// SetMuteUntil silences notifications for a chat until the given time.
+	// A zero time unmutes the chat.
+	SetMuteUntil(ctx context.Context, chatID int64, until time.Time) error
+	// GetUnreadMentions returns the IDs of messages that mention us and are
+	// still unread, oldest first.
+	GetUnreadMentions(ctx context.Context, chatID int64) ([]int, error)
+	// ReadMentions marks all mentions in a chat as read.
+	ReadMentions(ctx context.Context, chatID int64) error
+	// GetParticipants returns the members of a chat, excluding ourselves.
+	// For large groups only recently active members are returned.
+	GetParticipants(ctx context.Context, chatID int64) ([]domain.Participant, error)
+	// SetReaction replaces our reaction on a message; an empty emoji
+	// removes it.
+	SetReaction(ctx context.Context, chatID int64, msgID int, emoji string) error
+	// GetAvailableReactions returns the reactions allowed in a chat, or nil
+	// if any reaction is allowed.
+	GetAvailableReactions(ctx context.Context, chatID int64) ([]string, error)
+	// ForwardMessages forwards messages to another chat and returns the
+	// new copies in the destination.
+	ForwardMessages(ctx context.Context, fromChatID int64, msgIDs []int, toChatID int64) ([]domain.Message, error)
+	// GetPinnedMessages returns a chat's pinned messages, newest first.
+	GetPinnedMessages(ctx context.Context, chatID int64) ([]domain.Message, error)
+	// PinMessage pins or unpins a message.
+	PinMessage(ctx context.Context, chatID int64, msgID int, pin bool) error
+	// SaveDraft stores unsent text for a chat on the server, where other
+	// devices pick it up. Empty text clears the draft.
+	SaveDraft(ctx context.Context, chatID int64, text string) error
+	// SearchMessages searches a chat's history, newest first. The query may
+	// be empty when filtering by content type.
+	SearchMessages(ctx context.Context, chatID int64, query string, filter SearchFilter) ([]domain.Message, error)
+	// SearchGlobal searches chat names, public usernames and messages
+	// across all chats. Chats it returns can be opened like any dialog.
+	SearchGlobal(ctx context.Context, query string) (GlobalSearchResults, error)
+	// SendFile uploads a file from disk and sends it as a document with
+	// an optional caption.
+	SendFile(ctx context.Context, chatID int64, path, caption string) (domain.Message, error)
+	// JoinChat joins a group or channel by invite link or public username
+	// and returns it, ready to open. A user's username is just resolved.
+	JoinChat(ctx context.Context, target string) (domain.ChatInfo, error)
+	// SetName changes our profile's first and last name.
+	SetName(ctx context.Context, first, last string) error
+	// GetBotCommands returns the commands of the bots in a chat, or nil if
+	// it has none.
+	GetBotCommands(ctx context.Context, chatID int64) ([]domain.BotCommand, error)
+	// PressButton presses a callback button on a message and returns the
+	// bot's answer.
+	PressButton(ctx context.Context, chatID int64, msgID int, data []byte) (CallbackAnswer, error)
+	// GetInlineResults asks an inline bot, by username, for results to
+	// send to a chat. offset continues from a previous page's NextOffset.
+	GetInlineResults(ctx context.Context, chatID int64, bot, query, offset string) (InlineResults, error)
+	// SendInlineResult sends one of an inline query's results to a chat.
+	SendInlineResult(ctx context.Context, chatID, queryID int64, resultID string) (domain.Message, error)
+	// Vote votes in the poll on a message and returns the updated poll.
+	// No options retracts our vote.
+	Vote(ctx context.Context, chatID int64, msgID int, options [][]byte) (domain.Poll, error)
+	// SendPoll sends a new poll with p's question, option texts and the
+	// Multiple and Public settings.
+	SendPoll(ctx context.Context, chatID int64, p domain.Poll) (domain.Message, error)
 	GetSelfName() string
 }
@@ -2,8 +2,13 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,9 +18,12 @@ import (
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/telegram/auth"
 	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/telegram/message/styling"
 	"github.com/gotd/td/telegram/query/dialogs"
 	"github.com/gotd/td/telegram/updates"
+	"github.com/gotd/td/telegram/uploader"
 	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
 
 	"github.com/danhigham/telecharm/internal/domain"
 )
@@ -37,6 +45,18 @@ type GotdClient struct {
 
 	peerCache map[int64]tg.InputPeerClass
 	nameCache map[int64]string
+	userCache map[int64]*tg.InputUser // for mention entities
+	chatCache map[int64]string        // group and channel titles
+	// inlineBots caches resolved usernames for inline queries, by lower
+	// case username; nil marks one that isn't an inline bot.
+	// inlineBotOrder lists the usernames, oldest first, for eviction.
+	inlineBots     map[string]*tg.User
+	inlineBotOrder []string
+	// polls caches the polls seen, by ID, since poll updates may carry
+	// only new results; pollOrder lists their IDs, oldest first, for
+	// eviction.
+	polls     map[int64]domain.Poll
+	pollOrder []int64
 	mu        sync.Mutex
 
 	onReady func()
@@ -57,6 +77,10 @@ func NewGotdClient(apiID int, apiHash, sessionDir string, handler EventHandler,
 		logger:     logger,
 		peerCache:  make(map[int64]tg.InputPeerClass),
 		nameCache:  make(map[int64]string),
+		userCache:  make(map[int64]*tg.InputUser),
+		chatCache:  make(map[int64]string),
+		inlineBots: make(map[string]*tg.User),
+		polls:      make(map[int64]domain.Poll),
 	}
 }
 
@@ -66,27 +90,56 @@ func (c *GotdClient) Run(ctx context.Context) error {
 
 	// Register message handlers on the dispatcher.
 	dispatcher.OnNewMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
-		msg, ok := update.Message.(*tg.Message)
+		c.cacheEntityTitles(e)
+		domainMsg, ok := c.convertMessageClass(update.Message, e.Users)
 		if !ok {
 			return nil
 		}
-		users := e.Users
-		domainMsg := c.convertMessage(msg, users)
 		c.handler.OnNewMessage(domainMsg)
 		return nil
 	})
 
 	dispatcher.OnNewChannelMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewChannelMessage) error {
-		msg, ok := update.Message.(*tg.Message)
+		c.cacheEntityTitles(e)
+		domainMsg, ok := c.convertMessageClass(update.Message, e.Users)
 		if !ok {
 			return nil
 		}
-		users := e.Users
-		domainMsg := c.convertMessage(msg, users)
 		c.handler.OnNewMessage(domainMsg)
 		return nil
 	})
 
+	// Bots edit messages to update their keyboards, e.g. after a button
+	// press.
+	dispatcher.OnEditMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateEditMessage) error {
+		msg, ok := update.Message.(*tg.Message)
+		if !ok {
+			return nil
+		}
+		c.handler.OnMessageEdited(c.convertMessage(msg, e.Users))
+		return nil
+	})
+
+	dispatcher.OnEditChannelMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateEditChannelMessage) error {
+		msg, ok := update.Message.(*tg.Message)
+		if !ok {
+			return nil
+		}
+		c.handler.OnMessageEdited(c.convertMessage(msg, e.Users))
+		return nil
+	})
+
+	dispatcher.OnMessagePoll(func(ctx context.Context, e tg.Entities, update *tg.UpdateMessagePoll) error {
+		var poll *tg.Poll
+		if p, ok := update.GetPoll(); ok {
+			poll = &p
+		}
+		if p, ok := c.updatePoll(update.PollID, poll, update.Results); ok {
+			c.handler.OnPollUpdated(p)
+		}
+		return nil
+	})
+
 	// Register typing event handlers.
 	dispatcher.OnUserTyping(func(ctx context.Context, e tg.Entities, update *tg.UpdateUserTyping) error {
 		switch update.Action.(type) {
@@ -129,6 +182,47 @@ func (c *GotdClient) Run(ctx context.Context) error {
 		return nil
 	})
 
+	dispatcher.OnNotifySettings(func(ctx context.Context, e tg.Entities, update *tg.UpdateNotifySettings) error {
+		np, ok := update.Peer.(*tg.NotifyPeer)
+		if !ok {
+			return nil
+		}
+		chatID := peerIDFromPeer(np.Peer)
+		if chatID == 0 {
+			return nil
+		}
+		c.handler.OnNotifySettings(chatID, muteUntilTime(update.NotifySettings.MuteUntil))
+		return nil
+	})
+
+	dispatcher.OnMessageReactions(func(ctx context.Context, e tg.Entities, update *tg.UpdateMessageReactions) error {
+		c.handleReactions(update)
+		return nil
+	})
+
+	dispatcher.OnPinnedMessages(func(ctx context.Context, e tg.Entities, update *tg.UpdatePinnedMessages) error {
+		c.handlePinned(update)
+		return nil
+	})
+
+	dispatcher.OnPinnedChannelMessages(func(ctx context.Context, e tg.Entities, update *tg.UpdatePinnedChannelMessages) error {
+		c.handlePinned(update)
+		return nil
+	})
+
+	dispatcher.OnDraftMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateDraftMessage) error {
+		// Drafts in forum topics and saved-message dialogs aren't shown.
+		if update.TopMsgID != 0 || update.SavedPeerID != nil {
+			return nil
+		}
+		chatID := peerIDFromPeer(update.Peer)
+		if chatID == 0 {
+			return nil
+		}
+		c.handler.OnDraft(chatID, draftText(update.Draft))
+		return nil
+	})
+
 	// Create gap-aware update manager.
 	c.gaps = updates.New(updates.Config{
 		Handler: dispatcher,
@@ -179,12 +273,20 @@ func (c *GotdClient) Run(ctx context.Context) error {
 }
 
 // SendMessage sends a text message to the given chat and returns the sent message.
-func (c *GotdClient) SendMessage(ctx context.Context, chatID int64, text string) (domain.Message, error) {
+// Mention spans become MessageEntityMentionName entities.
+func (c *GotdClient) SendMessage(ctx context.Context, chatID int64, text string, mentions []domain.Mention) (domain.Message, error) {
 	peer := c.findPeer(chatID)
 	if peer == nil {
 		return domain.Message{}, fmt.Errorf("unknown peer: %d", chatID)
 	}
-	upd, err := c.sender.To(peer).Text(ctx, text)
+
+	var upd tg.UpdatesClass
+	var err error
+	if len(mentions) == 0 {
+		upd, err = c.sender.To(peer).Clear().Text(ctx, text)
+	} else {
+		upd, err = c.sender.To(peer).Clear().StyledText(ctx, c.mentionStyling(text, mentions)...)
+	}
 	if err != nil {
 		return domain.Message{}, err
 	}
@@ -220,18 +322,59 @@ func (c *GotdClient) SendMessage(ctx context.Context, chatID int64, text string)
 	return msg, nil
 }
 
+// mentionStyling splits text into plain and mention-name segments. Styling
+// computes the UTF-16 entity offsets Telegram expects. Mentions of users we
+// have no access hash for are sent as plain text.
+func (c *GotdClient) mentionStyling(text string, mentions []domain.Mention) []styling.StyledTextOption {
+	sorted := append([]domain.Mention(nil), mentions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	var opts []styling.StyledTextOption
+	pos := 0
+	for _, mn := range sorted {
+		end := mn.Offset + mn.Length
+		if mn.Offset < pos || end > len(text) {
+			continue
+		}
+		user := c.findInputUser(mn.UserID)
+		if user == nil {
+			continue
+		}
+		if mn.Offset > pos {
+			opts = append(opts, styling.Plain(text[pos:mn.Offset]))
+		}
+		opts = append(opts, styling.MentionName(text[mn.Offset:end], user))
+		pos = end
+	}
+	if pos < len(text) {
+		opts = append(opts, styling.Plain(text[pos:]))
+	}
+	return opts
+}
+
 // GetHistory retrieves message history for a chat.
 func (c *GotdClient) GetHistory(ctx context.Context, chatID int64, limit int, offsetID int) ([]domain.Message, error) {
+	return c.GetHistoryWindow(ctx, chatID, HistoryQuery{OffsetID: offsetID, Limit: limit})
+}
+
+// GetHistoryWindow fetches an arbitrary window of history, e.g. around a
+// message being jumped to.
+func (c *GotdClient) GetHistoryWindow(ctx context.Context, chatID int64, q HistoryQuery) ([]domain.Message, error) {
 	peer := c.findPeer(chatID)
 	if peer == nil {
 		return nil, fmt.Errorf("unknown peer: %d", chatID)
 	}
 
-	result, err := c.api.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
-		Peer:     peer,
-		Limit:    limit,
-		OffsetID: offsetID,
-	})
+	req := &tg.MessagesGetHistoryRequest{
+		Peer:      peer,
+		Limit:     q.Limit,
+		OffsetID:  q.OffsetID,
+		AddOffset: q.AddOffset,
+	}
+	if !q.OffsetDate.IsZero() {
+		req.OffsetDate = int(q.OffsetDate.Unix())
+	}
+	result, err := c.api.MessagesGetHistory(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("get history: %w", err)
 	}
@@ -258,27 +401,42 @@ func (c *GotdClient) GetDialogs(ctx context.Context) ([]domain.ChatInfo, error)
 		title := c.titleFromEntities(elem)
 
 		// Get dialog details.
-		var unreadCount int
+		var unreadCount, unreadMentions int
 		var lastMsg string
 		var lastTime time.Time
+		var muteUntil time.Time
+		var draft string
 
 		if dlg, ok := elem.Dialog.(*tg.Dialog); ok {
 			unreadCount = dlg.UnreadCount
+			unreadMentions = dlg.UnreadMentionsCount
+			muteUntil = muteUntilTime(dlg.NotifySettings.MuteUntil)
+			draft = draftText(dlg.Draft)
 		}
 		if elem.Last != nil {
-			if msg, ok := elem.Last.(*tg.Message); ok {
+			switch msg := elem.Last.(type) {
+			case *tg.Message:
 				lastMsg = msg.Message
 				lastTime = time.Unix(int64(msg.Date), 0)
+			case *tg.MessageService:
+				if m, ok := c.convertService(msg, elem.Entities.Users()); ok {
+					lastMsg = m.Text
+				}
+				lastTime = time.Unix(int64(msg.Date), 0)
 			}
 		}
 
 		result = append(result, domain.ChatInfo{
-			ID:          peerID,
-			Title:       title,
-			UnreadCount: unreadCount,
-			LastMessage: lastMsg,
-			LastTime:    lastTime,
-			Peer:        elem.Peer,
+			ID:             peerID,
+			Title:          title,
+			Username:       usernameFromEntities(elem),
+			UnreadCount:    unreadCount,
+			UnreadMentions: unreadMentions,
+			LastMessage:    lastMsg,
+			LastTime:       lastTime,
+			MuteUntil:      muteUntil,
+			Draft:          draft,
+			Peer:           elem.Peer,
 		})
 	}
 	if err := iter.Err(); err != nil {
@@ -319,190 +477,1648 @@ func (c *GotdClient) MarkAsRead(ctx context.Context, chatID int64, maxID int) er
 	}
 }
 
-// findPeer looks up a cached peer by chat ID.
-func (c *GotdClient) findPeer(chatID int64) tg.InputPeerClass {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.peerCache[chatID]
-}
+// SetMuteUntil updates the notification settings for a chat. Telegram has no
+// separate "unmute" call; a MuteUntil of 0 re-enables notifications.
+func (c *GotdClient) SetMuteUntil(ctx context.Context, chatID int64, until time.Time) error {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return fmt.Errorf("unknown peer: %d", chatID)
+	}
 
-// cachePeer stores a peer in the cache.
-func (c *GotdClient) cachePeer(chatID int64, peer tg.InputPeerClass) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.peerCache[chatID] = peer
-}
+	var muteUntil int
+	if !until.IsZero() {
+		muteUntil = int(until.Unix())
+		if until.Unix() > math.MaxInt32 {
+			muteUntil = math.MaxInt32
+		}
+	}
 
-// cacheUserName stores a user's display name for later lookup (e.g. typing indicators).
-func (c *GotdClient) cacheUserName(userID int64, name string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.nameCache[userID] = name
+	settings := tg.InputPeerNotifySettings{}
+	settings.SetMuteUntil(muteUntil)
+	_, err := c.api.AccountUpdateNotifySettings(ctx, &tg.AccountUpdateNotifySettingsRequest{
+		Peer:     &tg.InputNotifyPeer{Peer: peer},
+		Settings: settings,
+	})
+	if err != nil {
+		return fmt.Errorf("update notify settings: %w", err)
+	}
+	return nil
 }
 
-// findUserName looks up a cached user display name.
-func (c *GotdClient) findUserName(userID int64) string {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.nameCache[userID]
+// SaveDraft saves or, for empty text, clears a chat's draft.
+func (c *GotdClient) SaveDraft(ctx context.Context, chatID int64, text string) error {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return fmt.Errorf("unknown peer: %d", chatID)
+	}
+	_, err := c.api.MessagesSaveDraft(ctx, &tg.MessagesSaveDraftRequest{
+		Peer:    peer,
+		Message: text,
+	})
+	if err != nil {
+		return fmt.Errorf("save draft: %w", err)
+	}
+	return nil
 }
 
-// convertMessage converts a tg.Message to a domain.Message.
-func (c *GotdClient) convertMessage(msg *tg.Message, users map[int64]*tg.User) domain.Message {
-	var senderName string
-	var senderID int64
+// draftText returns a draft's text, or "" for a cleared draft.
+func draftText(d tg.DraftMessageClass) string {
+	if dm, ok := d.(*tg.DraftMessage); ok {
+		return dm.Message
+	}
+	return ""
+}
 
-	if fromID := msg.FromID; fromID != nil {
-		switch p := fromID.(type) {
-		case *tg.PeerUser:
-			senderID = p.UserID
-			if u, ok := users[p.UserID]; ok {
-				senderName = formatUserName(u)
-			}
-		case *tg.PeerChat:
-			senderID = p.ChatID
-		case *tg.PeerChannel:
-			senderID = p.ChannelID
-		}
+// GetUnreadMentions returns the IDs of unread messages mentioning us, oldest
+// first. Only the most recent 100 are fetched.
+func (c *GotdClient) GetUnreadMentions(ctx context.Context, chatID int64) ([]int, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return nil, fmt.Errorf("unknown peer: %d", chatID)
 	}
 
-	// Determine chatID from PeerID.
-	var chatID int64
-	if peerID := msg.PeerID; peerID != nil {
-		switch p := peerID.(type) {
-		case *tg.PeerUser:
-			chatID = p.UserID
-		case *tg.PeerChat:
-			chatID = p.ChatID
-		case *tg.PeerChannel:
-			chatID = p.ChannelID
-		}
+	result, err := c.api.MessagesGetUnreadMentions(ctx, &tg.MessagesGetUnreadMentionsRequest{
+		Peer:  peer,
+		Limit: 100,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get unread mentions: %w", err)
 	}
 
-	// In DMs, FromID is often nil. Derive sender from PeerID and Out flag.
-	if senderName == "" && !msg.Out {
-		if p, ok := msg.PeerID.(*tg.PeerUser); ok {
-			senderID = p.UserID
-			if u, ok := users[p.UserID]; ok {
-				senderName = formatUserName(u)
-			}
-		}
+	msgs, err := c.convertHistoryResult(result)
+	if err != nil {
+		return nil, err
 	}
-	if senderName == "" && msg.Out && c.self != nil {
-		senderID = c.self.ID
-		senderName = formatUserName(c.self)
+	ids := make([]int, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
 	}
+	sort.Ints(ids)
+	return ids, nil
+}
 
-	// Cache the resolved name for typing indicator lookups.
-	if senderID != 0 && senderName != "" {
-		c.cacheUserName(senderID, senderName)
+// ReadMentions marks every mention in a chat as read.
+func (c *GotdClient) ReadMentions(ctx context.Context, chatID int64) error {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return fmt.Errorf("unknown peer: %d", chatID)
 	}
 
-	// Convert Telegram entities to markdown-formatted text.
-	text := msg.Message
-	hasMarkdown := false
-	if entities, ok := msg.GetEntities(); ok && len(entities) > 0 {
-		text = EntitiesToMarkdown(text, entities)
-		hasMarkdown = text != msg.Message
+	if _, err := c.api.MessagesReadMentions(ctx, &tg.MessagesReadMentionsRequest{Peer: peer}); err != nil {
+		return fmt.Errorf("read mentions: %w", err)
 	}
+	return nil
+}
 
-	return domain.Message{
-		ID:          msg.ID,
-		ChatID:      chatID,
-		SenderName:  senderName,
-		SenderID:    senderID,
-		Text:        text,
-		HasMarkdown: hasMarkdown,
-		Timestamp:   time.Unix(int64(msg.Date), 0),
-		Out:         msg.Out,
+// GetParticipants returns chat members for mention completion. Channels
+// and supergroups return the 200 most recently active members; basic groups
+// return everyone.
+func (c *GotdClient) GetParticipants(ctx context.Context, chatID int64) ([]domain.Participant, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return nil, fmt.Errorf("unknown peer: %d", chatID)
 	}
-}
 
-// convertHistoryResult extracts messages from a MessagesMessagesClass response.
-func (c *GotdClient) convertHistoryResult(result tg.MessagesMessagesClass) ([]domain.Message, error) {
-	var messages []tg.MessageClass
 	var users []tg.UserClass
-
-	switch r := result.(type) {
-	case *tg.MessagesMessages:
-		messages = r.Messages
-		users = r.Users
-	case *tg.MessagesMessagesSlice:
-		messages = r.Messages
-		users = r.Users
-	case *tg.MessagesChannelMessages:
-		messages = r.Messages
-		users = r.Users
+	switch p := peer.(type) {
+	case *tg.InputPeerChannel:
+		result, err := c.api.ChannelsGetParticipants(ctx, &tg.ChannelsGetParticipantsRequest{
+			Channel: &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash},
+			Filter:  &tg.ChannelParticipantsRecent{},
+			Limit:   200,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get participants: %w", err)
+		}
+		if r, ok := result.(*tg.ChannelsChannelParticipants); ok {
+			users = r.Users
+		}
+	case *tg.InputPeerChat:
+		result, err := c.api.MessagesGetFullChat(ctx, p.ChatID)
+		if err != nil {
+			return nil, fmt.Errorf("get full chat: %w", err)
+		}
+		users = result.Users
+	case *tg.InputPeerUser:
+		result, err := c.api.UsersGetUsers(ctx, []tg.InputUserClass{
+			&tg.InputUser{UserID: p.UserID, AccessHash: p.AccessHash},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+		users = result
 	default:
-		return nil, fmt.Errorf("unexpected messages type: %T", result)
-	}
-
-	userMap := usersToMap(users)
-
-	// Cache all user names for typing indicator lookups.
-	for id, u := range userMap {
-		c.cacheUserName(id, formatUserName(u))
+		return nil, fmt.Errorf("unsupported peer type for participants: %T", peer)
 	}
 
-	// Messages come in reverse chronological order from the API; reverse them.
-	var domainMsgs []domain.Message
-	for i := len(messages) - 1; i >= 0; i-- {
-		msg, ok := messages[i].(*tg.Message)
-		if !ok {
+	var out []domain.Participant
+	for _, u := range usersToMap(users) {
+		c.cacheInputUser(u)
+		if u.Self || u.Deleted {
 			continue
 		}
-		domainMsgs = append(domainMsgs, c.convertMessage(msg, userMap))
+		out = append(out, domain.Participant{
+			ID:       u.ID,
+			Name:     formatUserName(u),
+			Username: u.Username,
+			Bot:      u.Bot,
+		})
 	}
-
-	return domainMsgs, nil
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
 }
 
-// titleFromEntities extracts the chat title from dialog entities.
-func (c *GotdClient) titleFromEntities(elem dialogs.Elem) string {
-	if elem.Peer == nil {
-		return "Unknown"
+// SetReaction sets our reaction on a message, replacing any previous one.
+// The RPC result carries the new tallies, which are applied directly since
+// updates returned from calls don't pass through the dispatcher.
+func (c *GotdClient) SetReaction(ctx context.Context, chatID int64, msgID int, emoji string) error {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return fmt.Errorf("unknown peer: %d", chatID)
 	}
 
-	entities := elem.Entities
+	req := &tg.MessagesSendReactionRequest{
+		Peer:  peer,
+		MsgID: msgID,
+	}
+	if emoji != "" {
+		req.SetReaction([]tg.ReactionClass{&tg.ReactionEmoji{Emoticon: emoji}})
+		req.AddToRecent = true
+	}
+	result, err := c.api.MessagesSendReaction(ctx, req)
+	if err != nil {
+		return fmt.Errorf("send reaction: %w", err)
+	}
 
-	switch p := elem.Dialog.GetPeer().(type) {
-	case *tg.PeerUser:
-		if u, ok := entities.User(p.UserID); ok {
-			name := formatUserName(u)
-			c.cacheUserName(p.UserID, name)
-			return name
-		}
-	case *tg.PeerChat:
-		if ch, ok := entities.Chat(p.ChatID); ok {
-			return ch.Title
-		}
-	case *tg.PeerChannel:
-		if ch, ok := entities.Channel(p.ChannelID); ok {
-			return ch.Title
+	updates, _, _ := unpackUpdates(result)
+	for _, upd := range updates {
+		if r, ok := upd.(*tg.UpdateMessageReactions); ok {
+			c.handleReactions(r)
 		}
 	}
-
-	return "Unknown"
+	return nil
 }
 
-// peerIDFromInputPeer extracts a numeric peer ID from an InputPeerClass.
-func (c *GotdClient) peerIDFromInputPeer(peer tg.InputPeerClass) int64 {
+// GetAvailableReactions returns the emoji reactions enabled in a group or
+// channel. Private chats, and groups allowing all reactions, return nil.
+func (c *GotdClient) GetAvailableReactions(ctx context.Context, chatID int64) ([]string, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return nil, fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	var full *tg.MessagesChatFull
+	var err error
 	switch p := peer.(type) {
-	case *tg.InputPeerUser:
-		return p.UserID
-	case *tg.InputPeerChat:
-		return p.ChatID
 	case *tg.InputPeerChannel:
-		return p.ChannelID
+		full, err = c.api.ChannelsGetFullChannel(ctx, &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash})
+	case *tg.InputPeerChat:
+		full, err = c.api.MessagesGetFullChat(ctx, p.ChatID)
 	default:
-		return 0
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get full chat: %w", err)
 	}
-}
 
-// GetSelfName returns the logged-in user's display name.
-func (c *GotdClient) GetSelfName() string {
-	if c.self != nil {
-		return formatUserName(c.self)
+	available, ok := full.FullChat.GetAvailableReactions()
+	if !ok {
+		return nil, nil
+	}
+	switch r := available.(type) {
+	case *tg.ChatReactionsSome:
+		out := []string{}
+		for _, rc := range r.Reactions {
+			if e, ok := rc.(*tg.ReactionEmoji); ok {
+				out = append(out, e.Emoticon)
+			}
+		}
+		return out, nil
+	case *tg.ChatReactionsNone:
+		return []string{}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// ForwardMessages forwards messages between chats. The forwarded copies
+// arrive in the RPC result rather than through the dispatcher, so they
+// are converted and returned here.
+func (c *GotdClient) ForwardMessages(ctx context.Context, fromChatID int64, msgIDs []int, toChatID int64) ([]domain.Message, error) {
+	from := c.findPeer(fromChatID)
+	if from == nil {
+		return nil, fmt.Errorf("unknown peer: %d", fromChatID)
+	}
+	to := c.findPeer(toChatID)
+	if to == nil {
+		return nil, fmt.Errorf("unknown peer: %d", toChatID)
+	}
+
+	randomIDs := make([]int64, len(msgIDs))
+	for i := range randomIDs {
+		randomIDs[i] = rand.Int64()
+	}
+	result, err := c.api.MessagesForwardMessages(ctx, &tg.MessagesForwardMessagesRequest{
+		FromPeer: from,
+		ID:       msgIDs,
+		RandomID: randomIDs,
+		ToPeer:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("forward messages: %w", err)
+	}
+
+	return c.forwardedMessages(result), nil
+}
+
+// forwardedMessages returns the copies a forward created, as carried in
+// its result.
+func (c *GotdClient) forwardedMessages(result tg.UpdatesClass) []domain.Message {
+	updates, userList, chats := unpackUpdates(result)
+	c.cacheChatTitles(chats)
+	users := usersToMap(userList)
+	var out []domain.Message
+	for _, upd := range updates {
+		var mc tg.MessageClass
+		switch nm := upd.(type) {
+		case *tg.UpdateNewMessage:
+			mc = nm.Message
+		case *tg.UpdateNewChannelMessage:
+			mc = nm.Message
+		default:
+			continue
+		}
+		if msg, ok := mc.(*tg.Message); ok {
+			out = append(out, c.convertMessage(msg, users))
+		}
+	}
+	return out
+}
+
+// GetPinnedMessages searches a chat for pinned messages. Search results
+// come newest first, which is the order the pin bar cycles through.
+func (c *GotdClient) GetPinnedMessages(ctx context.Context, chatID int64) ([]domain.Message, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return nil, fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	result, err := c.api.MessagesSearch(ctx, &tg.MessagesSearchRequest{
+		Peer:   peer,
+		Filter: &tg.InputMessagesFilterPinned{},
+		Limit:  50,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search pinned: %w", err)
+	}
+
+	msgs, err := c.convertHistoryResult(result)
+	if err != nil {
+		return nil, err
+	}
+	// convertHistoryResult returns oldest first; restore newest first.
+	reverseMessages(msgs)
+	return msgs, nil
+}
+
+// searchLimit caps how many hits a message search returns.
+const searchLimit = 100
+
+// SearchMessages runs messages.search in a chat. Results come newest
+// first, which is the order n/N step through them.
+func (c *GotdClient) SearchMessages(ctx context.Context, chatID int64, query string, filter SearchFilter) ([]domain.Message, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return nil, fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	result, err := c.api.MessagesSearch(ctx, &tg.MessagesSearchRequest{
+		Peer:   peer,
+		Q:      query,
+		Filter: inputSearchFilter(filter),
+		Limit:  searchLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search messages: %w", err)
+	}
+
+	msgs, err := c.convertHistoryResult(result)
+	if err != nil {
+		return nil, err
+	}
+	reverseMessages(msgs)
+	return msgs, nil
+}
+
+// reverseMessages reverses msgs in place.
+func reverseMessages(msgs []domain.Message) {
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+}
+
+// globalSearchLimit caps each group of global search results.
+const globalSearchLimit = 30
+
+// SearchGlobal runs contacts.search for chats and usernames and
+// messages.searchGlobal for messages. Either half failing still returns
+// the other's results; only when both fail is an error returned.
+func (c *GotdClient) SearchGlobal(ctx context.Context, query string) (GlobalSearchResults, error) {
+	res := GlobalSearchResults{Titles: make(map[int64]string)}
+
+	found, contactsErr := c.api.ContactsSearch(ctx, &tg.ContactsSearchRequest{Q: query, Limit: globalSearchLimit})
+	if contactsErr == nil {
+		c.cacheEntities(found.Users, found.Chats)
+		res.Chats = c.chatsForPeers(found.MyResults)
+		res.Public = c.chatsForPeers(found.Results)
+	}
+
+	result, msgsErr := c.api.MessagesSearchGlobal(ctx, &tg.MessagesSearchGlobalRequest{
+		Q:          query,
+		Filter:     &tg.InputMessagesFilterEmpty{},
+		OffsetPeer: &tg.InputPeerEmpty{},
+		Limit:      globalSearchLimit,
+	})
+	if msgsErr == nil {
+		msgs, err := c.convertHistoryResult(result)
+		if err != nil {
+			msgsErr = err
+		} else {
+			reverseMessages(msgs)
+			res.Messages = msgs
+			for _, msg := range msgs {
+				res.Titles[msg.ChatID] = c.peerTitle(msg.ChatID)
+			}
+		}
+	}
+
+	switch {
+	case contactsErr != nil && msgsErr != nil:
+		return res, errors.Join(fmt.Errorf("search contacts: %w", contactsErr), fmt.Errorf("search messages: %w", msgsErr))
+	case contactsErr != nil:
+		c.logger.Warn("Global search: contacts search failed", zap.Error(contactsErr))
+	case msgsErr != nil:
+		c.logger.Warn("Global search: message search failed", zap.Error(msgsErr))
+	}
+	return res, nil
+}
+
+// chatsForPeers describes search result peers, whose entities must
+// already be cached.
+func (c *GotdClient) chatsForPeers(peers []tg.PeerClass) []domain.ChatInfo {
+	var out []domain.ChatInfo
+	for _, p := range peers {
+		id := peerIDFromPeer(p)
+		peer := c.findPeer(id)
+		if id == 0 || peer == nil {
+			continue
+		}
+		out = append(out, domain.ChatInfo{ID: id, Title: c.peerTitle(id), Peer: peer})
+	}
+	return out
+}
+
+// peerTitle names a chat from the caches: a group or channel title, or a
+// user's name.
+func (c *GotdClient) peerTitle(id int64) string {
+	if title := c.findChatTitle(id); title != "" {
+		return title
+	}
+	if name := c.findUserName(id); name != "" {
+		return name
+	}
+	return "Unknown"
+}
+
+// inputSearchFilter maps a SearchFilter to its MTProto filter.
+func inputSearchFilter(f SearchFilter) tg.MessagesFilterClass {
+	switch f {
+	case SearchPhotos:
+		return &tg.InputMessagesFilterPhotos{}
+	case SearchVideos:
+		return &tg.InputMessagesFilterVideo{}
+	case SearchDocuments:
+		return &tg.InputMessagesFilterDocument{}
+	case SearchLinks:
+		return &tg.InputMessagesFilterURL{}
+	case SearchVoice:
+		return &tg.InputMessagesFilterVoice{}
+	default:
+		return &tg.InputMessagesFilterEmpty{}
+	}
+}
+
+// PinMessage pins or unpins a message without notifying members. The RPC
+// result carries the pin update, which is applied directly.
+func (c *GotdClient) PinMessage(ctx context.Context, chatID int64, msgID int, pin bool) error {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	result, err := c.api.MessagesUpdatePinnedMessage(ctx, &tg.MessagesUpdatePinnedMessageRequest{
+		Silent: true,
+		Unpin:  !pin,
+		Peer:   peer,
+		ID:     msgID,
+	})
+	if err != nil {
+		return fmt.Errorf("update pinned message: %w", err)
+	}
+
+	updates, _, _ := unpackUpdates(result)
+	for _, upd := range updates {
+		c.handlePinned(upd)
+	}
+	return nil
+}
+
+// SendFile uploads a file from disk and sends it as a document, with an
+// optional caption. The sent message's text names the file, since media
+// has no text of its own.
+func (c *GotdClient) SendFile(ctx context.Context, chatID int64, path, caption string) (domain.Message, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return domain.Message{}, fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	file, err := uploader.NewUploader(c.api).FromPath(ctx, path)
+	if err != nil {
+		return domain.Message{}, fmt.Errorf("upload %s: %w", filepath.Base(path), err)
+	}
+	var captionOpts []styling.StyledTextOption
+	if caption != "" {
+		captionOpts = append(captionOpts, styling.Plain(caption))
+	}
+	doc := message.UploadedDocument(file, captionOpts...).
+		Filename(filepath.Base(path)).
+		ForceFile(true)
+	upd, err := c.sender.To(peer).Clear().Media(ctx, doc)
+	if err != nil {
+		return domain.Message{}, fmt.Errorf("send file: %w", err)
+	}
+
+	msg := domain.Message{
+		ChatID:    chatID,
+		Text:      fileLabel(filepath.Base(path), caption),
+		Timestamp: time.Now(),
+		Out:       true,
+	}
+	if c.self != nil {
+		msg.SenderID = c.self.ID
+		msg.SenderName = formatUserName(c.self)
+	}
+	if u, ok := upd.(*tg.Updates); ok {
+		for _, update := range u.Updates {
+			var mc tg.MessageClass
+			switch nm := update.(type) {
+			case *tg.UpdateNewMessage:
+				mc = nm.Message
+			case *tg.UpdateNewChannelMessage:
+				mc = nm.Message
+			}
+			if m, ok := mc.(*tg.Message); ok {
+				msg.ID = m.ID
+				msg.Timestamp = time.Unix(int64(m.Date), 0)
+			}
+		}
+	}
+	return msg, nil
+}
+
+// fileLabel describes a sent file in place of the media it carries.
+func fileLabel(name, caption string) string {
+	label := "📎 " + name
+	if caption != "" {
+		label += "\n" + caption
+	}
+	return label
+}
+
+// JoinChat joins a group or channel by invite link ("t.me/+hash",
+// "t.me/joinchat/hash") or public username ("@name", "t.me/name") and
+// returns it. A username that belongs to a user is returned without
+// joining, so it can be opened as a private chat.
+func (c *GotdClient) JoinChat(ctx context.Context, target string) (domain.ChatInfo, error) {
+	hash, username := parseJoinTarget(target)
+	switch {
+	case hash != "":
+		result, err := c.api.MessagesImportChatInvite(ctx, hash)
+		if err != nil {
+			return domain.ChatInfo{}, fmt.Errorf("import chat invite: %w", err)
+		}
+		u, ok := result.(*tg.Updates)
+		if !ok || len(u.Chats) == 0 {
+			return domain.ChatInfo{}, fmt.Errorf("import chat invite: no chat in result")
+		}
+		c.cacheEntities(u.Users, u.Chats)
+		return c.joinedChat(u.Chats[0].GetID())
+	case username != "":
+		resolved, err := c.api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: username})
+		if err != nil {
+			return domain.ChatInfo{}, fmt.Errorf("resolve @%s: %w", username, err)
+		}
+		c.cacheEntities(resolved.Users, resolved.Chats)
+		id := peerIDFromPeer(resolved.Peer)
+		if p, ok := c.findPeer(id).(*tg.InputPeerChannel); ok {
+			channel := &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash}
+			if _, err := c.api.ChannelsJoinChannel(ctx, channel); err != nil {
+				return domain.ChatInfo{}, fmt.Errorf("join @%s: %w", username, err)
+			}
+		}
+		return c.joinedChat(id)
+	}
+	return domain.ChatInfo{}, fmt.Errorf("not an invite link or username: %q", target)
+}
+
+// joinedChat describes a chat whose entity was just cached.
+func (c *GotdClient) joinedChat(id int64) (domain.ChatInfo, error) {
+	peer := c.findPeer(id)
+	if peer == nil {
+		return domain.ChatInfo{}, fmt.Errorf("unknown peer: %d", id)
+	}
+	return domain.ChatInfo{ID: id, Title: c.peerTitle(id), Peer: peer}, nil
+}
+
+// parseJoinTarget splits a join target into an invite hash or a public
+// username; at most one is set.
+func parseJoinTarget(target string) (hash, username string) {
+	s := strings.TrimSpace(target)
+	if rest, ok := strings.CutPrefix(s, "tg://join?invite="); ok {
+		return rest, ""
+	}
+	if rest, ok := strings.CutPrefix(s, "tg://resolve?domain="); ok {
+		name, _, _ := strings.Cut(rest, "&")
+		return "", name
+	}
+	for _, prefix := range []string{"https://", "http://"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	for _, host := range []string{"t.me/", "telegram.me/", "telegram.dog/"} {
+		if rest, ok := strings.CutPrefix(s, host); ok {
+			rest, _, _ = strings.Cut(rest, "?")
+			rest = strings.TrimSuffix(rest, "/")
+			if h, ok := strings.CutPrefix(rest, "+"); ok {
+				return h, ""
+			}
+			if h, ok := strings.CutPrefix(rest, "joinchat/"); ok {
+				return h, ""
+			}
+			// Links to a message, like t.me/name/123, name the chat first.
+			name, _, _ := strings.Cut(rest, "/")
+			return "", name
+		}
+	}
+	if name, ok := strings.CutPrefix(s, "@"); ok && name != "" && !strings.ContainsAny(name, " /") {
+		return "", name
+	}
+	return "", ""
+}
+
+// SetName changes our first and last name.
+func (c *GotdClient) SetName(ctx context.Context, first, last string) error {
+	req := &tg.AccountUpdateProfileRequest{}
+	req.SetFirstName(first)
+	req.SetLastName(last)
+	result, err := c.api.AccountUpdateProfile(ctx, req)
+	if err != nil {
+		return fmt.Errorf("update profile: %w", err)
+	}
+	if u, ok := result.(*tg.User); ok {
+		c.self = u
+	}
+	return nil
+}
+
+// GetBotCommands returns the commands of the bots in a chat: the bot
+// itself in a private chat, or the bots that are members of a group.
+// Chats without bots return nil.
+func (c *GotdClient) GetBotCommands(ctx context.Context, chatID int64) ([]domain.BotCommand, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return nil, fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	var infos []tg.BotInfo
+	var users []tg.UserClass
+	switch p := peer.(type) {
+	case *tg.InputPeerUser:
+		result, err := c.api.UsersGetFullUser(ctx, &tg.InputUser{UserID: p.UserID, AccessHash: p.AccessHash})
+		if err != nil {
+			return nil, fmt.Errorf("get full user: %w", err)
+		}
+		if info, ok := result.FullUser.GetBotInfo(); ok {
+			infos = append(infos, info)
+		}
+	case *tg.InputPeerChat:
+		result, err := c.api.MessagesGetFullChat(ctx, p.ChatID)
+		if err != nil {
+			return nil, fmt.Errorf("get full chat: %w", err)
+		}
+		if full, ok := result.FullChat.(*tg.ChatFull); ok {
+			infos, _ = full.GetBotInfo()
+		}
+		users = result.Users
+	case *tg.InputPeerChannel:
+		result, err := c.api.ChannelsGetFullChannel(ctx, &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash})
+		if err != nil {
+			return nil, fmt.Errorf("get full channel: %w", err)
+		}
+		if full, ok := result.FullChat.(*tg.ChannelFull); ok {
+			infos = full.GetBotInfo()
+		}
+		users = result.Users
+	default:
+		return nil, fmt.Errorf("unsupported peer type for bot commands: %T", peer)
+	}
+	return botCommands(infos, usersToMap(users)), nil
+}
+
+// botCommands flattens the command lists of a chat's bots. With several
+// bots in a group, commands are addressed as "cmd@botname" so the right
+// bot answers.
+func botCommands(infos []tg.BotInfo, users map[int64]*tg.User) []domain.BotCommand {
+	var out []domain.BotCommand
+	for _, info := range infos {
+		suffix := ""
+		if u, ok := users[info.UserID]; ok && len(infos) > 1 && u.Username != "" {
+			suffix = "@" + u.Username
+		}
+		for _, cmd := range info.Commands {
+			out = append(out, domain.BotCommand{
+				Command:     cmd.Command + suffix,
+				Description: cmd.Description,
+			})
+		}
+	}
+	return out
+}
+
+// PressButton sends a callback button's data to the bot that sent the
+// message and returns its answer. Bots that don't answer in time fail
+// with BOT_RESPONSE_TIMEOUT.
+func (c *GotdClient) PressButton(ctx context.Context, chatID int64, msgID int, data []byte) (CallbackAnswer, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return CallbackAnswer{}, fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	req := &tg.MessagesGetBotCallbackAnswerRequest{Peer: peer, MsgID: msgID}
+	req.SetData(data)
+	answer, err := c.api.MessagesGetBotCallbackAnswer(ctx, req)
+	if err != nil {
+		return CallbackAnswer{}, fmt.Errorf("get bot callback answer: %w", err)
+	}
+	return CallbackAnswer{Message: answer.Message, Alert: answer.Alert, URL: answer.URL}, nil
+}
+
+// GetInlineResults asks an inline bot for results to a query typed in a chat.
+func (c *GotdClient) GetInlineResults(ctx context.Context, chatID int64, bot, query, offset string) (InlineResults, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return InlineResults{}, fmt.Errorf("unknown peer: %d", chatID)
+	}
+	user, placeholder, err := c.resolveInlineBot(ctx, bot)
+	if err != nil {
+		return InlineResults{}, err
+	}
+
+	res, err := c.api.MessagesGetInlineBotResults(ctx, &tg.MessagesGetInlineBotResultsRequest{
+		Bot:    user,
+		Peer:   peer,
+		Query:  query,
+		Offset: offset,
+	})
+	if err != nil {
+		return InlineResults{}, fmt.Errorf("get inline bot results: %w", err)
+	}
+	out := InlineResults{QueryID: res.QueryID, NextOffset: res.NextOffset, Placeholder: placeholder}
+	for _, r := range res.Results {
+		out.Results = append(out.Results, convertInlineResult(r))
+	}
+	return out, nil
+}
+
+// resolveInlineBot looks up an inline bot by username, returning it with
+// its placeholder. Lookups are cached, including misses, since queries
+// are made as the user types.
+func (c *GotdClient) resolveInlineBot(ctx context.Context, username string) (*tg.InputUser, string, error) {
+	key := strings.ToLower(username)
+	c.mu.Lock()
+	bot, ok := c.inlineBots[key]
+	c.mu.Unlock()
+	if !ok {
+		resolved, err := c.api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: username})
+		switch {
+		case tgerr.Is(err, "USERNAME_NOT_OCCUPIED", "USERNAME_INVALID"):
+		case err != nil:
+			return nil, "", fmt.Errorf("resolve @%s: %w", username, err)
+		default:
+			c.cacheEntities(resolved.Users, resolved.Chats)
+			bot = inlineBot(resolved.Users, peerIDFromPeer(resolved.Peer))
+		}
+		c.cacheInlineBot(key, bot)
+	}
+	if bot == nil {
+		return nil, "", ErrNotInlineBot
+	}
+	placeholder, _ := bot.GetBotInlinePlaceholder()
+	return &tg.InputUser{UserID: bot.ID, AccessHash: bot.AccessHash}, placeholder, nil
+}
+
+// maxCachedInlineBots bounds the inline bot cache. Misses are cached too,
+// and every prefix of a mistyped username is one, so the oldest lookups
+// are evicted first.
+const maxCachedInlineBots = 100
+
+// cacheInlineBot records a username lookup, evicting the oldest once the
+// cache is full.
+func (c *GotdClient) cacheInlineBot(key string, bot *tg.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, seen := c.inlineBots[key]; !seen {
+		c.inlineBotOrder = append(c.inlineBotOrder, key)
+		if len(c.inlineBotOrder) > maxCachedInlineBots {
+			delete(c.inlineBots, c.inlineBotOrder[0])
+			c.inlineBotOrder = c.inlineBotOrder[1:]
+		}
+	}
+	c.inlineBots[key] = bot
+}
+
+// inlineBot returns the user with the given ID if it's a bot that accepts
+// inline queries, or nil.
+func inlineBot(users []tg.UserClass, id int64) *tg.User {
+	for _, u := range users {
+		user, ok := u.(*tg.User)
+		if !ok || user.ID != id {
+			continue
+		}
+		if _, inline := user.GetBotInlinePlaceholder(); user.Bot && inline {
+			return user
+		}
+	}
+	return nil
+}
+
+// convertInlineResult converts an inline bot result, keeping what's
+// needed to list and send it.
+func convertInlineResult(r tg.BotInlineResultClass) domain.InlineResult {
+	out := domain.InlineResult{ID: r.GetID(), Type: r.GetType()}
+	out.Title, _ = r.GetTitle()
+	out.Description, _ = r.GetDescription()
+	switch m := r.GetSendMessage().(type) {
+	case *tg.BotInlineMessageText:
+		out.Text = m.Message
+	case *tg.BotInlineMessageMediaAuto:
+		out.Text = m.Message
+	}
+	return out
+}
+
+// SendInlineResult sends one of an inline query's results to a chat.
+func (c *GotdClient) SendInlineResult(ctx context.Context, chatID, queryID int64, resultID string) (domain.Message, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return domain.Message{}, fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	result, err := c.api.MessagesSendInlineBotResult(ctx, &tg.MessagesSendInlineBotResultRequest{
+		Peer:       peer,
+		RandomID:   rand.Int64(),
+		QueryID:    queryID,
+		ID:         resultID,
+		ClearDraft: true,
+	})
+	if err != nil {
+		return domain.Message{}, fmt.Errorf("send inline bot result: %w", err)
+	}
+
+	msg, ok := c.sentMessage(result)
+	if !ok {
+		return domain.Message{}, fmt.Errorf("send inline bot result: no message in result")
+	}
+	return msg, nil
+}
+
+// sentMessage returns the new message in the result of sending one.
+func (c *GotdClient) sentMessage(result tg.UpdatesClass) (domain.Message, bool) {
+	updates, users, _ := unpackUpdates(result)
+	byID := usersToMap(users)
+	for _, upd := range updates {
+		var mc tg.MessageClass
+		switch nm := upd.(type) {
+		case *tg.UpdateNewMessage:
+			mc = nm.Message
+		case *tg.UpdateNewChannelMessage:
+			mc = nm.Message
+		default:
+			continue
+		}
+		if msg, ok := mc.(*tg.Message); ok {
+			return c.convertMessage(msg, byID), true
+		}
+	}
+	return domain.Message{}, false
+}
+
+// Vote sends the chosen options and returns the poll with its new results.
+func (c *GotdClient) Vote(ctx context.Context, chatID int64, msgID int, options [][]byte) (domain.Poll, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return domain.Poll{}, fmt.Errorf("unknown peer: %d", chatID)
+	}
+
+	result, err := c.api.MessagesSendVote(ctx, &tg.MessagesSendVoteRequest{
+		Peer:    peer,
+		MsgID:   msgID,
+		Options: options,
+	})
+	if err != nil {
+		return domain.Poll{}, fmt.Errorf("send vote: %w", err)
+	}
+	updates, _, _ := unpackUpdates(result)
+	for _, upd := range updates {
+		mp, ok := upd.(*tg.UpdateMessagePoll)
+		if !ok {
+			continue
+		}
+		var poll *tg.Poll
+		if p, ok := mp.GetPoll(); ok {
+			poll = &p
+		}
+		if p, ok := c.updatePoll(mp.PollID, poll, mp.Results); ok {
+			return p, nil
+		}
+	}
+	return domain.Poll{}, fmt.Errorf("send vote: no poll in result")
+}
+
+// SendPoll sends a poll with p's question, options and settings.
+func (c *GotdClient) SendPoll(ctx context.Context, chatID int64, p domain.Poll) (domain.Message, error) {
+	peer := c.findPeer(chatID)
+	if peer == nil {
+		return domain.Message{}, fmt.Errorf("unknown peer: %d", chatID)
+	}
+	if len(p.Options) < 2 {
+		return domain.Message{}, fmt.Errorf("a poll needs at least 2 options")
+	}
+
+	answers := make([]message.PollAnswerOption, len(p.Options))
+	for i, o := range p.Options {
+		answers[i] = message.PollAnswer(o.Text)
+	}
+	poll := message.Poll(p.Question, answers[0], answers[1], answers[2:]...).
+		MultipleChoice(p.Multiple).
+		PublicVoters(p.Public)
+	result, err := c.sender.To(peer).Clear().Media(ctx, poll)
+	if err != nil {
+		return domain.Message{}, fmt.Errorf("send poll: %w", err)
+	}
+	msg, ok := c.sentMessage(result)
+	if !ok {
+		return domain.Message{}, fmt.Errorf("send poll: no message in result")
+	}
+	return msg, nil
+}
+
+// maxCachedPolls bounds the poll cache. The oldest polls seen are evicted
+// first; results for an evicted poll are then dropped like those for one
+// never seen, until the poll itself arrives again with its message.
+const maxCachedPolls = 500
+
+// updatePoll merges a poll and its latest results into the cached copy
+// and returns it. poll is nil when an update carries only results; those
+// for a poll we haven't seen are dropped.
+func (c *GotdClient) updatePoll(id int64, poll *tg.Poll, results tg.PollResults) (domain.Poll, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev, seen := c.polls[id]
+	p := prev
+	switch {
+	case poll != nil:
+		p = convertPoll(*poll)
+	case !seen:
+		return domain.Poll{}, false
+	}
+	p = applyPollResults(p, results, prev)
+	if !seen {
+		c.pollOrder = append(c.pollOrder, id)
+		if len(c.pollOrder) > maxCachedPolls {
+			delete(c.polls, c.pollOrder[0])
+			c.pollOrder = c.pollOrder[1:]
+		}
+	}
+	c.polls[id] = p
+	return p, true
+}
+
+// convertPoll converts a poll's question, options and settings.
+func convertPoll(poll tg.Poll) domain.Poll {
+	p := domain.Poll{
+		ID:       poll.ID,
+		Question: poll.Question.Text,
+		Closed:   poll.Closed,
+		Multiple: poll.MultipleChoice,
+		Quiz:     poll.Quiz,
+		Public:   poll.PublicVoters,
+	}
+	for _, a := range poll.Answers {
+		p.Options = append(p.Options, domain.PollOption{Text: a.Text.Text, Option: a.Option})
+	}
+	return p
+}
+
+// applyPollResults sets a poll's vote counts from results. Min results
+// leave out which options we chose, so those carry over from prev, as do
+// counts when results leave them out.
+func applyPollResults(p domain.Poll, results tg.PollResults, prev domain.Poll) domain.Poll {
+	p.Options = append([]domain.PollOption(nil), p.Options...)
+	index := make(map[string]int, len(p.Options))
+	for i, o := range p.Options {
+		index[string(o.Option)] = i
+	}
+	for i := range p.Options {
+		p.Options[i].Voters, p.Options[i].Chosen, p.Options[i].Correct = 0, false, false
+	}
+	for _, o := range prev.Options {
+		if i, ok := index[string(o.Option)]; ok {
+			p.Options[i].Voters = o.Voters
+			if results.Min {
+				p.Options[i].Chosen, p.Options[i].Correct = o.Chosen, o.Correct
+			}
+		}
+	}
+	p.HasResults, p.Voters = prev.HasResults, prev.Voters
+
+	voters, ok := results.GetResults()
+	if !ok && !results.Min {
+		// Full results without counts: we haven't voted, or retracted.
+		p.HasResults = false
+	}
+	if ok {
+		p.HasResults = true
+		for _, v := range voters {
+			i, ok := index[string(v.Option)]
+			if !ok {
+				continue
+			}
+			p.Options[i].Voters = v.Voters
+			if !results.Min {
+				p.Options[i].Chosen, p.Options[i].Correct = v.Chosen, v.Correct
+			}
+		}
+	}
+	if total, ok := results.GetTotalVoters(); ok {
+		p.Voters = total
+	}
+	return p
+}
+
+// pollLabel describes a poll in place of message text, for the chat list
+// and search.
+func pollLabel(p domain.Poll) string {
+	return "📊 " + p.Question
+}
+
+// handlePinned forwards a pin or unpin update to the handler. Other
+// update types are ignored.
+func (c *GotdClient) handlePinned(update tg.UpdateClass) {
+	switch u := update.(type) {
+	case *tg.UpdatePinnedMessages:
+		if chatID := peerIDFromPeer(u.Peer); chatID != 0 {
+			c.handler.OnPinnedMessages(chatID, u.Messages, u.Pinned)
+		}
+	case *tg.UpdatePinnedChannelMessages:
+		c.handler.OnPinnedMessages(u.ChannelID, u.Messages, u.Pinned)
+	}
+}
+
+// findPeer looks up a cached peer by chat ID.
+func (c *GotdClient) findPeer(chatID int64) tg.InputPeerClass {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peerCache[chatID]
+}
+
+// cachePeer stores a peer in the cache.
+func (c *GotdClient) cachePeer(chatID int64, peer tg.InputPeerClass) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.peerCache[chatID] = peer
+}
+
+// cacheUserName stores a user's display name for later lookup (e.g. typing indicators).
+func (c *GotdClient) cacheUserName(userID int64, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nameCache[userID] = name
+}
+
+// cacheInputUser remembers how to address a user in mention entities.
+func (c *GotdClient) cacheInputUser(u *tg.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.userCache[u.ID] = &tg.InputUser{UserID: u.ID, AccessHash: u.AccessHash}
+}
+
+// findInputUser looks up a cached InputUser, or nil if the user is unknown.
+func (c *GotdClient) findInputUser(userID int64) *tg.InputUser {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.userCache[userID]
+}
+
+// cacheChatTitles remembers group and channel titles, which name the
+// origin of forwarded messages.
+func (c *GotdClient) cacheChatTitles(chats []tg.ChatClass) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ch := range chats {
+		switch ch := ch.(type) {
+		case *tg.Chat:
+			c.chatCache[ch.ID] = ch.Title
+		case *tg.Channel:
+			c.chatCache[ch.ID] = ch.Title
+		}
+	}
+}
+
+// cacheEntities remembers the users and chats attached to an RPC result:
+// their names, and how to address them so they can be opened as chats.
+// Min constructors carry no usable access hash and are only named.
+func (c *GotdClient) cacheEntities(users []tg.UserClass, chats []tg.ChatClass) {
+	c.cacheChatTitles(chats)
+	for _, u := range users {
+		user, ok := u.(*tg.User)
+		if !ok {
+			continue
+		}
+		c.cacheUserName(user.ID, formatUserName(user))
+		if !user.Min {
+			c.cachePeer(user.ID, &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash})
+		}
+	}
+	for _, ch := range chats {
+		switch ch := ch.(type) {
+		case *tg.Chat:
+			c.cachePeer(ch.ID, &tg.InputPeerChat{ChatID: ch.ID})
+		case *tg.Channel:
+			if !ch.Min {
+				c.cachePeer(ch.ID, &tg.InputPeerChannel{ChannelID: ch.ID, AccessHash: ch.AccessHash})
+			}
+		}
+	}
+}
+
+// cacheEntityTitles remembers the chat titles attached to an update.
+func (c *GotdClient) cacheEntityTitles(e tg.Entities) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, ch := range e.Chats {
+		c.chatCache[id] = ch.Title
+	}
+	for id, ch := range e.Channels {
+		c.chatCache[id] = ch.Title
+	}
+}
+
+// findChatTitle looks up a cached group or channel title.
+func (c *GotdClient) findChatTitle(chatID int64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.chatCache[chatID]
+}
+
+// findUserName looks up a cached user display name.
+func (c *GotdClient) findUserName(userID int64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nameCache[userID]
+}
+
+// convertMessage converts a tg.Message to a domain.Message.
+func (c *GotdClient) convertMessage(msg *tg.Message, users map[int64]*tg.User) domain.Message {
+	chatID, senderID, senderName := c.messagePeers(msg.FromID, msg.PeerID, msg.Out, users)
+
+	// Convert Telegram entities to markdown-formatted text.
+	text := msg.Message
+	hasMarkdown := false
+	if entities, ok := msg.GetEntities(); ok && len(entities) > 0 {
+		text = EntitiesToMarkdown(text, entities)
+		hasMarkdown = text != msg.Message
+	}
+
+	out := domain.Message{
+		ID:          msg.ID,
+		ChatID:      chatID,
+		SenderName:  senderName,
+		SenderID:    senderID,
+		Text:        text,
+		HasMarkdown: hasMarkdown,
+		Timestamp:   time.Unix(int64(msg.Date), 0),
+		Out:         msg.Out,
+		Mentioned:   msg.Mentioned,
+		Reactions:   convertReactions(msg.Reactions),
+
+		ForwardedFrom: c.forwardedFrom(msg, users),
+	}
+	if media, ok := msg.Media.(*tg.MessageMediaPoll); ok {
+		p, _ := c.updatePoll(media.Poll.ID, &media.Poll, media.Results)
+		out.Poll = &p
+		if out.Text == "" {
+			out.Text = pollLabel(p)
+		}
+	}
+	switch markup := msg.ReplyMarkup.(type) {
+	case *tg.ReplyInlineMarkup:
+		out.Buttons = convertButtons(markup.Rows)
+	case *tg.ReplyKeyboardMarkup:
+		out.ReplyKeyboard = &domain.ReplyKeyboard{
+			Rows:      convertButtons(markup.Rows),
+			SingleUse: markup.SingleUse,
+		}
+	case *tg.ReplyKeyboardHide:
+		out.ReplyKeyboard = &domain.ReplyKeyboard{Hide: true}
+	}
+	return out
+}
+
+// messagePeers resolves the chat a message belongs to and who sent it.
+func (c *GotdClient) messagePeers(fromID, peerID tg.PeerClass, out bool, users map[int64]*tg.User) (chatID, senderID int64, senderName string) {
+	if fromID != nil {
+		switch p := fromID.(type) {
+		case *tg.PeerUser:
+			senderID = p.UserID
+			if u, ok := users[p.UserID]; ok {
+				senderName = formatUserName(u)
+			}
+		case *tg.PeerChat:
+			senderID = p.ChatID
+		case *tg.PeerChannel:
+			senderID = p.ChannelID
+		}
+	}
+
+	// Determine chatID from PeerID.
+	if peerID != nil {
+		switch p := peerID.(type) {
+		case *tg.PeerUser:
+			chatID = p.UserID
+		case *tg.PeerChat:
+			chatID = p.ChatID
+		case *tg.PeerChannel:
+			chatID = p.ChannelID
+		}
+	}
+
+	// In DMs, FromID is often nil. Derive sender from PeerID and Out flag.
+	if senderName == "" && !out {
+		if p, ok := peerID.(*tg.PeerUser); ok {
+			senderID = p.UserID
+			if u, ok := users[p.UserID]; ok {
+				senderName = formatUserName(u)
+			}
+		}
+	}
+	if senderName == "" && out && c.self != nil {
+		senderID = c.self.ID
+		senderName = formatUserName(c.self)
+	}
+
+	// Cache the resolved name for typing indicator lookups.
+	if senderID != 0 && senderName != "" {
+		c.cacheUserName(senderID, senderName)
+	}
+	return chatID, senderID, senderName
+}
+
+// convertMessageClass converts a message or a service message. It reports
+// false for empty messages and for events that aren't shown.
+func (c *GotdClient) convertMessageClass(mc tg.MessageClass, users map[int64]*tg.User) (domain.Message, bool) {
+	switch msg := mc.(type) {
+	case *tg.Message:
+		return c.convertMessage(msg, users), true
+	case *tg.MessageService:
+		return c.convertService(msg, users)
+	}
+	return domain.Message{}, false
+}
+
+// convertService converts a service message, which records an event such
+// as a member joining, into a message describing the event. It reports
+// false for events that aren't shown.
+func (c *GotdClient) convertService(msg *tg.MessageService, users map[int64]*tg.User) (domain.Message, bool) {
+	chatID, senderID, senderName := c.messagePeers(msg.FromID, msg.PeerID, msg.Out, users)
+
+	// Channels post events as themselves, without a sender.
+	actor := senderName
+	switch {
+	case msg.Out:
+		actor = "You"
+	case actor == "":
+		actor = c.findChatTitle(chatID)
+	}
+	if actor == "" {
+		actor = "Someone"
+	}
+
+	text := c.serviceText(msg.Action, senderID, actor, msg.Out, users)
+	if text == "" {
+		return domain.Message{}, false
+	}
+	return domain.Message{
+		ID:         msg.ID,
+		ChatID:     chatID,
+		SenderName: senderName,
+		SenderID:   senderID,
+		Text:       text,
+		Timestamp:  time.Unix(int64(msg.Date), 0),
+		Out:        msg.Out,
+		Mentioned:  msg.Mentioned,
+		Kind:       domain.MessageService,
+		Event:      serviceEvent(msg.Action, msg.Out),
+	}, true
+}
+
+// serviceEvent classifies a service message's action.
+func serviceEvent(action tg.MessageActionClass, out bool) domain.ServiceEvent {
+	switch a := action.(type) {
+	case *tg.MessageActionPhoneCall:
+		if _, missed := a.Reason.(*tg.PhoneCallDiscardReasonMissed); missed && !out {
+			return domain.EventMissedCall
+		}
+	case *tg.MessageActionGroupCall:
+		if a.Duration == 0 {
+			return domain.EventVideoChatStarted
+		}
+	case *tg.MessageActionPinMessage:
+		return domain.EventPin
+	}
+	return domain.EventOther
+}
+
+// serviceText describes a service message's action, done by actor, or
+// returns "" for actions that aren't shown, such as payments and gifts.
+func (c *GotdClient) serviceText(action tg.MessageActionClass, actorID int64, actor string, out bool, users map[int64]*tg.User) string {
+	switch a := action.(type) {
+	case *tg.MessageActionChatCreate:
+		return fmt.Sprintf("%s created the group «%s»", actor, a.Title)
+	case *tg.MessageActionChannelCreate:
+		return fmt.Sprintf("Channel «%s» created", a.Title)
+	case *tg.MessageActionChatEditTitle:
+		return fmt.Sprintf("%s changed the name to «%s»", actor, a.Title)
+	case *tg.MessageActionChatEditPhoto:
+		return actor + " changed the photo"
+	case *tg.MessageActionChatDeletePhoto:
+		return actor + " removed the photo"
+	case *tg.MessageActionChatAddUser:
+		if len(a.Users) == 1 && a.Users[0] == actorID {
+			return actor + " joined"
+		}
+		return actor + " added " + c.userNames(a.Users, users)
+	case *tg.MessageActionChatDeleteUser:
+		if a.UserID == actorID {
+			return actor + " left"
+		}
+		return actor + " removed " + c.userNames([]int64{a.UserID}, users)
+	case *tg.MessageActionChatJoinedByLink:
+		return actor + " joined via an invite link"
+	case *tg.MessageActionChatJoinedByRequest:
+		return actor + " was accepted into the chat"
+	case *tg.MessageActionChatMigrateTo, *tg.MessageActionChannelMigrateFrom:
+		return "The group was upgraded to a supergroup"
+	case *tg.MessageActionPinMessage:
+		return actor + " pinned a message"
+	case *tg.MessageActionHistoryClear:
+		return "History was cleared"
+	case *tg.MessageActionScreenshotTaken:
+		return actor + " took a screenshot"
+	case *tg.MessageActionContactSignUp:
+		return actor + " joined Telegram"
+	case *tg.MessageActionCustomAction:
+		return a.Message
+	case *tg.MessageActionPhoneCall:
+		return callText(a, out)
+	case *tg.MessageActionGroupCall:
+		if a.Duration > 0 {
+			return fmt.Sprintf("Video chat ended (%s)", durationLabel(a.Duration))
+		}
+		return actor + " started a video chat"
+	case *tg.MessageActionInviteToGroupCall:
+		return actor + " invited " + c.userNames(a.Users, users) + " to the video chat"
+	case *tg.MessageActionSetMessagesTTL:
+		if a.Period == 0 {
+			return actor + " turned off auto-delete"
+		}
+		return fmt.Sprintf("%s set messages to auto-delete after %s", actor, periodLabel(a.Period))
+	case *tg.MessageActionTopicCreate:
+		return fmt.Sprintf("%s created the topic «%s»", actor, a.Title)
+	}
+	return ""
+}
+
+// userNames lists users named in a service message, falling back to the
+// name cache for users the message didn't include.
+func (c *GotdClient) userNames(ids []int64, users map[int64]*tg.User) string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name := c.findUserName(id)
+		if u, ok := users[id]; ok {
+			name = formatUserName(u)
+		}
+		if name == "" {
+			name = "someone"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// callText describes a call from our side of it, like the call log of
+// other Telegram apps.
+func callText(a *tg.MessageActionPhoneCall, out bool) string {
+	kind := "call"
+	if a.Video {
+		kind = "video call"
+	}
+	var text string
+	switch a.Reason.(type) {
+	case *tg.PhoneCallDiscardReasonMissed:
+		text = "Missed " + kind
+		if out {
+			text = "Cancelled " + kind
+		}
+	case *tg.PhoneCallDiscardReasonBusy:
+		text = "Declined " + kind
+	default:
+		text = "Incoming " + kind
+		if out {
+			text = "Outgoing " + kind
+		}
+	}
+	if a.Duration > 0 {
+		text += " (" + durationLabel(a.Duration) + ")"
+	}
+	return text
+}
+
+// durationLabel formats a call's length in seconds, e.g. "5m 3s".
+func durationLabel(seconds int) string {
+	switch {
+	case seconds < 60:
+		return fmt.Sprintf("%ds", seconds)
+	case seconds < 3600:
+		return fmt.Sprintf("%dm %ds", seconds/60, seconds%60)
+	}
+	return fmt.Sprintf("%dh %dm", seconds/3600, seconds%3600/60)
+}
+
+// periodLabel formats an auto-delete period in seconds, e.g. "1 week".
+func periodLabel(seconds int) string {
+	const day = 24 * 60 * 60
+	n, unit := seconds/60, "minute"
+	switch {
+	case seconds >= 30*day:
+		n, unit = seconds/(30*day), "month"
+	case seconds%(7*day) == 0:
+		n, unit = seconds/(7*day), "week"
+	case seconds >= day:
+		n, unit = seconds/day, "day"
+	case seconds >= 60*60:
+		n, unit = seconds/(60*60), "hour"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
+
+// convertButtons converts keyboard rows. Buttons that can't be pressed
+// here are kept, marked unsupported, so the keyboard keeps its shape.
+func convertButtons(rows []tg.KeyboardButtonRow) [][]domain.Button {
+	out := make([][]domain.Button, 0, len(rows))
+	for _, row := range rows {
+		buttons := make([]domain.Button, 0, len(row.Buttons))
+		for _, b := range row.Buttons {
+			btn := domain.Button{Text: b.GetText(), Kind: domain.ButtonUnsupported}
+			switch b := b.(type) {
+			case *tg.KeyboardButton:
+				btn.Kind = domain.ButtonText
+			case *tg.KeyboardButtonCallback:
+				// Callbacks that ask for our 2FA password aren't supported.
+				if !b.RequiresPassword {
+					btn.Kind = domain.ButtonCallback
+					btn.Data = b.Data
+				}
+			case *tg.KeyboardButtonURL:
+				btn.Kind = domain.ButtonURL
+				btn.URL = b.URL
+			case *tg.KeyboardButtonCopy:
+				btn.Kind = domain.ButtonCopy
+				btn.Data = []byte(b.CopyText)
+			}
+			buttons = append(buttons, btn)
+		}
+		out = append(out, buttons)
+	}
+	return out
+}
+
+// forwardedFrom names the origin of a forwarded message. Users who hide
+// their account in forwards only leave a name; unknown origins fall back
+// to "Unknown".
+func (c *GotdClient) forwardedFrom(msg *tg.Message, users map[int64]*tg.User) string {
+	fwd, ok := msg.GetFwdFrom()
+	if !ok {
+		return ""
+	}
+	if name, ok := fwd.GetFromName(); ok && name != "" {
+		return name
+	}
+	var name string
+	switch p := fwd.FromID.(type) {
+	case *tg.PeerUser:
+		if u, ok := users[p.UserID]; ok {
+			name = formatUserName(u)
+		} else {
+			name = c.findUserName(p.UserID)
+		}
+	case *tg.PeerChat:
+		name = c.findChatTitle(p.ChatID)
+	case *tg.PeerChannel:
+		name = c.findChatTitle(p.ChannelID)
+	}
+	if author, ok := fwd.GetPostAuthor(); ok && author != "" && name != "" {
+		name += " (" + author + ")"
+	}
+	if name == "" {
+		return "Unknown"
+	}
+	return name
+}
+
+// customEmojiPlaceholder stands in for custom emoji reactions, which are
+// stickers we can't draw in a terminal.
+const customEmojiPlaceholder = "✦"
+
+// convertReactions converts a message's reaction tallies.
+func convertReactions(r tg.MessageReactions) []domain.Reaction {
+	out := make([]domain.Reaction, 0, len(r.Results))
+	for _, rc := range r.Results {
+		var emoji string
+		switch re := rc.Reaction.(type) {
+		case *tg.ReactionEmoji:
+			emoji = re.Emoticon
+		case *tg.ReactionCustomEmoji:
+			emoji = customEmojiPlaceholder
+		default:
+			continue
+		}
+		_, chosen := rc.GetChosenOrder()
+		out = append(out, domain.Reaction{Emoji: emoji, Count: rc.Count, Chosen: chosen})
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// handleReactions forwards a reaction update to the handler.
+func (c *GotdClient) handleReactions(update *tg.UpdateMessageReactions) {
+	chatID := peerIDFromPeer(update.Peer)
+	if chatID == 0 {
+		return
+	}
+	c.handler.OnMessageReactions(chatID, update.MsgID, convertReactions(update.Reactions))
+}
+
+// convertHistoryResult extracts messages from a MessagesMessagesClass response.
+func (c *GotdClient) convertHistoryResult(result tg.MessagesMessagesClass) ([]domain.Message, error) {
+	var messages []tg.MessageClass
+	var users []tg.UserClass
+	var chats []tg.ChatClass
+
+	switch r := result.(type) {
+	case *tg.MessagesMessages:
+		messages = r.Messages
+		users = r.Users
+		chats = r.Chats
+	case *tg.MessagesMessagesSlice:
+		messages = r.Messages
+		users = r.Users
+		chats = r.Chats
+	case *tg.MessagesChannelMessages:
+		messages = r.Messages
+		users = r.Users
+		chats = r.Chats
+	default:
+		return nil, fmt.Errorf("unexpected messages type: %T", result)
+	}
+
+	userMap := usersToMap(users)
+	c.cacheEntities(users, chats)
+
+	// Messages come in reverse chronological order from the API; reverse them.
+	var domainMsgs []domain.Message
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg, ok := c.convertMessageClass(messages[i], userMap)
+		if !ok {
+			continue
+		}
+		domainMsgs = append(domainMsgs, msg)
+	}
+
+	return domainMsgs, nil
+}
+
+// titleFromEntities extracts the chat title from dialog entities.
+func (c *GotdClient) titleFromEntities(elem dialogs.Elem) string {
+	if elem.Peer == nil {
+		return "Unknown"
+	}
+
+	entities := elem.Entities
+
+	switch p := elem.Dialog.GetPeer().(type) {
+	case *tg.PeerUser:
+		if u, ok := entities.User(p.UserID); ok {
+			name := formatUserName(u)
+			c.cacheUserName(p.UserID, name)
+			return name
+		}
+	case *tg.PeerChat:
+		if ch, ok := entities.Chat(p.ChatID); ok {
+			c.cacheChatTitles([]tg.ChatClass{ch})
+			return ch.Title
+		}
+	case *tg.PeerChannel:
+		if ch, ok := entities.Channel(p.ChannelID); ok {
+			c.cacheChatTitles([]tg.ChatClass{ch})
+			return ch.Title
+		}
+	}
+
+	return "Unknown"
+}
+
+// usernameFromEntities returns the public username of a dialog's user or
+// channel, if it has one.
+func usernameFromEntities(elem dialogs.Elem) string {
+	switch p := elem.Dialog.GetPeer().(type) {
+	case *tg.PeerUser:
+		if u, ok := elem.Entities.User(p.UserID); ok {
+			return u.Username
+		}
+	case *tg.PeerChannel:
+		if ch, ok := elem.Entities.Channel(p.ChannelID); ok {
+			return ch.Username
+		}
+	}
+	return ""
+}
+
+// peerIDFromInputPeer extracts a numeric peer ID from an InputPeerClass.
+func (c *GotdClient) peerIDFromInputPeer(peer tg.InputPeerClass) int64 {
+	switch p := peer.(type) {
+	case *tg.InputPeerUser:
+		return p.UserID
+	case *tg.InputPeerChat:
+		return p.ChatID
+	case *tg.InputPeerChannel:
+		return p.ChannelID
+	default:
+		return 0
+	}
+}
+
+// peerIDFromPeer extracts a numeric peer ID from a PeerClass.
+func peerIDFromPeer(peer tg.PeerClass) int64 {
+	switch p := peer.(type) {
+	case *tg.PeerUser:
+		return p.UserID
+	case *tg.PeerChat:
+		return p.ChatID
+	case *tg.PeerChannel:
+		return p.ChannelID
+	default:
+		return 0
+	}
+}
+
+// muteUntilTime converts a Telegram mute_until Unix timestamp to a time,
+// returning the zero time when the chat is not muted.
+func muteUntilTime(muteUntil int) time.Time {
+	if muteUntil <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(muteUntil), 0)
+}
+
+// GetSelfName returns the logged-in user's display name.
+func (c *GotdClient) GetSelfName() string {
+	if c.self != nil {
+		return formatUserName(c.self)
 	}
 	return ""
 }
@@ -521,6 +2137,21 @@ func formatUserName(u *tg.User) string {
 	return "Unknown"
 }
 
+// unpackUpdates returns the updates, users and chats in a method's result,
+// whichever form the server sent it in. The short message forms and
+// updatesTooLong carry no update objects, so they return none.
+func unpackUpdates(result tg.UpdatesClass) ([]tg.UpdateClass, []tg.UserClass, []tg.ChatClass) {
+	switch u := result.(type) {
+	case *tg.Updates:
+		return u.Updates, u.Users, u.Chats
+	case *tg.UpdatesCombined:
+		return u.Updates, u.Users, u.Chats
+	case *tg.UpdateShort:
+		return []tg.UpdateClass{u.Update}, nil, nil
+	}
+	return nil, nil, nil
+}
+
 // usersToMap converts a UserClass slice to a map of User by ID.
 func usersToMap(users []tg.UserClass) map[int64]*tg.User {
 	m := make(map[int64]*tg.User, len(users))
@@ -0,0 +1,388 @@
+package telegram
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+func newTestClient() *GotdClient {
+	return NewGotdClient(0, "", "", nil, nil, nil)
+}
+
+func forwarded(fwd tg.MessageFwdHeader) *tg.Message {
+	msg := &tg.Message{}
+	msg.SetFwdFrom(fwd)
+	return msg
+}
+
+func TestForwardedFrom_NotForwarded(t *testing.T) {
+	c := newTestClient()
+	if got := c.forwardedFrom(&tg.Message{}, nil); got != "" {
+		t.Errorf("expected empty origin, got %q", got)
+	}
+}
+
+func TestForwardedFrom_User(t *testing.T) {
+	c := newTestClient()
+	users := map[int64]*tg.User{7: {ID: 7, FirstName: "Ada", LastName: "Lovelace"}}
+	msg := forwarded(tg.MessageFwdHeader{FromID: &tg.PeerUser{UserID: 7}})
+	if got := c.forwardedFrom(msg, users); got != "Ada Lovelace" {
+		t.Errorf("expected %q, got %q", "Ada Lovelace", got)
+	}
+}
+
+func TestForwardedFrom_HiddenUser(t *testing.T) {
+	c := newTestClient()
+	fwd := tg.MessageFwdHeader{}
+	fwd.SetFromName("Anonymous Ada")
+	if got := c.forwardedFrom(forwarded(fwd), nil); got != "Anonymous Ada" {
+		t.Errorf("expected %q, got %q", "Anonymous Ada", got)
+	}
+}
+
+func TestForwardedFrom_ChannelWithAuthor(t *testing.T) {
+	c := newTestClient()
+	c.cacheChatTitles([]tg.ChatClass{&tg.Channel{ID: 42, Title: "Announcements"}})
+	fwd := tg.MessageFwdHeader{FromID: &tg.PeerChannel{ChannelID: 42}}
+	fwd.SetPostAuthor("Grace")
+	if got := c.forwardedFrom(forwarded(fwd), nil); got != "Announcements (Grace)" {
+		t.Errorf("expected %q, got %q", "Announcements (Grace)", got)
+	}
+}
+
+func TestForwardedFrom_UnknownOrigin(t *testing.T) {
+	c := newTestClient()
+	msg := forwarded(tg.MessageFwdHeader{FromID: &tg.PeerChannel{ChannelID: 99}})
+	if got := c.forwardedFrom(msg, nil); got != "Unknown" {
+		t.Errorf("expected %q, got %q", "Unknown", got)
+	}
+}
+
+func TestDraftText(t *testing.T) {
+	if got := draftText(&tg.DraftMessage{Message: "half-written"}); got != "half-written" {
+		t.Errorf("expected %q, got %q", "half-written", got)
+	}
+	if got := draftText(&tg.DraftMessageEmpty{}); got != "" {
+		t.Errorf("expected empty draft, got %q", got)
+	}
+}
+
+func TestParseJoinTarget(t *testing.T) {
+	tests := []struct {
+		target, hash, username string
+	}{
+		{"https://t.me/+AbCdEf123", "AbCdEf123", ""},
+		{"t.me/joinchat/AbCdEf123", "AbCdEf123", ""},
+		{"tg://join?invite=AbCdEf123", "AbCdEf123", ""},
+		{"@gophers", "", "gophers"},
+		{"https://t.me/gophers", "", "gophers"},
+		{"t.me/gophers/1234", "", "gophers"},
+		{"tg://resolve?domain=gophers&start=x", "", "gophers"},
+		{"gophers", "", ""},
+		{"@", "", ""},
+	}
+	for _, tt := range tests {
+		hash, username := parseJoinTarget(tt.target)
+		if hash != tt.hash || username != tt.username {
+			t.Errorf("parseJoinTarget(%q) = %q, %q; want %q, %q", tt.target, hash, username, tt.hash, tt.username)
+		}
+	}
+}
+
+func TestBotCommands(t *testing.T) {
+	deploy := tg.BotCommand{Command: "deploy", Description: "Ship it"}
+	one := []tg.BotInfo{{UserID: 1, Commands: []tg.BotCommand{deploy}}}
+	if got := botCommands(one, nil); len(got) != 1 || got[0].Command != "deploy" || got[0].Description != "Ship it" {
+		t.Errorf("single bot: got %+v", got)
+	}
+
+	two := append(one, tg.BotInfo{UserID: 2, Commands: []tg.BotCommand{{Command: "status"}}})
+	users := map[int64]*tg.User{1: {ID: 1, Username: "shipbot"}, 2: {ID: 2, Username: "statusbot"}}
+	got := botCommands(two, users)
+	if len(got) != 2 || got[0].Command != "deploy@shipbot" || got[1].Command != "status@statusbot" {
+		t.Errorf("two bots: got %+v", got)
+	}
+}
+
+func TestConvertMessage_Keyboards(t *testing.T) {
+	c := newTestClient()
+
+	inline := &tg.Message{ID: 1, PeerID: &tg.PeerUser{UserID: 5}}
+	inline.SetReplyMarkup(&tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{
+		{Buttons: []tg.KeyboardButtonClass{
+			&tg.KeyboardButtonCallback{Text: "Approve", Data: []byte("ok")},
+			&tg.KeyboardButtonURL{Text: "Logs", URL: "https://example.com/logs"},
+		}},
+		{Buttons: []tg.KeyboardButtonClass{&tg.KeyboardButtonGame{Text: "Play"}}},
+	}})
+	msg := c.convertMessage(inline, nil)
+	if len(msg.Buttons) != 2 || len(msg.Buttons[0]) != 2 {
+		t.Fatalf("Buttons = %+v, want rows of 2 and 1", msg.Buttons)
+	}
+	if b := msg.Buttons[0][0]; b.Kind != domain.ButtonCallback || string(b.Data) != "ok" {
+		t.Errorf("callback button = %+v", b)
+	}
+	if b := msg.Buttons[0][1]; b.Kind != domain.ButtonURL || b.URL != "https://example.com/logs" {
+		t.Errorf("URL button = %+v", b)
+	}
+	if b := msg.Buttons[1][0]; b.Kind != domain.ButtonUnsupported || b.Text != "Play" {
+		t.Errorf("game button = %+v", b)
+	}
+
+	reply := &tg.Message{ID: 2, PeerID: &tg.PeerUser{UserID: 5}}
+	reply.SetReplyMarkup(&tg.ReplyKeyboardMarkup{SingleUse: true, Rows: []tg.KeyboardButtonRow{
+		{Buttons: []tg.KeyboardButtonClass{&tg.KeyboardButton{Text: "Yes"}}},
+	}})
+	msg = c.convertMessage(reply, nil)
+	if kb := msg.ReplyKeyboard; kb == nil || !kb.SingleUse || kb.Rows[0][0].Kind != domain.ButtonText {
+		t.Errorf("ReplyKeyboard = %+v", kb)
+	}
+
+	hide := &tg.Message{ID: 3, PeerID: &tg.PeerUser{UserID: 5}}
+	hide.SetReplyMarkup(&tg.ReplyKeyboardHide{})
+	if kb := c.convertMessage(hide, nil).ReplyKeyboard; kb == nil || !kb.Hide {
+		t.Errorf("hide ReplyKeyboard = %+v", kb)
+	}
+}
+
+func TestInlineBot(t *testing.T) {
+	gif := &tg.User{ID: 1, Bot: true}
+	gif.SetBotInlinePlaceholder("Search GIFs…")
+	plain := &tg.User{ID: 2, Bot: true}
+	person := &tg.User{ID: 3}
+	users := []tg.UserClass{gif, plain, person}
+
+	if got := inlineBot(users, 1); got != gif {
+		t.Errorf("inline bot: got %+v", got)
+	}
+	for _, id := range []int64{2, 3, 4} {
+		if got := inlineBot(users, id); got != nil {
+			t.Errorf("user %d: got %+v, want nil", id, got)
+		}
+	}
+}
+
+func TestConvertInlineResult(t *testing.T) {
+	article := &tg.BotInlineResult{ID: "a1", Type: "article", SendMessage: &tg.BotInlineMessageText{Message: "hello"}}
+	article.SetTitle("Greeting")
+	got := convertInlineResult(article)
+	want := domain.InlineResult{ID: "a1", Type: "article", Title: "Greeting", Text: "hello"}
+	if got != want {
+		t.Errorf("article = %+v, want %+v", got, want)
+	}
+
+	gif := &tg.BotInlineMediaResult{ID: "g1", Type: "gif", SendMessage: &tg.BotInlineMessageMediaAuto{}}
+	gif.SetDescription("cat.gif")
+	got = convertInlineResult(gif)
+	if got.ID != "g1" || got.Type != "gif" || got.Description != "cat.gif" || got.Title != "" {
+		t.Errorf("gif = %+v", got)
+	}
+}
+
+func TestConvertMessage_Poll(t *testing.T) {
+	c := newTestClient()
+	poll := tg.Poll{
+		ID:       42,
+		Question: tg.TextWithEntities{Text: "Lunch?"},
+		Answers: []tg.PollAnswer{
+			{Text: tg.TextWithEntities{Text: "Pizza"}, Option: []byte("0")},
+			{Text: tg.TextWithEntities{Text: "Salad"}, Option: []byte("1")},
+		},
+	}
+	msg := &tg.Message{ID: 1, PeerID: &tg.PeerUser{UserID: 5}, Media: &tg.MessageMediaPoll{Poll: poll}}
+	got := c.convertMessage(msg, nil)
+	if got.Poll == nil || got.Poll.Question != "Lunch?" || len(got.Poll.Options) != 2 {
+		t.Fatalf("Poll = %+v", got.Poll)
+	}
+	if got.Text != "📊 Lunch?" {
+		t.Errorf("Text = %q, want the poll label", got.Text)
+	}
+	if got.Poll.HasResults || got.Poll.Voted() {
+		t.Errorf("unvoted poll = %+v, want no results", got.Poll)
+	}
+
+	// Our vote arrives with full results.
+	var voted tg.PollResults
+	voted.SetResults([]tg.PollAnswerVoters{{Option: []byte("0"), Voters: 3, Chosen: true}, {Option: []byte("1"), Voters: 1}})
+	voted.SetTotalVoters(4)
+	p, ok := c.updatePoll(42, nil, voted)
+	if !ok || !p.HasResults || p.Voters != 4 || !p.Options[0].Chosen || p.Options[0].Voters != 3 {
+		t.Fatalf("after voting = %+v", p)
+	}
+
+	// Min results from others' votes keep our choice.
+	others := tg.PollResults{Min: true}
+	others.SetResults([]tg.PollAnswerVoters{{Option: []byte("0"), Voters: 3}, {Option: []byte("1"), Voters: 2}})
+	others.SetTotalVoters(5)
+	p, _ = c.updatePoll(42, nil, others)
+	if !p.Options[0].Chosen || p.Options[1].Voters != 2 || p.Voters != 5 {
+		t.Errorf("after min results = %+v", p)
+	}
+
+	// Retracting the vote hides the counts again.
+	var retracted tg.PollResults
+	retracted.SetTotalVoters(4)
+	p, _ = c.updatePoll(42, nil, retracted)
+	if p.HasResults || p.Voted() {
+		t.Errorf("after retracting = %+v", p)
+	}
+
+	if _, ok := c.updatePoll(99, nil, voted); ok {
+		t.Error("results for an unseen poll were applied")
+	}
+}
+
+func TestUpdatePoll_Evicts(t *testing.T) {
+	c := newTestClient()
+	for id := int64(1); id <= maxCachedPolls+1; id++ {
+		c.updatePoll(id, &tg.Poll{ID: id}, tg.PollResults{})
+	}
+	if len(c.polls) != maxCachedPolls || len(c.pollOrder) != maxCachedPolls {
+		t.Fatalf("cached %d polls (%d in order), want %d", len(c.polls), len(c.pollOrder), maxCachedPolls)
+	}
+	if _, ok := c.updatePoll(1, nil, tg.PollResults{}); ok {
+		t.Error("results for the evicted oldest poll were applied")
+	}
+	if _, ok := c.updatePoll(2, nil, tg.PollResults{}); !ok {
+		t.Error("results for a cached poll were dropped")
+	}
+}
+
+func TestConvertService(t *testing.T) {
+	c := newTestClient()
+	users := map[int64]*tg.User{
+		7: {ID: 7, FirstName: "Ann"},
+		8: {ID: 8, FirstName: "Bob"},
+	}
+	service := func(from int64, action tg.MessageActionClass) tg.MessageClass {
+		return &tg.MessageService{ID: 1, FromID: &tg.PeerUser{UserID: from}, PeerID: &tg.PeerChat{ChatID: 3}, Action: action}
+	}
+
+	tests := []struct {
+		name  string
+		msg   tg.MessageClass
+		want  string
+		event domain.ServiceEvent
+	}{
+		{"join", service(7, &tg.MessageActionChatAddUser{Users: []int64{7}}), "Ann joined", domain.EventOther},
+		{"add", service(7, &tg.MessageActionChatAddUser{Users: []int64{8}}), "Ann added Bob", domain.EventOther},
+		{"leave", service(8, &tg.MessageActionChatDeleteUser{UserID: 8}), "Bob left", domain.EventOther},
+		{"title", service(7, &tg.MessageActionChatEditTitle{Title: "Lunch"}), "Ann changed the name to «Lunch»", domain.EventOther},
+		{"pin", service(7, &tg.MessageActionPinMessage{}), "Ann pinned a message", domain.EventPin},
+		{"missed call", service(7, &tg.MessageActionPhoneCall{Reason: &tg.PhoneCallDiscardReasonMissed{}}), "Missed call", domain.EventMissedCall},
+		{"call", service(7, &tg.MessageActionPhoneCall{Video: true, Duration: 65}), "Incoming video call (1m 5s)", domain.EventOther},
+		{"video chat", service(7, &tg.MessageActionGroupCall{}), "Ann started a video chat", domain.EventVideoChatStarted},
+		{"auto-delete", service(7, &tg.MessageActionSetMessagesTTL{Period: 7 * 24 * 60 * 60}), "Ann set messages to auto-delete after 1 week", domain.EventOther},
+	}
+	for _, tt := range tests {
+		msg, ok := c.convertMessageClass(tt.msg, users)
+		if !ok {
+			t.Errorf("%s: not converted", tt.name)
+			continue
+		}
+		if msg.Kind != domain.MessageService || msg.Text != tt.want || msg.ChatID != 3 {
+			t.Errorf("%s: got %+v, want service text %q", tt.name, msg, tt.want)
+		}
+		if msg.Event != tt.event {
+			t.Errorf("%s: event = %d, want %d", tt.name, msg.Event, tt.event)
+		}
+	}
+
+	if _, ok := c.convertMessageClass(service(7, &tg.MessageActionPaymentSent{}), users); ok {
+		t.Error("payment service message was converted")
+	}
+	if _, ok := c.convertMessageClass(&tg.MessageEmpty{ID: 2}, users); ok {
+		t.Error("empty message was converted")
+	}
+}
+
+func TestUnpackUpdates(t *testing.T) {
+	upd := &tg.UpdateMessageReactions{MsgID: 5}
+	users := []tg.UserClass{&tg.User{ID: 7}}
+	chats := []tg.ChatClass{&tg.Chat{ID: 3}}
+
+	tests := []struct {
+		name        string
+		result      tg.UpdatesClass
+		wantUpdates int
+		wantUsers   int
+		wantChats   int
+	}{
+		{"updates", &tg.Updates{Updates: []tg.UpdateClass{upd}, Users: users, Chats: chats}, 1, 1, 1},
+		{"combined", &tg.UpdatesCombined{Updates: []tg.UpdateClass{upd}, Users: users, Chats: chats}, 1, 1, 1},
+		{"short", &tg.UpdateShort{Update: upd}, 1, 0, 0},
+		{"short sent", &tg.UpdateShortSentMessage{ID: 5}, 0, 0, 0},
+		{"too long", &tg.UpdatesTooLong{}, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		gotUpdates, gotUsers, gotChats := unpackUpdates(tt.result)
+		if len(gotUpdates) != tt.wantUpdates || len(gotUsers) != tt.wantUsers || len(gotChats) != tt.wantChats {
+			t.Errorf("%s: got %d updates, %d users, %d chats; want %d, %d, %d", tt.name,
+				len(gotUpdates), len(gotUsers), len(gotChats), tt.wantUpdates, tt.wantUsers, tt.wantChats)
+		}
+		if tt.wantUpdates > 0 && gotUpdates[0] != upd {
+			t.Errorf("%s: update = %v", tt.name, gotUpdates[0])
+		}
+	}
+}
+
+func TestForwardedMessages(t *testing.T) {
+	c := newTestClient()
+	fwd := &tg.Message{ID: 9, PeerID: &tg.PeerChat{ChatID: 3}, Message: "release notes"}
+	users := []tg.UserClass{&tg.User{ID: 7, FirstName: "Ann"}}
+
+	for _, result := range []tg.UpdatesClass{
+		&tg.Updates{Updates: []tg.UpdateClass{&tg.UpdateNewMessage{Message: fwd}}, Users: users},
+		&tg.UpdatesCombined{Updates: []tg.UpdateClass{&tg.UpdateNewMessage{Message: fwd}}, Users: users},
+		&tg.UpdateShort{Update: &tg.UpdateNewMessage{Message: fwd}},
+	} {
+		msgs := c.forwardedMessages(result)
+		if len(msgs) != 1 || msgs[0].ID != 9 || msgs[0].Text != "release notes" {
+			t.Errorf("%s: messages = %+v", result.TypeName(), msgs)
+		}
+	}
+}
+
+func TestSentMessage(t *testing.T) {
+	c := newTestClient()
+	sent := &tg.Message{ID: 12, PeerID: &tg.PeerUser{UserID: 7}, Message: "gif"}
+	users := []tg.UserClass{&tg.User{ID: 7, FirstName: "Ann"}}
+
+	for _, result := range []tg.UpdatesClass{
+		&tg.Updates{Updates: []tg.UpdateClass{&tg.UpdateNewMessage{Message: sent}}, Users: users},
+		&tg.UpdatesCombined{Updates: []tg.UpdateClass{&tg.UpdateNewMessage{Message: sent}}, Users: users},
+		&tg.UpdateShort{Update: &tg.UpdateNewMessage{Message: sent}},
+	} {
+		msg, ok := c.sentMessage(result)
+		if !ok || msg.ID != 12 || msg.Text != "gif" {
+			t.Errorf("%s: message = %+v, %v", result.TypeName(), msg, ok)
+		}
+	}
+	if _, ok := c.sentMessage(&tg.UpdatesTooLong{}); ok {
+		t.Error("found a message in updatesTooLong")
+	}
+}
+
+func TestCacheInlineBot_Evicts(t *testing.T) {
+	c := newTestClient()
+	for i := 0; i <= maxCachedInlineBots; i++ {
+		c.cacheInlineBot(fmt.Sprintf("bot%d", i), nil)
+	}
+	if len(c.inlineBots) != maxCachedInlineBots || len(c.inlineBotOrder) != maxCachedInlineBots {
+		t.Fatalf("cached %d bots (%d in order), want %d", len(c.inlineBots), len(c.inlineBotOrder), maxCachedInlineBots)
+	}
+	if _, ok := c.inlineBots["bot0"]; ok {
+		t.Error("the oldest lookup wasn't evicted")
+	}
+
+	// Looking a cached name up again doesn't add it twice.
+	gif := &tg.User{ID: 1, Bot: true}
+	c.cacheInlineBot("bot1", gif)
+	if len(c.inlineBotOrder) != maxCachedInlineBots || c.inlineBots["bot1"] != gif {
+		t.Errorf("re-caching bot1: %d in order, bot = %v", len(c.inlineBotOrder), c.inlineBots["bot1"])
+	}
+}
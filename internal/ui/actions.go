@@ -0,0 +1,449 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// actionScope limits an action to the pane that has focus.
+type actionScope int
+
+const (
+	scopeAny actionScope = iota
+	scopeChatList
+	scopeMessages
+	scopeInput
+)
+
+// allows reports whether the scope covers the focused pane.
+func (s actionScope) allows(f focusTarget) bool {
+	switch s {
+	case scopeChatList:
+		return f == focusChatList
+	case scopeMessages:
+		return f == focusMessages
+	case scopeInput:
+		return f == focusInput
+	}
+	return true
+}
+
+// Help sections.
+const (
+	groupGeneral  = "General"
+	groupChatList = "Chat List"
+	groupMessages = "Messages"
+	groupInput    = "Input"
+)
+
+// action is a named operation: it can be bound to keys, is listed in the
+// help overlay, and runs from the command palette. Actions without run
+// only document keys a pane handles itself, such as Enter in the input.
+type action struct {
+	name  string   // stable identifier, e.g. "toggle-sidebar"
+	title string   // shown in the help and the palette
+	group string   // help section
+	keys  []string // bindings in tea.KeyMsg.String() form
+	scope actionScope
+	when  func(m Model) bool // optional extra precondition
+	run   func(m Model) (Model, tea.Cmd)
+}
+
+// available reports whether the action can run in the model's state.
+func (a action) available(m Model) bool {
+	return a.run != nil && a.scope.allows(m.focus) && (a.when == nil || a.when(m))
+}
+
+// hasActiveChat is a precondition for actions on the open chat.
+func hasActiveChat(m Model) bool {
+	return m.store.GetActiveChat() != 0
+}
+
+// hasButtons reports whether the selected message has an inline keyboard.
+func hasButtons(m Model) bool {
+	msg, ok := m.messageView.SelectedMessage()
+	return ok && len(msg.Buttons) > 0
+}
+
+// notService reports whether the selected message, if any, is one the
+// user can act on. Service messages such as "Ana joined the group" can't
+// be reacted to, forwarded or pinned.
+func notService(m Model) bool {
+	msg, ok := m.messageView.SelectedMessage()
+	return !ok || msg.Kind != domain.MessageService
+}
+
+// hasOpenPoll reports whether the selected message has a poll we can
+// vote in. A quiz takes only one answer.
+func hasOpenPoll(m Model) bool {
+	msg, ok := m.messageView.SelectedMessage()
+	return ok && msg.Poll != nil && !msg.Poll.Closed && !(msg.Poll.Quiz && msg.Poll.Voted())
+}
+
+// defaultActions returns the registry of every action, in help order.
+func defaultActions() []action {
+	return []action{
+		// General
+		{name: "quit", title: "Quit", group: groupGeneral, keys: []string{"ctrl+c", "q"},
+			run: func(m Model) (Model, tea.Cmd) { return m.quit() }},
+		{name: "help", title: "Toggle this help", group: groupGeneral, keys: []string{"f1", "h"},
+			run: func(m Model) (Model, tea.Cmd) {
+				m.help = m.help.Toggle()
+				return m, nil
+			}},
+		{name: "command-palette", title: "Command palette", group: groupGeneral, keys: []string{":", "alt+x"},
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.palette, cmd = m.palette.Show(m.paletteEntries())
+				return m, cmd
+			}},
+		{name: "focus-next", title: "Next pane", group: groupGeneral, keys: []string{"tab"},
+			run: func(m Model) (Model, tea.Cmd) { return m.cycleFocus(1), nil }},
+		{name: "focus-prev", title: "Previous pane", group: groupGeneral, keys: []string{"shift+tab"},
+			run: func(m Model) (Model, tea.Cmd) { return m.cycleFocus(-1), nil }},
+		{name: "focus-chat-list", title: "Back to chat list", group: groupGeneral, keys: []string{"esc"},
+			run: func(m Model) (Model, tea.Cmd) {
+				if m.chatListVisible {
+					m.focus = focusChatList
+				} else {
+					m.focus = focusMessages
+				}
+				return m.updateFocus(), nil
+			}},
+		{name: "toggle-sidebar", title: "Toggle sidebar", group: groupGeneral, keys: []string{"ctrl+b"},
+			run: func(m Model) (Model, tea.Cmd) {
+				m.chatListVisible = !m.chatListVisible
+				if !m.chatListVisible && m.focus == focusChatList {
+					m.focus = focusMessages
+					m = m.updateFocus()
+				}
+				return m.distributeSize(), nil
+			}},
+		{name: "shrink-sidebar", title: "Shrink sidebar", group: groupGeneral, keys: []string{"["},
+			when: func(m Model) bool { return m.chatListVisible },
+			run:  func(m Model) (Model, tea.Cmd) { return m.resizeSplit(-splitStep), nil }},
+		{name: "grow-sidebar", title: "Expand sidebar", group: groupGeneral, keys: []string{"]"},
+			when: func(m Model) bool { return m.chatListVisible },
+			run:  func(m Model) (Model, tea.Cmd) { return m.resizeSplit(splitStep), nil }},
+		{name: "global-search", title: "Search all chats", group: groupGeneral, keys: []string{"s"},
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.global, cmd = m.global.Show()
+				return m, cmd
+			}},
+		{name: "switch-chat", title: "Switch chat", group: groupGeneral, keys: []string{"ctrl+k"},
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.switcher, cmd = m.switcher.Show(m.store.GetChatList(), m.frecency)
+				return m, cmd
+			}},
+
+		// Chat list
+		{name: "navigate-chats", title: "Navigate chats", group: groupChatList, keys: []string{"j", "k", "up", "down"}, scope: scopeChatList},
+		{name: "open-chat", title: "Open chat", group: groupChatList, keys: []string{"enter"}, scope: scopeChatList},
+		{name: "filter-chats", title: "Filter chats", group: groupChatList, keys: []string{"/"}, scope: scopeChatList},
+		{name: "mute", title: "Mute / unmute chat", group: groupChatList, keys: []string{"m"},
+			run: func(m Model) (Model, tea.Cmd) { return m.showMuteMenu(), nil }},
+		{name: "mark-read", title: "Mark chat as read", group: groupChatList,
+			run: func(m Model) (Model, tea.Cmd) { return m.markRead() }},
+
+		// Messages
+		{name: "scroll-down", title: "Scroll down", group: groupMessages, keys: []string{"j"}, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ScrollBy(1)
+				return m, cmd
+			}},
+		{name: "scroll-up", title: "Scroll up", group: groupMessages, keys: []string{"k"}, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ScrollBy(-1)
+				return m, cmd
+			}},
+		{name: "page-up", title: "Page up", group: groupMessages, keys: []string{"pgup"},
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ScrollPage(-1)
+				return m, cmd
+			}},
+		{name: "page-down", title: "Page down", group: groupMessages, keys: []string{"pgdown"},
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ScrollPage(1)
+				return m, cmd
+			}},
+		{name: "half-page-up", title: "Half page up", group: groupMessages, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ScrollHalfPage(-1)
+				return m, cmd
+			}},
+		{name: "half-page-down", title: "Half page down", group: groupMessages, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ScrollHalfPage(1)
+				return m, cmd
+			}},
+		{name: "scroll-top", title: "Oldest loaded message", group: groupMessages, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ScrollToEnd(-1)
+				return m, cmd
+			}},
+		{name: "scroll-bottom", title: "Newest message", group: groupMessages, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ScrollToEnd(1)
+				return m, cmd
+			}},
+		{name: "select-prev", title: "Select previous message", group: groupMessages, keys: []string{"up"}, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.Select(-1)
+				return m, cmd
+			}},
+		{name: "select-next", title: "Select next message", group: groupMessages, keys: []string{"down"}, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.Select(1)
+				return m, cmd
+			}},
+		{name: "react", title: "React to selected message", group: groupMessages, keys: []string{"r"}, scope: scopeMessages,
+			when: notService,
+			run:  func(m Model) (Model, tea.Cmd) { return m.showReactions() }},
+		{name: "press-button", title: "Press a button on selected message", group: groupMessages, keys: []string{"enter"}, scope: scopeMessages,
+			when: hasButtons,
+			run: func(m Model) (Model, tea.Cmd) {
+				m.messageView = m.messageView.FocusButtons()
+				return m, nil
+			}},
+		{name: "vote", title: "Vote in selected poll", group: groupMessages, keys: []string{"v"}, scope: scopeMessages,
+			when: hasOpenPoll,
+			run: func(m Model) (Model, tea.Cmd) {
+				m.messageView = m.messageView.FocusPoll()
+				return m, nil
+			}},
+		{name: "forward", title: "Forward selected message", group: groupMessages, keys: []string{"f"}, scope: scopeMessages,
+			when: notService,
+			run:  func(m Model) (Model, tea.Cmd) { return m.showForward(), nil }},
+		{name: "jump-to-pin", title: "Jump to pin / next pin", group: groupMessages, keys: []string{"p"}, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) { return m.jumpToPin() }},
+		{name: "toggle-pin", title: "Pin / unpin selected message", group: groupMessages, keys: []string{"P"}, scope: scopeMessages,
+			when: notService,
+			run:  func(m Model) (Model, tea.Cmd) { return m.togglePin() }},
+		{name: "jump-to-message", title: "Jump to message ID or date", group: groupMessages, keys: []string{"g"}, scope: scopeMessages,
+			when: hasActiveChat,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.jumpPrompt, cmd = m.jumpPrompt.Show()
+				return m, cmd
+			}},
+		{name: "search-chat", title: "Search this chat", group: groupMessages, keys: []string{"/"}, scope: scopeMessages,
+			when: hasActiveChat,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.search, cmd = m.search.Show()
+				return m, cmd
+			}},
+		{name: "next-search-hit", title: "Next search hit", group: groupMessages, keys: []string{"n"}, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) { return m.stepSearch(1) }},
+		{name: "prev-search-hit", title: "Previous search hit", group: groupMessages, keys: []string{"N"}, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) { return m.stepSearch(-1) }},
+		{name: "next-mention", title: "Jump to next unread mention", group: groupMessages, keys: []string{"@"},
+			run: func(m Model) (Model, tea.Cmd) { return m.jumpToMention() }},
+		{name: "toggle-bubbles", title: "Toggle speech bubbles", group: groupMessages, keys: []string{"b"}, scope: scopeMessages,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.messageView, cmd = m.messageView.ToggleBubbles()
+				return m, cmd
+			}},
+
+		// Input
+		{name: "send", title: "Send message", group: groupInput, keys: []string{"enter"}, scope: scopeInput},
+		{name: "newline", title: "New line", group: groupInput, keys: []string{"shift+enter", "alt+enter"}, scope: scopeInput},
+		{name: "history-prev", title: "Previous message sent to this chat", group: groupInput, keys: []string{"ctrl+p"}, scope: scopeInput,
+			run: func(m Model) (Model, tea.Cmd) {
+				m.input = m.input.HistoryPrev()
+				return m.syncInputHeight(), nil
+			}},
+		{name: "history-next", title: "Next message sent to this chat", group: groupInput, keys: []string{"ctrl+n"}, scope: scopeInput,
+			run: func(m Model) (Model, tea.Cmd) {
+				m.input = m.input.HistoryNext()
+				return m.syncInputHeight(), nil
+			}},
+		{name: "history-search", title: "Search messages sent to any chat", group: groupInput, keys: []string{"ctrl+r"}, scope: scopeInput,
+			run: func(m Model) (Model, tea.Cmd) {
+				m.input = m.input.SearchHistory()
+				return m.syncInputHeight(), nil
+			}},
+		{name: "external-editor", title: "Compose in $EDITOR, send on exit", group: groupInput, keys: []string{"ctrl+x"}, scope: scopeInput,
+			run: func(m Model) (Model, tea.Cmd) { return m, m.input.OpenEditor() }},
+		{name: "mention", title: "Mention (Tab/Enter to pick)", group: groupInput, keys: []string{"@name"}, scope: scopeInput},
+		{name: "inline-query", title: "Inline bot query (↑/↓ and Enter to send a result)", group: groupInput, keys: []string{"@bot query"}, scope: scopeInput},
+		{name: "emoji-shortcode", title: "Emoji shortcode (:code: expands)", group: groupInput, keys: []string{":code"}, scope: scopeInput},
+		{name: "quick-reply", title: "Choose a bot's quick reply", group: groupInput, keys: []string{"alt+k"}, scope: scopeInput,
+			when: func(m Model) bool { return m.input.HasQuickReplies() },
+			run: func(m Model) (Model, tea.Cmd) {
+				m.input = m.input.FocusQuickReplies()
+				return m, nil
+			}},
+		{name: "emoji-picker", title: "Emoji picker", group: groupInput, keys: []string{"ctrl+o"}, scope: scopeInput,
+			run: func(m Model) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.emojiPicker, cmd = m.emojiPicker.Show(m.cfg.RecentEmoji)
+				return m, cmd
+			}},
+	}
+}
+
+// printableKey reports whether a key types a character, so that it
+// belongs to the input while the input has focus.
+func printableKey(key string) bool {
+	return utf8.RuneCountInString(key) == 1 || key == "space"
+}
+
+// actionForKey finds the action bound to a key press in the model's
+// current state.
+func (m Model) actionForKey(key string) (action, bool) {
+	if m.focus == focusInput && printableKey(key) {
+		return action{}, false
+	}
+	for _, a := range m.actions {
+		if !a.available(m) {
+			continue
+		}
+		for _, k := range a.keys {
+			if k == key {
+				return a, true
+			}
+		}
+	}
+	return action{}, false
+}
+
+// runAction runs the named action if it's available.
+func (m Model) runAction(name string) (Model, tea.Cmd) {
+	for _, a := range m.actions {
+		if a.name == name && a.available(m) {
+			return a.run(m)
+		}
+	}
+	return m, nil
+}
+
+// paletteEntries lists the actions available in the model's current
+// state for the command palette.
+func (m Model) paletteEntries() []paletteEntry {
+	var entries []paletteEntry
+	for _, a := range m.actions {
+		if a.name == "command-palette" || !a.available(m) {
+			continue
+		}
+		entries = append(entries, paletteEntry{name: a.name, title: a.title, keys: keysLabel(a.keys)})
+	}
+	return entries
+}
+
+// keyNames spells out keys whose tea.KeyMsg form isn't how people write
+// them.
+var keyNames = map[string]string{
+	"up":     "↑",
+	"down":   "↓",
+	"left":   "←",
+	"right":  "→",
+	"pgup":   "PgUp",
+	"pgdown": "PgDn",
+	"home":   "Home",
+	"end":    "End",
+	"enter":  "Enter",
+	"esc":    "Esc",
+	"tab":    "Tab",
+	"space":  "Space",
+}
+
+// keyLabel formats a key for display, e.g. "ctrl+b" as "Ctrl+B".
+func keyLabel(key string) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	if utf8.RuneCountInString(key) == 1 {
+		return key
+	}
+	parts := strings.Split(key, "+")
+	for i, p := range parts {
+		if name, ok := keyNames[p]; ok {
+			parts[i] = name
+		} else if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// keysLabel formats a binding list for display.
+func keysLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = keyLabel(k)
+	}
+	return strings.Join(labels, " / ")
+}
+
+// cycleFocus moves focus to the next (delta 1) or previous (delta -1)
+// pane, skipping the chat list while it's hidden.
+func (m Model) cycleFocus(delta int) Model {
+	if m.chatListVisible {
+		m.focus = (m.focus + focusTarget(3+delta)) % 3
+	} else if m.focus == focusMessages {
+		m.focus = focusInput
+	} else {
+		m.focus = focusMessages
+	}
+	return m.updateFocus()
+}
+
+// resizeSplit widens (positive) or narrows the chat list pane.
+func (m Model) resizeSplit(delta int) Model {
+	m.splitPos += delta
+	m = m.clampSplitPos()
+	return m.distributeSize()
+}
+
+// stepSearch jumps to the next (delta 1) or previous search hit,
+// highlighting the search terms even if the overlay was closed without
+// opening a hit.
+func (m Model) stepSearch(delta int) (Model, tea.Cmd) {
+	var hit domain.Message
+	var ok bool
+	if m.search, hit, ok = m.search.Step(delta); ok {
+		m.messageView = m.messageView.SetHighlight(m.search.Terms()...)
+		return m.jumpTo(jumpMsg{id: hit.ID})
+	}
+	return m, nil
+}
+
+// markRead marks every message in the chat under the cursor, or the open
+// chat from other panes, as read.
+func (m Model) markRead() (Model, tea.Cmd) {
+	chatID := m.store.GetActiveChat()
+	if m.focus == focusChatList {
+		chatID = m.chatList.SelectedChatID()
+	}
+	if chatID == 0 {
+		return m, nil
+	}
+	m.store.OnMessageRead(chatID, 0)
+	client := m.client
+	return m, func() tea.Msg {
+		if err := client.MarkAsRead(context.Background(), chatID, 0); err != nil {
+			return localErrorMsg{err: fmt.Errorf("mark read: %w", err)}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// AlertModel renders a centered message that stays until dismissed, such
+// as a bot's alert in answer to a button press. An alert may carry a link,
+// which is opened only if the user confirms it.
+type AlertModel struct {
+	visible       bool
+	title         string
+	text          string
+	link          string
+	width, height int
+}
+
+// NewAlertModel creates a hidden alert.
+func NewAlertModel() AlertModel {
+	return AlertModel{}
+}
+
+// IsVisible reports whether the alert is showing.
+func (m AlertModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the alert with a title and message.
+func (m AlertModel) Show(title, text string) AlertModel {
+	m.visible = true
+	m.title = title
+	m.text = text
+	m.link = ""
+	return m
+}
+
+// ShowLink opens the alert with a link that comes from a bot, to be
+// opened if the user confirms it with Enter.
+func (m AlertModel) ShowLink(title, text, link string) AlertModel {
+	m = m.Show(title, text)
+	m.link = link
+	return m
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m AlertModel) SetSize(w, h int) AlertModel {
+	m.width = w
+	m.height = h
+	return m
+}
+
+func (m AlertModel) Update(msg tea.Msg) (AlertModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "enter":
+		m.visible = false
+		if m.link != "" {
+			return m, openURL(m.link)
+		}
+	case "esc", "space", "q":
+		m.visible = false
+	}
+	return m, nil
+}
+
+// View renders the alert box (without full-screen placement).
+func (m AlertModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	text, hint := m.text, "enter to close"
+	if m.link != "" {
+		text = strings.TrimSpace(text + "\n\nOpen " + m.link + "?")
+		hint = "enter to open · esc to cancel"
+	}
+	body := lipgloss.NewStyle().Width(min(lipgloss.Width(text), max(m.width-14, 20))).Render(text)
+	content := chatListHeaderStyle.Render(m.title) + "\n\n" + body + "\n\n" +
+		timeStyle.Render(hint)
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 3).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(content)
+}
@@ -2,12 +2,16 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/colorprofile"
 
 	"github.com/danhigham/telecharm/internal/config"
 	"github.com/danhigham/telecharm/internal/domain"
@@ -30,9 +34,6 @@ const (
 	splitStep       = 4
 )
 
-// inputRenderedHeight is the total height of the input box (4 inner + 2 border).
-const inputRenderedHeight = 6
-
 // Model is the root Bubble Tea model.
 type Model struct {
 	chatList    ChatListModel
@@ -42,40 +43,111 @@ type Model struct {
 	status      statusModel
 	splash      SplashModel
 	help        HelpModel
+	muteMenu    MuteMenuModel
+	emojiPicker EmojiPickerModel
+	reactions   ReactionPickerModel
+	forward     ForwardPickerModel
+	jumpPrompt  JumpPromptModel
+	search      SearchModel
+	global      GlobalSearchModel
+	switcher    SwitcherModel
+	palette     CommandPaletteModel
+	alert       AlertModel
+	inline      InlineResultsModel
+	pollForm    PollFormModel
+
+	// actions is the registry behind key bindings, the help overlay and
+	// the command palette.
+	actions []action
+	// slashCommands are the commands typed in the input, like "/mute 1h".
+	slashCommands []slashCommand
+
+	store        *state.Store
+	client       telegram.Client
+	authFlow     *telegram.TUIAuth
+	cfg          *config.Config
+	cfgPath      string
+	frecency     *config.Frecency
+	frecencyPath string
+	history      *config.History
+	historyPath  string
 
-	store    *state.Store
-	client   telegram.Client
-	authFlow *telegram.TUIAuth
-	cfg      *config.Config
-	cfgPath  string
+	// emojiChanged marks recent emoji not yet saved to the config, which
+	// is written on the way out rather than on every pick.
+	emojiChanged bool
 
 	focus           focusTarget
 	splitPos        int // width of the chat list pane (resizable)
 	chatListVisible bool
 	width           int
 	height          int
+
+	// pinsLoading is the chat whose pinned messages are being fetched.
+	pinsLoading int64
+	// draftBase is the open chat's draft as last synced. The input differs
+	// from it once edited here; until then, drafts written on other
+	// devices replace the input's text.
+	draftBase string
 }
 
+// historyPageSize is how many messages a history fetch loads, whether
+// paging back, paging forward across a gap, or around a jump target.
+const historyPageSize = 50
+
+// draftSaveTimeout bounds syncing a draft, which may delay quitting.
+const draftSaveTimeout = 3 * time.Second
+
 // NewModel creates the root model with all sub-components.
 func NewModel(store *state.Store, client telegram.Client, authFlow *telegram.TUIAuth, cfg *config.Config, cfgPath string) Model {
+	// main rejects key configs that don't bind with CheckKeys, so this
+	// only falls back for configs built by hand.
+	actions, err := bindActions(cfg.Keys)
+	if err != nil {
+		actions = defaultActions()
+	}
+	slash := defaultSlashCommands()
 	m := Model{
-		chatList:    NewChatListModel(),
-		messageView: NewMessageViewModel(cfg.BubblesEnabled()),
-		input:       NewInputModel(),
-		auth:        NewAuthModel(),
-		status:      newStatusModel(),
-		splash:      NewSplashModel(),
-		help:        NewHelpModel(),
-		store:       store,
-		client:      client,
-		authFlow:    authFlow,
-		cfg:         cfg,
-		cfgPath:     cfgPath,
+		chatList:        NewChatListModel(),
+		messageView:     NewMessageViewModel(cfg.BubblesEnabled()),
+		input:           NewInputModel().SetRecentEmoji(cfg.RecentEmoji).SetSlashCommands(slash),
+		auth:            NewAuthModel(),
+		status:          newStatusModel(),
+		splash:          NewSplashModel(),
+		help:            NewHelpModel(actions),
+		muteMenu:        NewMuteMenuModel(),
+		emojiPicker:     NewEmojiPickerModel(),
+		reactions:       NewReactionPickerModel(),
+		forward:         NewForwardPickerModel(),
+		jumpPrompt:      NewJumpPromptModel(),
+		search:          NewSearchModel(),
+		global:          NewGlobalSearchModel(),
+		switcher:        NewSwitcherModel(),
+		palette:         NewCommandPaletteModel(),
+		alert:           NewAlertModel(),
+		inline:          NewInlineResultsModel(),
+		pollForm:        NewPollFormModel(),
+		actions:         actions,
+		slashCommands:   slash,
+		store:           store,
+		client:          client,
+		authFlow:        authFlow,
+		cfg:             cfg,
+		cfgPath:         cfgPath,
 		focus:           focusChatList,
 		splitPos:        defaultSplitPos,
 		chatListVisible: true,
 	}
 
+	// Chat frecency lives next to the config. A corrupt file only costs
+	// the ranking, which rebuilds as chats are opened.
+	m.frecencyPath = filepath.Join(filepath.Dir(cfgPath), "frecency.yaml")
+	m.frecency, _ = config.LoadFrecency(m.frecencyPath)
+
+	// So does the history of sent messages recalled in the input.
+	m.historyPath = filepath.Join(filepath.Dir(cfgPath), "history.yaml")
+	m.history, _ = config.LoadHistory(m.historyPath)
+	m.input = m.input.SetHistory(m.history)
+
 	m.auth = m.auth.SetOnSubmit(func(stage domain.AuthState, value string) {
 		switch stage {
 		case domain.AuthStatePhone:
@@ -102,6 +174,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.ColorProfileMsg:
+		setColorProfile(msg.Profile)
+		m.messageView = m.messageView.renderContentNoScroll()
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -110,6 +187,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case list.FilterMatchesMsg:
 		var cmd tea.Cmd
+		if m.forward.IsVisible() {
+			m.forward, cmd = m.forward.Update(msg)
+			return m, cmd
+		}
 		m.chatList, cmd = m.chatList.Update(msg)
 		return m, cmd
 
@@ -124,32 +205,74 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		}
-		return m, nil
+		// A pin we didn't have cached invalidates the chat's pins.
+		var cmd tea.Cmd
+		m, cmd = m.loadPinned()
+		return m, cmd
 
 	case AuthRequestMsg:
 		m.auth = m.auth.Show(msg.Stage)
 		return m, nil
 
 	case ChatSelectedMsg:
+		// Keep the unsent text with the chat it was written for.
+		if prev := m.store.GetActiveChat(); prev != msg.ChatID {
+			cmds = append(cmds, m.saveDraft(prev))
+			m.draftBase = m.store.GetDraft(msg.ChatID)
+			m.input = m.input.SetValue(m.draftBase)
+			m = m.syncInputHeight()
+		}
 		m.store.SetActiveChat(msg.ChatID)
+		m.chatList = m.chatList.WithItems(m.chatPreviews())
+		m.inline = m.inline.Hide()
+		m.input = m.input.SetChat(msg.ChatID)
+		m.frecency.Visit(msg.ChatID, time.Now())
+		frecency, frecencyPath := m.frecency, m.frecencyPath
+		cmds = append(cmds, func() tea.Msg {
+			if err := frecency.Save(frecencyPath); err != nil {
+				return localErrorMsg{err: fmt.Errorf("save recent chats: %w", err)}
+			}
+			return nil
+		})
+		m.search = m.search.Reset()
+		m.messageView = m.messageView.SetHighlight()
 		chats := m.store.GetChatList()
 		for _, c := range chats {
 			if c.ID == msg.ChatID {
 				m.status = m.status.SetChatTitle(c.Title)
+				if c.UnreadMentions > 0 {
+					cmds = append(cmds, m.loadUnreadMentions(c.ID))
+				}
 				break
 			}
 		}
+		// Replace the previous chat's messages even if this chat has none
+		// cached yet, so jumps can't land on a stale message.
 		msgs := m.store.GetMessages(msg.ChatID)
-		if len(msgs) > 0 {
-			m.messageView = m.messageView.SetMessages(msgs)
+		m.messageView = m.messageView.SetMessages(msgs, m.store.GetGaps(msg.ChatID))
+		botCommands, _ := m.store.GetBotCommands(msg.ChatID)
+		m.input = m.input.SetBotCommands(botCommands)
+		participants, ok := m.store.GetParticipants(msg.ChatID)
+		m.input = m.input.SetParticipants(participants)
+		if ok {
+			cmds = append(cmds, m.loadBotCommands(msg.ChatID, participants))
+		} else {
+			cmds = append(cmds, m.loadParticipants(msg.ChatID))
 		}
+		m.input = m.input.SetQuickReplies(m.store.GetReplyKeyboard(msg.ChatID))
+		m = m.syncInputHeight()
+		pins, _ := m.store.GetPinnedMessages(msg.ChatID)
+		m.messageView = m.messageView.SetPinned(pins)
+		var pinCmd tea.Cmd
+		m, pinCmd = m.loadPinned()
+		cmds = append(cmds, pinCmd)
 		m.focus = focusInput
 		m = m.updateFocus()
 		if len(msgs) == 0 {
 			chatID := msg.ChatID
 			client := m.client
 			cmds = append(cmds, func() tea.Msg {
-				history, err := client.GetHistory(context.Background(), chatID, 50, 0)
+				history, err := client.GetHistory(context.Background(), chatID, historyPageSize, 0)
 				if err != nil {
 					return SendErrorMsg{Err: err}
 				}
@@ -159,9 +282,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 
 	case HistoryLoadedMsg:
-		m.store.SetMessages(msg.ChatID, msg.Messages)
+		// Merge rather than replace, since a jump may have loaded a window
+		// of older history while this was in flight.
+		m.store.InsertHistory(msg.ChatID, msg.Messages, true)
 		if m.store.GetActiveChat() == msg.ChatID {
-			m.messageView = m.messageView.SetMessages(msg.Messages)
+			m.messageView = m.messageView.SetMessages(m.store.GetMessages(msg.ChatID), m.store.GetGaps(msg.ChatID))
 		}
 		return m, nil
 
@@ -177,7 +302,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		chatID := msg.ChatID
 		client := m.client
 		cmds = append(cmds, func() tea.Msg {
-			history, err := client.GetHistory(context.Background(), chatID, 50, oldestID)
+			history, err := client.GetHistory(context.Background(), chatID, historyPageSize, oldestID)
 			if err != nil {
 				return SendErrorMsg{Err: err}
 			}
@@ -192,24 +317,278 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case LoadNewerHistoryMsg:
+		if m.store.GetActiveChat() != msg.ChatID {
+			return m, nil
+		}
+		m.messageView = m.messageView.SetLoadingNewer(true)
+		chatID := msg.ChatID
+		client := m.client
+		// Starting at AfterID itself makes the page overlap what we have,
+		// so the store can join them.
+		q := telegram.HistoryQuery{OffsetID: msg.AfterID, AddOffset: -historyPageSize, Limit: historyPageSize}
+		return m, func() tea.Msg {
+			history, err := client.GetHistoryWindow(context.Background(), chatID, q)
+			if err != nil {
+				return SendErrorMsg{Err: err}
+			}
+			return NewerHistoryLoadedMsg{ChatID: chatID, Messages: history, ReachedLatest: len(history) < historyPageSize}
+		}
+
+	case NewerHistoryLoadedMsg:
+		m.store.InsertHistory(msg.ChatID, msg.Messages, msg.ReachedLatest)
+		if m.store.GetActiveChat() == msg.ChatID {
+			m.messageView = m.messageView.SetLoadingNewer(false)
+			m.messageView = m.messageView.SetMessages(m.store.GetMessages(msg.ChatID), m.store.GetGaps(msg.ChatID))
+		}
+		return m, nil
+
+	case jumpMsg:
+		return m.jumpTo(msg)
+
+	case historyWindowLoadedMsg:
+		m.store.InsertHistory(msg.chatID, msg.msgs, msg.reachedLatest)
+		if m.store.GetActiveChat() != msg.chatID {
+			return m, nil
+		}
+		m.messageView = m.messageView.SetMessages(m.store.GetMessages(msg.chatID), m.store.GetGaps(msg.chatID))
+		id := msg.target.id
+		if id == 0 {
+			// Show the first message at or after the date, or the
+			// newest before it.
+			for _, hm := range msg.msgs {
+				id = hm.ID
+				if !hm.Timestamp.Before(msg.target.date) {
+					break
+				}
+			}
+		}
+		return m.showMessage(id), nil
+
+	case searchMsg:
+		chatID := m.store.GetActiveChat()
+		client := m.client
+		query, filter := msg.query, msg.filter
+		// from:, before: and after: only apply to cached results; the
+		// server gets the words and phrases.
+		text := strings.Join(state.ParseQuery(query).Terms(), " ")
+		return m, func() tea.Msg {
+			msgs, err := client.SearchMessages(context.Background(), chatID, text, filter)
+			return searchResultsMsg{chatID: chatID, query: query, filter: filter, msgs: msgs, err: err}
+		}
+
+	case searchResultsMsg:
+		if m.store.GetActiveChat() != msg.chatID {
+			return m, nil
+		}
+		if msg.msgs == nil && msg.err == nil {
+			msg.msgs = []domain.Message{}
+		}
+		m.search = m.search.SetResults(msg.query, msg.filter, msg.msgs, msg.err)
+		return m, nil
+
+	case searchHitMsg:
+		m.messageView = m.messageView.SetHighlight(m.search.Terms()...)
+		return m.jumpTo(jumpMsg{id: msg.msg.ID})
+
+	case globalSearchMsg:
+		client := m.client
+		query := msg.query
+		return m, func() tea.Msg {
+			res, err := client.SearchGlobal(context.Background(), query)
+			return globalSearchResultsMsg{query: query, results: res, err: err}
+		}
+
+	case globalSearchResultsMsg:
+		m.global = m.global.SetResults(msg.query, msg.results, msg.err)
+		return m, nil
+
+	case runActionMsg:
+		return m.runAction(msg.name)
+
+	case openResultMsg:
+		m.store.EnsureChat(msg.chat)
+		chatID := msg.chat.ID
+		open := func() tea.Msg { return ChatSelectedMsg{ChatID: chatID} }
+		if msg.msgID == 0 {
+			return m, open
+		}
+		target := jumpMsg{id: msg.msgID}
+		return m, tea.Sequence(open, func() tea.Msg { return target })
+
+	case participantsLoadedMsg:
+		m.store.SetParticipants(msg.chatID, msg.participants)
+		if m.store.GetActiveChat() == msg.chatID {
+			m.input = m.input.SetParticipants(msg.participants)
+		}
+		return m, m.loadBotCommands(msg.chatID, msg.participants)
+
+	case botCommandsLoadedMsg:
+		m.store.SetBotCommands(msg.chatID, msg.commands)
+		if m.store.GetActiveChat() == msg.chatID {
+			m.input = m.input.SetBotCommands(msg.commands)
+		}
+		return m, nil
+
+	case pinnedLoadedMsg:
+		m.store.SetPinnedMessages(msg.chatID, msg.msgs)
+		if m.pinsLoading == msg.chatID {
+			m.pinsLoading = 0
+		}
+		if m.store.GetActiveChat() == msg.chatID {
+			m.messageView = m.messageView.SetPinned(msg.msgs)
+		}
+		return m, nil
+
+	case pinMsg:
+		client := m.client
+		chatID, msgID, pin := msg.chatID, msg.msgID, msg.pin
+		return m, func() tea.Msg {
+			if err := client.PinMessage(context.Background(), chatID, msgID, pin); err != nil {
+				return localErrorMsg{err: fmt.Errorf("pin: %w", err)}
+			}
+			return nil
+		}
+
+	case unreadMentionsLoadedMsg:
+		m.store.SetUnreadMentions(msg.chatID, msg.ids)
+		return m, nil
+
+	case editorFinishedMsg:
+		if msg.err != nil {
+			m.status.text = fmt.Sprintf("Editor error: %v", msg.err)
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.FinishEdit(msg.text)
+		return m.syncInputHeight(), cmd
+
 	case sendMessageMsg:
 		chatID := m.store.GetActiveChat()
 		if chatID == 0 {
 			return m, nil
 		}
-		client := m.client
-		text := msg.text
-		cmds = append(cmds, func() tea.Msg {
-			sentMsg, err := client.SendMessage(context.Background(), chatID, text)
+		return m, tea.Batch(m.rememberSent(chatID, msg.text), m.sendText(chatID, msg.text, msg.mentions))
+
+	case slashCommandMsg:
+		c, _ := findSlashCommand(m.slashCommands, msg.name)
+		chatID := m.store.GetActiveChat()
+		switch {
+		case c.needsChat && chatID == 0:
+			m.status.text = fmt.Sprintf("/%s needs an open chat", c.name)
+			return m, nil
+		case c.argRequired() && strings.TrimSpace(msg.arg) == "":
+			m.status.text = "Usage: " + c.usage()
+			return m, nil
+		}
+		if chatID != 0 {
+			cmds = append(cmds, m.rememberSent(chatID, msg.text))
+		}
+		var cmd tea.Cmd
+		m, cmd = c.run(m, msg.arg)
+		return m, tea.Batch(append(cmds, cmd)...)
+
+	case selfNameChangedMsg:
+		m.status = m.status.SetUserName(msg.name)
+		return m, nil
+
+	case inlineQueryMsg:
+		bot, query, ok := m.input.InlineQuery()
+		chatID := m.store.GetActiveChat()
+		if !ok || chatID == 0 {
+			m.inline = m.inline.Hide()
+			return m, nil
+		}
+		if bot != msg.bot || query != msg.query {
+			// Typing went on; a later message carries the new query.
+			return m, nil
+		}
+		return m, m.loadInlineResults(chatID, bot, query, "")
+
+	case inlineResultsMsg:
+		bot, query, ok := m.input.InlineQuery()
+		if !ok || bot != msg.bot || query != msg.query || m.store.GetActiveChat() != msg.chatID {
+			return m, nil
+		}
+		switch {
+		case errors.Is(msg.err, telegram.ErrNotInlineBot):
+			m.inline = m.inline.Hide()
+		case msg.err != nil:
+			m.inline = m.inline.Hide()
+			m.status.text = fmt.Sprintf("@%s: %v", bot, msg.err)
+		case msg.offset != "":
+			m.inline = m.inline.Append(msg.results)
+		default:
+			m.inline = m.inline.Show(msg.chatID, bot, query, msg.results)
+		}
+		return m, nil
+
+	case inlineMoreMsg:
+		bot, query, ok := m.input.InlineQuery()
+		if !ok {
+			return m, nil
+		}
+		var offset string
+		if m.inline, offset, ok = m.inline.NextPage(); !ok {
+			return m, nil
+		}
+		return m, m.loadInlineResults(m.store.GetActiveChat(), bot, query, offset)
+
+	case sendInlineResultMsg:
+		m.input = m.input.SetValue("")
+		m = m.syncInputHeight()
+		m.inline = m.inline.Hide()
+		m.store.OnDraft(msg.chatID, "")
+		client, store := m.client, m.store
+		return m, func() tea.Msg {
+			sent, err := client.SendInlineResult(context.Background(), msg.chatID, msg.queryID, msg.result.ID)
 			if err != nil {
 				return SendErrorMsg{Err: err}
 			}
-			// Show the sent message immediately; OnNewMessage deduplicates
-			// if the update dispatcher later echoes it back.
-			m.store.OnNewMessage(sentMsg)
+			store.OnNewMessage(sent)
 			return nil
-		})
-		return m, tea.Batch(cmds...)
+		}
+
+	case pressButtonMsg:
+		return m.pressButton(msg)
+
+	case votePollMsg:
+		client, store := m.client, m.store
+		return m, func() tea.Msg {
+			poll, err := client.Vote(context.Background(), msg.chatID, msg.msgID, msg.options)
+			if err != nil {
+				return localErrorMsg{err: fmt.Errorf("vote: %w", err)}
+			}
+			store.OnPollUpdated(poll)
+			return nil
+		}
+
+	case sendPollMsg:
+		client, store := m.client, m.store
+		return m, func() tea.Msg {
+			sent, err := client.SendPoll(context.Background(), msg.chatID, msg.poll)
+			if err != nil {
+				return SendErrorMsg{Err: err}
+			}
+			store.OnNewMessage(sent)
+			return nil
+		}
+
+	case buttonAnsweredMsg:
+		if msg.err != nil {
+			return m, func() tea.Msg { return localErrorMsg{err: fmt.Errorf("press button: %w", msg.err)} }
+		}
+		switch {
+		case msg.answer.URL != "":
+			// Games and login buttons answer with a link; the user
+			// sees where it goes before anything opens.
+			m.alert = m.alert.ShowLink(m.status.chatTitle, msg.answer.Message, msg.answer.URL)
+		case msg.answer.Alert && msg.answer.Message != "":
+			m.alert = m.alert.Show(m.status.chatTitle, msg.answer.Message)
+		case msg.answer.Message != "":
+			m.status.text = msg.answer.Message
+		}
+		return m, nil
 
 	case SplashDoneMsg:
 		m.splash = m.splash.TimerDone()
@@ -235,17 +614,72 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status.connected = false
 		return m, nil
 
+	case localErrorMsg:
+		m.status.text = fmt.Sprintf("Error: %v", msg.err)
+		return m, nil
+
+	case muteChatMsg:
+		client := m.client
+		store := m.store
+		chatID := msg.chatID
+		until := msg.until
+		return m, func() tea.Msg {
+			if err := client.SetMuteUntil(context.Background(), chatID, until); err != nil {
+				return localErrorMsg{err: fmt.Errorf("update notifications: %w", err)}
+			}
+			// Reflect the change immediately; UpdateNotifySettings will
+			// confirm it (and carries changes made on other devices).
+			store.OnNotifySettings(chatID, until)
+			return nil
+		}
+
 	case BubblesToggledMsg:
 		m.cfg.SetBubbles(msg.Enabled)
-		cfgPath := m.cfgPath
-		cfg := m.cfg
+		m.emojiChanged = false
+		return m, m.saveConfig()
+
+	case reactionsLoadedMsg:
+		if msg.err == nil {
+			m.store.SetAvailableReactions(msg.msg.ChatID, msg.allowed)
+		}
+		m.reactions = m.reactions.Show(msg.msg, msg.allowed)
+		return m, nil
+
+	case reactMsg:
+		client := m.client
+		chatID, msgID, emoji := msg.chatID, msg.msgID, msg.emoji
+		return m, func() tea.Msg {
+			if err := client.SetReaction(context.Background(), chatID, msgID, emoji); err != nil {
+				return localErrorMsg{err: fmt.Errorf("react: %w", err)}
+			}
+			return nil
+		}
+
+	case forwardMsg:
+		client := m.client
+		store := m.store
+		fwd := msg
 		return m, func() tea.Msg {
-			if err := cfg.Save(cfgPath); err != nil {
-				return SendErrorMsg{Err: fmt.Errorf("save config: %w", err)}
+			msgs, err := client.ForwardMessages(context.Background(), fwd.fromChatID, fwd.msgIDs, fwd.toChatID)
+			if err != nil {
+				return SendErrorMsg{Err: err}
+			}
+			for _, msg := range msgs {
+				store.OnNewMessage(msg)
 			}
 			return nil
 		}
 
+	case emojiPickedMsg:
+		m.input = m.input.InsertEmoji(msg.char)
+		return m.syncInputHeight(), emojiUsedCmd(msg.char)
+
+	case emojiUsedMsg:
+		m.cfg.AddRecentEmoji(msg.char)
+		m.input = m.input.SetRecentEmoji(m.cfg.RecentEmoji)
+		m.emojiChanged = true
+		return m, nil
+
 	case tea.KeyMsg:
 		// Auth takes priority over the splash screen — the connection
 		// request arrives within the 3-second splash window and we must
@@ -256,22 +690,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
-		if m.splash.IsVisible() {
+		if o, ok := m.activeModal(); ok {
 			if msg.String() == "ctrl+c" {
-				return m, tea.Quit
-			}
-			return m, nil
-		}
-
-		if m.help.IsVisible() {
-			switch msg.String() {
-			case "ctrl+c":
-				return m, tea.Quit
-			case "h", "f1", "esc", "q":
-				m.help = m.help.Toggle()
-				return m, nil
+				return m.quit()
 			}
-			return m, nil
+			return o.handleKey(m, msg)
 		}
 
 		// When the chat list filter is active, pass all keys (except
@@ -284,88 +707,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(cmds...)
 		}
 
-		switch msg.String() {
-		case "ctrl+c":
-			return m, tea.Quit
-		case "f1":
-			m.help = m.help.Toggle()
-			return m, nil
-		case "q":
-			if m.focus != focusInput {
-				return m, tea.Quit
-			}
-		case "h":
-			if m.focus != focusInput {
-				m.help = m.help.Toggle()
-				return m, nil
-			}
-		case "ctrl+b":
-			m.chatListVisible = !m.chatListVisible
-			if !m.chatListVisible && m.focus == focusChatList {
-				m.focus = focusMessages
-				m = m.updateFocus()
-			}
-			m = m.distributeSize()
-			return m, nil
-		case "tab":
-			if m.chatListVisible {
-				m.focus = (m.focus + 1) % 3
-			} else {
-				// Toggle between messages and input only
-				if m.focus == focusMessages {
-					m.focus = focusInput
-				} else {
-					m.focus = focusMessages
-				}
-			}
-			m = m.updateFocus()
-			return m, nil
-		case "shift+tab":
-			if m.chatListVisible {
-				m.focus = (m.focus + 2) % 3
-			} else {
-				if m.focus == focusMessages {
-					m.focus = focusInput
-				} else {
-					m.focus = focusMessages
-				}
-			}
-			m = m.updateFocus()
-			return m, nil
-		case "esc":
-			if m.chatListVisible {
-				m.focus = focusChatList
-			} else {
-				m.focus = focusMessages
-			}
-			m = m.updateFocus()
-			return m, nil
-		case "j", "k":
-			if m.focus != focusInput {
+		// Inline results take the keys that choose among them; the rest
+		// keep editing the query.
+		if m.focus == focusInput && m.inline.IsVisible() {
+			switch msg.String() {
+			case "up", "down", "ctrl+p", "ctrl+n", "enter", "tab", "esc":
 				var cmd tea.Cmd
-				m.messageView, cmd = m.messageView.Update(msg)
-				cmds = append(cmds, cmd)
-				return m, tea.Batch(cmds...)
+				m.inline, cmd = m.inline.Update(msg)
+				return m, cmd
 			}
-		case "pgup", "pgdown":
+		}
+
+		// Likewise while the input's completion popup or history search is
+		// open, so arrows, Tab, Enter and Esc drive the popup.
+		if m.focus == focusInput && (m.input.IsCompleting() || m.input.IsSearchingHistory() || m.input.IsChoosingQuickReply()) && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m.syncInputHeight(), cmd
+		}
+
+		// And while choosing a button on a message's keyboard or voting in
+		// its poll.
+		if m.focus == focusMessages && (m.messageView.IsPressingButtons() || m.messageView.IsVoting()) && msg.String() != "ctrl+c" {
 			var cmd tea.Cmd
 			m.messageView, cmd = m.messageView.Update(msg)
-			cmds = append(cmds, cmd)
-			return m, tea.Batch(cmds...)
-		case "[":
-			if m.focus != focusInput && m.chatListVisible {
-				m.splitPos -= splitStep
-				m = m.clampSplitPos()
-				m = m.distributeSize()
-				return m, nil
-			}
-		case "]":
-			if m.focus != focusInput && m.chatListVisible {
-				m.splitPos += splitStep
-				m = m.clampSplitPos()
-				m = m.distributeSize()
-				return m, nil
-			}
+			return m, cmd
+		}
+
+		if a, ok := m.actionForKey(msg.String()); ok {
+			return a.run(m)
 		}
 
 		switch m.focus {
@@ -380,6 +750,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case focusInput:
 			var cmd tea.Cmd
 			m.input, cmd = m.input.Update(msg)
+			m = m.syncInputHeight()
 			cmds = append(cmds, cmd)
 		}
 		return m, tea.Batch(cmds...)
@@ -393,7 +764,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.focus == focusInput {
 			var cmd tea.Cmd
 			m.input, cmd = m.input.Update(msg)
-			return m, cmd
+			return m.syncInputHeight(), cmd
 		}
 		return m, nil
 	}
@@ -439,18 +810,18 @@ func (m Model) View() tea.View {
 		MaxHeight(m.height).
 		Render(full)
 
-	if m.splash.IsVisible() {
-		x, y := m.splash.BoxOffset()
-		bg := lipgloss.NewLayer(mainContent)
-		fg := lipgloss.NewLayer(m.splash.View()).X(x).Y(y).Z(1)
-		comp := lipgloss.NewCompositor(bg, fg)
-		v.SetContent(comp.Render())
-	} else if m.help.IsVisible() {
-		x, y := m.help.BoxOffset()
-		bg := lipgloss.NewLayer(mainContent)
-		fg := lipgloss.NewLayer(m.help.View()).X(x).Y(y).Z(1)
-		comp := lipgloss.NewCompositor(bg, fg)
-		v.SetContent(comp.Render())
+	if o, ok := m.activeModal(); ok {
+		box := o.View()
+		x, y := centerOffset(box, m.width, m.height)
+		v.SetContent(drawOver(mainContent, box, x, y))
+	} else if m.inline.IsVisible() && m.focus == focusInput {
+		popup := m.inline.View()
+		x, y := m.inputPopupOffset(popup)
+		v.SetContent(drawOver(mainContent, popup, x, y))
+	} else if popup := m.input.PopupView(); popup != "" && m.focus == focusInput {
+		// Completion popup sits just above the input box.
+		x, y := m.inputPopupOffset(popup)
+		v.SetContent(drawOver(mainContent, popup, x, y))
 	} else {
 		v.SetContent(mainContent)
 	}
@@ -493,22 +864,377 @@ func (m Model) distributeSize() Model {
 		rightWidth = 1
 	}
 
-	// Input gets fixed height, messages get the rest
-	messagesHeight := contentHeight - inputRenderedHeight
+	// Input grows with its content, messages get the rest
+	m.input = m.input.SetSize(rightWidth, minInputHeight)
+	inputHeight := m.inputRenderedHeight()
+	m.input = m.input.SetSize(rightWidth, inputHeight)
+
+	messagesHeight := contentHeight - inputHeight
 	if messagesHeight < 1 {
 		messagesHeight = 1
 	}
-
 	m.messageView = m.messageView.SetSize(rightWidth, messagesHeight)
-	m.input = m.input.SetSize(rightWidth, inputRenderedHeight)
+	m.inline = m.inline.SetWidth(rightWidth)
 
 	m.auth = m.auth.SetSize(m.width, m.height)
 	m.splash = m.splash.SetSize(m.width, m.height)
 	m.help = m.help.SetSize(m.width, m.height)
+	m.muteMenu = m.muteMenu.SetSize(m.width, m.height)
+	m.emojiPicker = m.emojiPicker.SetSize(m.width, m.height)
+	m.reactions = m.reactions.SetSize(m.width, m.height)
+	m.forward = m.forward.SetSize(m.width, m.height)
+	m.switcher = m.switcher.SetSize(m.width, m.height)
+	m.palette = m.palette.SetSize(m.width, m.height)
+	m.jumpPrompt = m.jumpPrompt.SetSize(m.width, m.height)
+	m.search = m.search.SetSize(m.width, m.height)
+	m.global = m.global.SetSize(m.width, m.height)
+	m.alert = m.alert.SetSize(m.width, m.height)
+	m.pollForm = m.pollForm.SetSize(m.width, m.height)
+
+	return m
+}
+
+// inputRenderedHeight is the total height of the input box: tall
+// enough for its content, but never more than half the content area.
+func (m Model) inputRenderedHeight() int {
+	return max(min(m.input.ContentHeight(), (m.height-1)/2), minInputHeight)
+}
+
+// syncInputHeight resizes the panes when the input's content needs a
+// different height.
+func (m Model) syncInputHeight() Model {
+	if m.inputRenderedHeight() != m.input.height {
+		m = m.distributeSize()
+	}
+	return m
+}
+
+// inputPopupOffset returns the (x, y) that places a popup directly above
+// the input box, aligned with its left edge.
+func (m Model) inputPopupOffset(popup string) (int, int) {
+	x := 0
+	if m.chatListVisible {
+		x = m.splitPos
+	}
+	// Status bar (1 row) plus the message pane sit above the input.
+	inputTop := m.height - m.input.height
+	y := inputTop - lipgloss.Height(popup)
+	if y < 1 {
+		y = 1
+	}
+	return x, y
+}
+
+// loadParticipants fetches a chat's members for mention completion. Failures
+// (e.g. broadcast channels we can't list) cache an empty list.
+func (m Model) loadParticipants(chatID int64) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ps, err := client.GetParticipants(context.Background(), chatID)
+		if err != nil {
+			ps = nil
+		}
+		return participantsLoadedMsg{chatID: chatID, participants: ps}
+	}
+}
+
+// loadBotCommands fetches the commands of a chat's bots for slash
+// completion, unless they are cached. Only chats whose members include a
+// bot are asked, since each lookup fetches the full user or chat; human
+// DMs and broadcast channels, whose members we can't list, are skipped.
+// Failures cache an empty list, as for participants.
+func (m Model) loadBotCommands(chatID int64, participants []domain.Participant) tea.Cmd {
+	if _, ok := m.store.GetBotCommands(chatID); ok {
+		return nil
+	}
+	hasBot := false
+	for _, p := range participants {
+		hasBot = hasBot || p.Bot
+	}
+	if !hasBot {
+		return nil
+	}
+	client := m.client
+	return func() tea.Msg {
+		cmds, err := client.GetBotCommands(context.Background(), chatID)
+		if err != nil {
+			cmds = nil
+		}
+		return botCommandsLoadedMsg{chatID: chatID, commands: cmds}
+	}
+}
+
+// loadInlineResults asks an inline bot for a page of results.
+func (m Model) loadInlineResults(chatID int64, bot, query, offset string) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		results, err := client.GetInlineResults(context.Background(), chatID, bot, query, offset)
+		return inlineResultsMsg{chatID: chatID, bot: bot, query: query, offset: offset, results: results, err: err}
+	}
+}
+
+// rememberSent records text sent to a chat for recall in the input and
+// saves the history.
+func (m Model) rememberSent(chatID int64, text string) tea.Cmd {
+	m.history.Add(chatID, text)
+	history, historyPath := m.history, m.historyPath
+	return func() tea.Msg {
+		if err := history.Save(historyPath); err != nil {
+			return localErrorMsg{err: fmt.Errorf("save input history: %w", err)}
+		}
+		return nil
+	}
+}
+
+// sendText sends a message to a chat and shows it once sent.
+func (m Model) sendText(chatID int64, text string, mentions []domain.Mention) tea.Cmd {
+	// Sending clears the draft on the server too.
+	m.store.OnDraft(chatID, "")
+	client, store := m.client, m.store
+	return func() tea.Msg {
+		sentMsg, err := client.SendMessage(context.Background(), chatID, text, mentions)
+		if err != nil {
+			return SendErrorMsg{Err: err}
+		}
+		// Show the sent message immediately; OnNewMessage deduplicates
+		// if the update dispatcher later echoes it back.
+		store.OnNewMessage(sentMsg)
+		return nil
+	}
+}
 
+// sendFile uploads and sends a file to a chat and shows it once sent.
+func (m Model) sendFile(chatID int64, path, caption string) tea.Cmd {
+	client, store := m.client, m.store
+	return func() tea.Msg {
+		sentMsg, err := client.SendFile(context.Background(), chatID, path, caption)
+		if err != nil {
+			return localErrorMsg{err: fmt.Errorf("send file: %w", err)}
+		}
+		store.OnNewMessage(sentMsg)
+		return nil
+	}
+}
+
+// pressButton acts on a pressed keyboard button: text buttons send their
+// label, callback buttons ask the bot, link buttons open once confirmed,
+// and copy buttons are handled locally.
+func (m Model) pressButton(msg pressButtonMsg) (Model, tea.Cmd) {
+	b := msg.button
+	switch b.Kind {
+	case domain.ButtonText:
+		return m, tea.Batch(m.rememberSent(msg.chatID, b.Text), m.sendText(msg.chatID, b.Text, nil))
+	case domain.ButtonCallback:
+		client := m.client
+		chatID, msgID, data := msg.chatID, msg.msgID, b.Data
+		return m, func() tea.Msg {
+			answer, err := client.PressButton(context.Background(), chatID, msgID, data)
+			return buttonAnsweredMsg{answer: answer, err: err}
+		}
+	case domain.ButtonURL:
+		m.alert = m.alert.ShowLink(m.status.chatTitle, b.Text, b.URL)
+		return m, nil
+	case domain.ButtonCopy:
+		m.status.text = fmt.Sprintf("Copied %q", truncate(string(b.Data), 40))
+		return m, tea.SetClipboard(string(b.Data))
+	}
+	m.status.text = fmt.Sprintf("%q isn't supported here; use another Telegram app", b.Text)
+	return m, nil
+}
+
+// showMuteMenu opens the mute menu for the chat under the chat list cursor,
+// or for the active chat when another pane has focus.
+func (m Model) showMuteMenu() Model {
+	chatID := m.store.GetActiveChat()
+	if m.focus == focusChatList {
+		chatID = m.chatList.SelectedChatID()
+	}
+	if chatID == 0 {
+		return m
+	}
+	for _, c := range m.store.GetChatList() {
+		if c.ID == chatID {
+			m.muteMenu = m.muteMenu.Show(c.ID, c.Title, c.Muted())
+			break
+		}
+	}
 	return m
 }
 
+// showReactions opens the reaction picker for the selected message,
+// fetching the chat's allowed reactions first if they aren't cached.
+func (m Model) showReactions() (Model, tea.Cmd) {
+	msg, ok := m.messageView.SelectedMessage()
+	if !ok {
+		m.status.text = "Select a message with ↑/↓ to react"
+		return m, nil
+	}
+	if allowed, ok := m.store.GetAvailableReactions(msg.ChatID); ok {
+		m.reactions = m.reactions.Show(msg, allowed)
+		return m, nil
+	}
+	client := m.client
+	return m, func() tea.Msg {
+		// On error the picker falls back to the default set; the server
+		// rejects any reaction the chat doesn't allow.
+		allowed, err := client.GetAvailableReactions(context.Background(), msg.ChatID)
+		return reactionsLoadedMsg{msg: msg, allowed: allowed, err: err}
+	}
+}
+
+// showForward opens the forward picker for the selected message.
+func (m Model) showForward() Model {
+	msg, ok := m.messageView.SelectedMessage()
+	if !ok {
+		m.status.text = "Select a message with ↑/↓ to forward"
+		return m
+	}
+	m.forward = m.forward.Show(msg, m.store.GetChatList())
+	return m
+}
+
+// loadPinned fetches the active chat's pinned messages unless they are
+// cached or already on their way. Failures cache an empty list.
+func (m Model) loadPinned() (Model, tea.Cmd) {
+	chatID := m.store.GetActiveChat()
+	if chatID == 0 || m.pinsLoading == chatID {
+		return m, nil
+	}
+	if _, ok := m.store.GetPinnedMessages(chatID); ok {
+		return m, nil
+	}
+	m.pinsLoading = chatID
+	client := m.client
+	return m, func() tea.Msg {
+		msgs, err := client.GetPinnedMessages(context.Background(), chatID)
+		if err != nil {
+			msgs = nil
+		}
+		return pinnedLoadedMsg{chatID: chatID, msgs: msgs}
+	}
+}
+
+// jumpToPin shows the pinned message in the pin bar, then cycles the bar
+// to the next older pin. A pin older than the loaded history is fetched
+// by jumpTo as a window around it, however far back it is.
+func (m Model) jumpToPin() (Model, tea.Cmd) {
+	pin, ok := m.messageView.CurrentPin()
+	if !ok {
+		return m, nil
+	}
+	m.messageView = m.messageView.NextPin()
+	return m.jumpTo(jumpMsg{id: pin.ID})
+}
+
+// togglePin pins the selected message, or unpins it if already pinned.
+func (m Model) togglePin() (Model, tea.Cmd) {
+	msg, ok := m.messageView.SelectedMessage()
+	if !ok {
+		m.status.text = "Select a message with ↑/↓ to pin"
+		return m, nil
+	}
+	pinned := false
+	pins, _ := m.store.GetPinnedMessages(msg.ChatID)
+	for _, p := range pins {
+		if p.ID == msg.ID {
+			pinned = true
+			break
+		}
+	}
+	return m, func() tea.Msg {
+		return pinMsg{chatID: msg.ChatID, msgID: msg.ID, pin: !pinned}
+	}
+}
+
+// loadUnreadMentions fetches the IDs of unread mentions in a chat.
+func (m Model) loadUnreadMentions(chatID int64) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ids, err := client.GetUnreadMentions(context.Background(), chatID)
+		if err != nil {
+			return localErrorMsg{err: fmt.Errorf("load mentions: %w", err)}
+		}
+		return unreadMentionsLoadedMsg{chatID: chatID, ids: ids}
+	}
+}
+
+// jumpToMention shows the oldest unread mention in the active chat and
+// marks it read. As with pins, a mention older than the loaded history is
+// fetched by jumpTo as a window around it; one that has been deleted is
+// reported as not found and still marked read, so the next jump moves on.
+func (m Model) jumpToMention() (Model, tea.Cmd) {
+	chatID := m.store.GetActiveChat()
+	id, ok := m.store.NextUnreadMention(chatID)
+	if !ok {
+		return m, nil
+	}
+
+	m, jumpCmd := m.jumpTo(jumpMsg{id: id})
+	if m.store.MarkMentionRead(chatID, id) > 0 {
+		return m, jumpCmd
+	}
+	client := m.client
+	return m, tea.Batch(jumpCmd, func() tea.Msg {
+		if err := client.ReadMentions(context.Background(), chatID); err != nil {
+			return localErrorMsg{err: fmt.Errorf("read mentions: %w", err)}
+		}
+		return nil
+	})
+}
+
+// jumpTo shows a message in the active chat, by ID or date. Targets that
+// aren't loaded are fetched as a window of history centered on them,
+// which the store keeps alongside the loaded history with a gap between.
+func (m Model) jumpTo(target jumpMsg) (Model, tea.Cmd) {
+	chatID := m.store.GetActiveChat()
+	if chatID == 0 {
+		return m, nil
+	}
+	if target.id != 0 && m.messageView.HasMessage(target.id) {
+		return m.showMessage(target.id), nil
+	}
+
+	q := telegram.HistoryQuery{
+		OffsetID:   target.id,
+		OffsetDate: target.date,
+		AddOffset:  -historyPageSize / 2,
+		Limit:      historyPageSize,
+	}
+	client := m.client
+	return m, func() tea.Msg {
+		msgs, err := client.GetHistoryWindow(context.Background(), chatID, q)
+		if err != nil {
+			return SendErrorMsg{Err: fmt.Errorf("load history: %w", err)}
+		}
+		// Fewer messages at or after the target than asked for means the
+		// window reaches the newest message.
+		newer := 0
+		for _, msg := range msgs {
+			if (target.id != 0 && msg.ID >= target.id) || (target.id == 0 && !msg.Timestamp.Before(target.date)) {
+				newer++
+			}
+		}
+		return historyWindowLoadedMsg{
+			chatID:        chatID,
+			msgs:          msgs,
+			reachedLatest: newer < historyPageSize/2,
+			target:        target,
+		}
+	}
+}
+
+// showMessage selects a loaded message and focuses the message pane.
+func (m Model) showMessage(id int) Model {
+	var ok bool
+	m.messageView, ok = m.messageView.SelectMessage(id)
+	if !ok {
+		m.status.text = fmt.Sprintf("Message %d not found", id)
+		return m
+	}
+	m.focus = focusMessages
+	return m.updateFocus()
+}
+
 func (m Model) updateFocus() Model {
 	m.chatList = m.chatList.SetFocused(m.focus == focusChatList)
 	m.messageView = m.messageView.SetFocused(m.focus == focusMessages)
@@ -516,15 +1242,95 @@ func (m Model) updateFocus() Model {
 	return m
 }
 
-func (m Model) refreshFromStore() Model {
+// chatPreviews returns the chat list for the sidebar. The open chat's
+// draft is in the input, so it isn't previewed.
+func (m Model) chatPreviews() []domain.ChatInfo {
 	chats := m.store.GetChatList()
-	m.chatList = m.chatList.WithItems(chats)
+	active := m.store.GetActiveChat()
+	for i := range chats {
+		if chats[i].ID == active {
+			chats[i].Draft = ""
+		}
+	}
+	return chats
+}
+
+// saveDraft records the input as a chat's draft and syncs it to the
+// server if it changed.
+func (m Model) saveDraft(chatID int64) tea.Cmd {
+	text := m.input.Value()
+	if chatID == 0 || m.store.GetDraft(chatID) == text {
+		return nil
+	}
+	m.store.OnDraft(chatID, text)
+	client := m.client
+	return func() tea.Msg {
+		// Bounded, since this also runs on the way out.
+		ctx, cancel := context.WithTimeout(context.Background(), draftSaveTimeout)
+		defer cancel()
+		if err := client.SaveDraft(ctx, chatID, text); err != nil {
+			return localErrorMsg{err: fmt.Errorf("save draft: %w", err)}
+		}
+		return nil
+	}
+}
+
+// quit saves the open chat's draft and any recent emoji, then exits.
+// Every way out goes through here.
+func (m Model) quit() (Model, tea.Cmd) {
+	return m, tea.Sequence(m.saveDraft(m.store.GetActiveChat()), m.saveRecentEmoji(), tea.Quit)
+}
+
+// saveRecentEmoji writes the config if emoji have been picked since it
+// was last saved, and returns nil otherwise.
+func (m Model) saveRecentEmoji() tea.Cmd {
+	if !m.emojiChanged {
+		return nil
+	}
+	return m.saveConfig()
+}
+
+// saveConfig writes the config file, e.g. after a setting changes.
+func (m Model) saveConfig() tea.Cmd {
+	cfg, cfgPath := m.cfg, m.cfgPath
+	return func() tea.Msg {
+		if err := cfg.Save(cfgPath); err != nil {
+			return localErrorMsg{err: fmt.Errorf("save config: %w", err)}
+		}
+		return nil
+	}
+}
+
+// applyRemoteDraft takes in a draft of the open chat written on another
+// device, unless the input has been edited since the last sync; saving
+// those edits later overwrites the remote draft.
+func (m Model) applyRemoteDraft(chatID int64) Model {
+	draft := m.store.GetDraft(chatID)
+	if draft == m.draftBase {
+		return m
+	}
+	if m.input.Value() == m.draftBase {
+		m.input = m.input.SetValue(draft)
+		m = m.syncInputHeight()
+	}
+	m.draftBase = draft
+	return m
+}
+
+func (m Model) refreshFromStore() Model {
+	m.chatList = m.chatList.WithItems(m.chatPreviews())
+	m.chatList = m.chatList.SetTotalUnread(m.store.GetTotalUnread())
 
 	activeChat := m.store.GetActiveChat()
 	if activeChat != 0 {
 		m.messageView = m.messageView.SetTypingUser(m.store.GetTypingUser(activeChat))
 		msgs := m.store.GetMessages(activeChat)
-		m.messageView = m.messageView.SetMessages(msgs)
+		m.messageView = m.messageView.SetMessages(msgs, m.store.GetGaps(activeChat))
+		pins, _ := m.store.GetPinnedMessages(activeChat)
+		m.messageView = m.messageView.SetPinned(pins)
+		m.input = m.input.SetQuickReplies(m.store.GetReplyKeyboard(activeChat))
+		m = m.syncInputHeight()
+		m = m.applyRemoteDraft(activeChat)
 	}
 
 	return m
@@ -538,7 +1344,12 @@ type App struct {
 // NewApp creates a new App ready to Run.
 func NewApp(store *state.Store, client telegram.Client, authFlow *telegram.TUIAuth, cfg *config.Config, cfgPath string) *App {
 	model := NewModel(store, client, authFlow, cfg, cfgPath)
-	p := tea.NewProgram(model)
+	var opts []tea.ProgramOption
+	if cfg.ThemeName() == config.ThemeNoColor {
+		// Also strips the colors of rendered markdown.
+		opts = append(opts, tea.WithColorProfile(colorprofile.ASCII))
+	}
+	p := tea.NewProgram(model, opts...)
 	return &App{program: p}
 }
 
@@ -553,6 +1364,12 @@ func (a *App) Send(msg tea.Msg) {
 	go a.program.Send(msg)
 }
 
+// WriteRaw writes an escape sequence (e.g. a terminal notification) straight
+// to the terminal, serialized with the renderer's output.
+func (a *App) WriteRaw(seq string) {
+	a.Send(tea.RawMsg{Msg: seq})
+}
+
 // DrawFunc returns a function suitable for state.Store that triggers a re-render.
 func (a *App) DrawFunc() func() {
 	return func() {
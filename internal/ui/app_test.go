@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/danhigham/telecharm/internal/config"
+	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/state"
+	"github.com/danhigham/telecharm/internal/telegram"
+)
+
+// newTestModel returns a model without a client, with one open chat.
+func newTestModel(t *testing.T) (Model, *state.Store) {
+	t.Helper()
+	store := state.New(func() {})
+	store.EnsureChat(domain.ChatInfo{ID: 1, Title: "Ops"})
+	store.SetActiveChat(1)
+	m := NewModel(store, nil, nil, &config.Config{}, filepath.Join(t.TempDir(), "config.yaml"))
+	return m, store
+}
+
+func TestApplyRemoteDraft(t *testing.T) {
+	m, store := newTestModel(t)
+
+	// A draft from another device fills the untouched input and isn't
+	// pushed back on the way out.
+	store.OnDraft(1, "from phone")
+	m = m.applyRemoteDraft(1)
+	if got := m.input.Value(); got != "from phone" {
+		t.Fatalf("input = %q, want the remote draft", got)
+	}
+	if m.saveDraft(1) != nil {
+		t.Error("saveDraft pushed an unedited remote draft")
+	}
+
+	// Local edits win over a later remote draft.
+	m.input = m.input.SetValue("typed here")
+	store.OnDraft(1, "from laptop")
+	m = m.applyRemoteDraft(1)
+	if got := m.input.Value(); got != "typed here" {
+		t.Errorf("input = %q, want local edits kept", got)
+	}
+	if m.saveDraft(1) == nil {
+		t.Error("saveDraft didn't push local edits")
+	}
+}
+
+func TestLoadBotCommands_OnlyWithBots(t *testing.T) {
+	m, store := newTestModel(t)
+
+	people := []domain.Participant{{ID: 2, Name: "Ann"}, {ID: 3, Name: "Bob"}}
+	if m.loadBotCommands(1, people) != nil {
+		t.Error("bot commands requested for a chat without bots")
+	}
+	if m.loadBotCommands(1, nil) != nil {
+		t.Error("bot commands requested for a chat without known members")
+	}
+
+	withBot := append(people, domain.Participant{ID: 4, Name: "Helper", Bot: true})
+	if m.loadBotCommands(1, withBot) == nil {
+		t.Error("bot commands not requested for a chat with a bot")
+	}
+
+	store.SetBotCommands(1, nil)
+	if m.loadBotCommands(1, withBot) != nil {
+		t.Error("bot commands requested again once cached")
+	}
+}
+
+func TestButtonLinksNeedConfirmation(t *testing.T) {
+	m, _ := newTestModel(t)
+	m.alert = m.alert.SetSize(120, 40)
+
+	// A bot's answer with a link shows it rather than opening it.
+	next, cmd := m.Update(buttonAnsweredMsg{answer: telegram.CallbackAnswer{URL: "https://example.com/game"}})
+	m = next.(Model)
+	if cmd != nil {
+		t.Error("answer with a link returned a command before confirmation")
+	}
+	if !m.alert.IsVisible() || !strings.Contains(m.alert.View(), "https://example.com/game") {
+		t.Fatal("link isn't shown for confirmation")
+	}
+
+	// Esc dismisses it without opening anything.
+	m.alert, cmd = m.alert.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.alert.IsVisible() || cmd != nil {
+		t.Error("esc didn't cancel the link")
+	}
+
+	// URL buttons ask the same way, and Enter opens the link.
+	m, cmd = m.pressButton(pressButtonMsg{chatID: 1, button: domain.Button{Kind: domain.ButtonURL, Text: "Docs", URL: "https://example.com/docs"}})
+	if cmd != nil || !m.alert.IsVisible() {
+		t.Fatal("URL button didn't ask before opening")
+	}
+	m.alert, cmd = m.alert.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.alert.IsVisible() || cmd == nil {
+		t.Error("enter didn't open the link")
+	}
+}
+
+func TestServiceMessagesRefuseMessageActions(t *testing.T) {
+	m, _ := newTestModel(t)
+	m.messageView = m.messageView.SetSize(80, 20).SetMessages([]domain.Message{
+		{ID: 1, ChatID: 1, SenderName: "Ana", Text: "hi"},
+		{ID: 2, ChatID: 1, Text: "Ana joined", Kind: domain.MessageService},
+	}, nil)
+	m.focus = focusMessages
+
+	for _, tt := range []struct {
+		id   int
+		want bool
+	}{{1, true}, {2, false}} {
+		m.messageView, _ = m.messageView.SelectMessage(tt.id)
+		for _, key := range []string{"r", "f", "P"} {
+			if _, ok := m.actionForKey(key); ok != tt.want {
+				t.Errorf("message %d, key %q: available = %v, want %v", tt.id, key, ok, tt.want)
+			}
+		}
+	}
+}
+
+func TestLocalErrorKeepsConnection(t *testing.T) {
+	m, _ := newTestModel(t)
+	m.status.connected = true
+	next, _ := m.Update(localErrorMsg{err: errors.New("save recent chats: disk full")})
+	m = next.(Model)
+	if !m.status.connected {
+		t.Error("a local error marked the connection down")
+	}
+	if !strings.Contains(m.status.text, "disk full") {
+		t.Errorf("status = %q, want the error", m.status.text)
+	}
+}
+
+func TestHelpKeyClosesHelpFromInput(t *testing.T) {
+	m, _ := newTestModel(t)
+	m.focus = focusInput
+	m.help = m.help.Toggle()
+
+	m, _ = m.helpKey(tea.KeyPressMsg{Code: 'x', Text: "x"})
+	if !m.help.IsVisible() {
+		t.Fatal("an unbound key closed the help")
+	}
+	m, _ = m.helpKey(tea.KeyPressMsg{Code: 'h', Text: "h"})
+	if m.help.IsVisible() {
+		t.Error("h didn't close the help while the input has focus")
+	}
+}
+
+func TestHistoryLoadedKeepsJumpWindow(t *testing.T) {
+	m, store := newTestModel(t)
+	m.messageView = m.messageView.SetSize(80, 20)
+	msgs := func(ids ...int) []domain.Message {
+		out := make([]domain.Message, len(ids))
+		for i, id := range ids {
+			out[i] = domain.Message{ID: id, ChatID: 1, SenderName: "Ana", Text: "hi"}
+		}
+		return out
+	}
+
+	// Opening a global search hit fetches the latest history and a window
+	// around the hit at once; the window can arrive first.
+	next, _ := m.Update(historyWindowLoadedMsg{chatID: 1, msgs: msgs(10, 11, 12), target: jumpMsg{id: 11}})
+	m = next.(Model)
+	next, _ = m.Update(HistoryLoadedMsg{ChatID: 1, Messages: msgs(90, 91, 92)})
+	m = next.(Model)
+
+	if !m.messageView.HasMessage(11) || !m.messageView.HasMessage(92) {
+		t.Fatalf("messages = %v, want the window and the latest history", store.GetMessages(1))
+	}
+	if sel, ok := m.messageView.SelectedMessage(); !ok || sel.ID != 11 {
+		t.Errorf("selected = %v, want the jump target", sel.ID)
+	}
+	if got := store.GetGaps(1); len(got) != 1 || got[0] != 12 {
+		t.Errorf("gaps = %v, want [12]", got)
+	}
+}
+
+func TestStepSearchHighlightsAfterEsc(t *testing.T) {
+	m, _ := newTestModel(t)
+	hits := []domain.Message{
+		{ID: 1, ChatID: 1, SenderName: "Ana", Text: "deploy done"},
+		{ID: 2, ChatID: 1, SenderName: "Bea", Text: "deploy failed"},
+	}
+	m.messageView = m.messageView.SetSize(80, 20).SetMessages(hits, nil)
+
+	// Results were listed, then the overlay closed without opening one.
+	m.search = m.search.SetLocalResults("deploy", hits)
+	m, _ = m.stepSearch(1)
+	if !slices.Equal(m.messageView.highlight, []string{"deploy"}) {
+		t.Errorf("highlight = %q after n, want the search terms", m.messageView.highlight)
+	}
+	// The first n goes to the first hit rather than skipping it.
+	if sel, ok := m.messageView.SelectedMessage(); !ok || sel.ID != 1 {
+		t.Errorf("first n selected %+v, want message 1", sel)
+	}
+	m, _ = m.stepSearch(1)
+	if sel, ok := m.messageView.SelectedMessage(); !ok || sel.ID != 2 {
+		t.Errorf("second n selected %+v, want message 2", sel)
+	}
+
+	// Switching chats clears the search and its highlight.
+	next, _ := m.Update(ChatSelectedMsg{ChatID: 1})
+	m = next.(Model)
+	if len(m.messageView.highlight) != 0 {
+		t.Errorf("highlight = %q after reset, want none", m.messageView.highlight)
+	}
+}
+
+func TestRecentEmojiSavedOnQuit(t *testing.T) {
+	m, _ := newTestModel(t)
+	if m.saveRecentEmoji() != nil {
+		t.Error("quitting would save the config with no emoji picked")
+	}
+
+	next, cmd := m.Update(emojiUsedMsg{char: "🚀"})
+	m = next.(Model)
+	if cmd != nil {
+		t.Error("picking an emoji wrote the config")
+	}
+	if !m.emojiChanged {
+		t.Fatal("recent emoji not marked for saving")
+	}
+
+	if _, err := os.Stat(m.cfgPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("config written before quitting: %v", err)
+	}
+	save := m.saveRecentEmoji()
+	if save == nil {
+		t.Fatal("quitting wouldn't save the recent emoji")
+	}
+	if msg := save(); msg != nil {
+		t.Fatalf("save: %v", msg)
+	}
+	cfg, err := config.Load(m.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.RecentEmoji, []string{"🚀"}) {
+		t.Errorf("saved recent emoji = %q", cfg.RecentEmoji)
+	}
+}
@@ -13,13 +13,24 @@ import (
 
 // chatItem implements list.Item for the chat list.
 type chatItem struct {
-	chatID      int64
-	title       string
-	unreadCount int
-	lastMessage string
+	chatID         int64
+	title          string
+	username       string
+	unreadCount    int
+	unreadMentions int
+	lastMessage    string
+	draft          string
+	muted          bool
 }
 
-func (i chatItem) FilterValue() string { return i.title }
+// FilterValue lets the / filter match a chat's @username as well as its
+// title.
+func (i chatItem) FilterValue() string {
+	if i.username == "" {
+		return i.title
+	}
+	return i.title + " @" + i.username
+}
 
 // chatItemDelegate renders a chatItem in the list.
 type chatItemDelegate struct{}
@@ -49,27 +60,48 @@ func (d chatItemDelegate) Render(w io.Writer, m list.Model, index int, item list
 	}
 
 	titleStyle := lipgloss.NewStyle().MaxWidth(contentWidth).MaxHeight(1)
-	descStyle := lipgloss.NewStyle().MaxWidth(contentWidth).MaxHeight(1).Foreground(lipgloss.Color("240"))
+	descStyle := lipgloss.NewStyle().MaxWidth(contentWidth).MaxHeight(1).Foreground(dimColor)
 
 	cursor := "  "
 	if isSelected {
 		cursor = "> "
-		titleStyle = titleStyle.Foreground(lipgloss.Color("#8C6161")).Bold(true)
-		descStyle = descStyle.Foreground(lipgloss.Color("250"))
+		titleStyle = titleStyle.Inherit(listSelectedStyle)
+		descStyle = descStyle.Foreground(subtleColor)
 	}
 	if ci.unreadCount > 0 {
 		titleStyle = titleStyle.Bold(true)
 	}
+	// Muted chats are dimmed so they recede behind chats that need attention.
+	if ci.muted {
+		titleStyle = titleStyle.Foreground(dimColor).Bold(false).Faint(monochrome)
+		descStyle = descStyle.Foreground(dimColor).Faint(monochrome)
+	}
 
-	fmt.Fprintf(w, "%s%s\n%s%s", cursor, titleStyle.Render(title), "  ", descStyle.Render(desc))
+	// Unread mentions get an "@" badge that survives title truncation.
+	badge := ""
+	if ci.unreadMentions > 0 {
+		badge = mentionBadgeStyle.Render(" @")
+		titleStyle = titleStyle.MaxWidth(max(contentWidth-2, 1))
+	}
+
+	// A draft replaces the last message preview, like in other clients.
+	preview := descStyle.Render(desc)
+	if ci.draft != "" {
+		label := draftLabelStyle.Render("Draft: ")
+		preview = label + descStyle.MaxWidth(max(contentWidth-lipgloss.Width(label), 1)).Render(ci.draft)
+	}
+
+	fmt.Fprintf(w, "%s%s%s\n%s%s", cursor, titleStyle.Render(title), badge, "  ", preview)
 }
 
 // ChatListModel wraps bubbles/list for the chat sidebar.
 type ChatListModel struct {
-	list    list.Model
-	focused bool
-	width   int
-	height  int
+	list        list.Model
+	focused     bool
+	width       int
+	height      int
+	totalUnread int
+	title       string
 }
 
 func NewChatListModel() ChatListModel {
@@ -81,7 +113,7 @@ func NewChatListModel() ChatListModel {
 	l.SetFilteringEnabled(true)
 	l.DisableQuitKeybindings()
 
-	return ChatListModel{list: l}
+	return ChatListModel{list: l, title: "Chats"}
 }
 
 func (m ChatListModel) Update(msg tea.Msg) (ChatListModel, tea.Cmd) {
@@ -110,8 +142,15 @@ func (m ChatListModel) View() string {
 		contentH = 0
 	}
 
+	// Header line with the unread total, then the list below it.
+	header := chatListHeaderStyle.Render(m.title)
+	if m.totalUnread > 0 {
+		header += timeStyle.Render(fmt.Sprintf(" · %d unread", m.totalUnread))
+	}
+	header = lipgloss.NewStyle().MaxWidth(m.width - 2).Render(header)
+
 	// Truncate list output to content area inside border
-	content := truncateHeight(m.list.View(), contentH)
+	content := truncateHeight(header+"\n"+m.list.View(), contentH)
 
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -126,10 +165,14 @@ func (m ChatListModel) WithItems(chats []domain.ChatInfo) ChatListModel {
 	items := make([]list.Item, len(chats))
 	for i, c := range chats {
 		items[i] = chatItem{
-			chatID:      c.ID,
-			title:       c.Title,
-			unreadCount: c.UnreadCount,
-			lastMessage: c.LastMessage,
+			chatID:         c.ID,
+			title:          c.Title,
+			username:       c.Username,
+			unreadCount:    c.UnreadCount,
+			unreadMentions: c.UnreadMentions,
+			lastMessage:    c.LastMessage,
+			draft:          c.Draft,
+			muted:          c.Muted(),
 		}
 	}
 	m.list.SetItems(items)
@@ -140,7 +183,7 @@ func (m ChatListModel) SetSize(w, h int) ChatListModel {
 	m.width = w
 	m.height = h
 	innerW := w - 2
-	innerH := h - 2
+	innerH := h - 3 // border plus the header line
 	if innerW < 1 {
 		innerW = 1
 	}
@@ -151,10 +194,43 @@ func (m ChatListModel) SetSize(w, h int) ChatListModel {
 	return m
 }
 
+// SetTotalUnread sets the unread total shown in the header. Callers are
+// expected to exclude muted chats.
+func (m ChatListModel) SetTotalUnread(n int) ChatListModel {
+	m.totalUnread = n
+	return m
+}
+
+// SelectedChatID returns the chat under the cursor, or 0 if the list is empty.
+func (m ChatListModel) SelectedChatID() int64 {
+	if item, ok := m.list.SelectedItem().(chatItem); ok {
+		return item.chatID
+	}
+	return 0
+}
+
 func (m ChatListModel) IsFiltering() bool {
 	return m.list.FilterState() == list.Filtering
 }
 
+// IsFiltered reports whether a filter is being typed or has been applied.
+func (m ChatListModel) IsFiltered() bool {
+	return m.list.FilterState() != list.Unfiltered
+}
+
+// ResetFilter clears any filter and moves the cursor to the top.
+func (m ChatListModel) ResetFilter() ChatListModel {
+	m.list.ResetFilter()
+	m.list.Select(0)
+	return m
+}
+
+// SetTitle replaces the "Chats" header, for reuse of the list as a picker.
+func (m ChatListModel) SetTitle(title string) ChatListModel {
+	m.title = title
+	return m
+}
+
 func (m ChatListModel) SetFocused(f bool) ChatListModel {
 	m.focused = f
 	return m
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/emoji"
+)
+
+// maxCompletions caps how many candidates the popup shows.
+const maxCompletions = 6
+
+// completionItem is a candidate in the input's completion popup.
+type completionItem struct {
+	label  string // shown in the popup
+	detail string // dimmed text after the label
+	insert string // replaces the token being completed
+	userID int64  // non-zero for mentions sent as MessageEntityMentionName
+	emoji  string // set for emoji, to track recent use
+}
+
+// completionPopup lists candidates for the token being typed at the end of
+// the input, such as "@al".
+type completionPopup struct {
+	token  string
+	items  []completionItem
+	cursor int
+}
+
+func (p completionPopup) visible() bool {
+	return len(p.items) > 0
+}
+
+// move shifts the selection by delta, wrapping at either end.
+func (p completionPopup) move(delta int) completionPopup {
+	if len(p.items) == 0 {
+		return p
+	}
+	p.cursor = (p.cursor + delta + len(p.items)) % len(p.items)
+	return p
+}
+
+func (p completionPopup) selected() completionItem {
+	return p.items[p.cursor]
+}
+
+// View renders the popup box, at most maxWidth columns wide.
+func (p completionPopup) View(maxWidth int) string {
+	if !p.visible() {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, item := range p.items {
+		if i == p.cursor {
+			b.WriteString("> " + listSelectedStyle.Render(item.label))
+		} else {
+			b.WriteString("  " + item.label)
+		}
+		if item.detail != "" {
+			b.WriteString(" " + timeStyle.Render(item.detail))
+		}
+		if i < len(p.items)-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForegroundBlend(rainbowBlend...).
+		Padding(0, 1).
+		MaxWidth(maxWidth).
+		Render(b.String())
+}
+
+// mentionCompletions returns the participants matching query (the text
+// after "@"). Username prefix matches rank above display-name matches.
+// Users with a username are inserted as "@username"; the rest are inserted
+// by name and linked to their ID so the mention still notifies them.
+func mentionCompletions(ps []domain.Participant, query string) []completionItem {
+	q := strings.ToLower(query)
+
+	type scored struct {
+		p     domain.Participant
+		score int
+	}
+	var matches []scored
+	for _, p := range ps {
+		switch {
+		case p.Username != "" && strings.HasPrefix(strings.ToLower(p.Username), q):
+			matches = append(matches, scored{p, 0})
+		case nameHasPrefix(p.Name, q):
+			matches = append(matches, scored{p, 1})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score < matches[j].score
+	})
+	if len(matches) > maxCompletions {
+		matches = matches[:maxCompletions]
+	}
+
+	items := make([]completionItem, len(matches))
+	for i, m := range matches {
+		if m.p.Username != "" {
+			items[i] = completionItem{
+				label:  m.p.Name,
+				detail: "@" + m.p.Username,
+				insert: "@" + m.p.Username + " ",
+			}
+		} else {
+			items[i] = completionItem{
+				label:  m.p.Name,
+				insert: m.p.Name + " ",
+				userID: m.p.ID,
+			}
+		}
+	}
+	return items
+}
+
+// nameHasPrefix reports whether the name, or any word in it, starts with
+// the lowercase prefix q.
+func nameHasPrefix(name, q string) bool {
+	lower := strings.ToLower(name)
+	if strings.HasPrefix(lower, q) {
+		return true
+	}
+	for _, word := range strings.Fields(lower) {
+		if strings.HasPrefix(word, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// emojiCompletions returns emoji whose shortcode matches query (the text
+// after ":"). Recently used emoji rank first.
+func emojiCompletions(recent []string, query string) []completionItem {
+	matches := emoji.Search(query, 0)
+	rank := make(map[string]int, len(recent))
+	for i, r := range recent {
+		rank[r] = i + 1
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		ri, rj := rank[matches[i].Char], rank[matches[j].Char]
+		if ri == 0 || rj == 0 {
+			return ri > rj
+		}
+		return ri < rj
+	})
+	if len(matches) > maxCompletions {
+		matches = matches[:maxCompletions]
+	}
+
+	items := make([]completionItem, len(matches))
+	for i, e := range matches {
+		items[i] = completionItem{
+			label:  e.Char + " :" + e.Code + ":",
+			insert: e.Char,
+			emoji:  e.Char,
+		}
+	}
+	return items
+}
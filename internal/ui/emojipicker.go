@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/emoji"
+)
+
+const (
+	emojiPickerCols = 10
+	emojiPickerRows = 5
+)
+
+// EmojiPickerModel renders a centered, searchable emoji grid. With an empty
+// query it shows recently used emoji followed by the rest of the table.
+type EmojiPickerModel struct {
+	visible       bool
+	search        textinput.Model
+	recent        []string
+	results       []emoji.Emoji
+	cursor        int
+	width, height int
+}
+
+// NewEmojiPickerModel creates a hidden emoji picker.
+func NewEmojiPickerModel() EmojiPickerModel {
+	ti := textinput.New()
+	ti.Placeholder = "search shortcodes"
+	ti.Prompt = ": "
+	ti.CharLimit = 32
+	return EmojiPickerModel{search: ti}
+}
+
+// IsVisible reports whether the picker is showing.
+func (m EmojiPickerModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the picker with an empty search.
+func (m EmojiPickerModel) Show(recent []string) (EmojiPickerModel, tea.Cmd) {
+	m.visible = true
+	m.recent = recent
+	m.search.SetValue("")
+	m = m.refresh()
+	return m, m.search.Focus()
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m EmojiPickerModel) SetSize(w, h int) EmojiPickerModel {
+	m.width = w
+	m.height = h
+	return m
+}
+
+func (m EmojiPickerModel) Update(msg tea.Msg) (EmojiPickerModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.visible = false
+			m.search.Blur()
+			return m, nil
+		case "left":
+			m = m.moveCursor(-1)
+			return m, nil
+		case "right":
+			m = m.moveCursor(1)
+			return m, nil
+		case "up":
+			m = m.moveCursor(-emojiPickerCols)
+			return m, nil
+		case "down":
+			m = m.moveCursor(emojiPickerCols)
+			return m, nil
+		case "enter":
+			if len(m.results) == 0 {
+				return m, nil
+			}
+			char := m.results[m.cursor].Char
+			m.visible = false
+			m.search.Blur()
+			return m, func() tea.Msg { return emojiPickedMsg{char: char} }
+		}
+	}
+
+	prev := m.search.Value()
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	if m.search.Value() != prev {
+		m = m.refresh()
+	}
+	return m, cmd
+}
+
+func (m EmojiPickerModel) moveCursor(delta int) EmojiPickerModel {
+	next := m.cursor + delta
+	if next >= 0 && next < len(m.results) && next < emojiPickerCols*emojiPickerRows {
+		m.cursor = next
+	}
+	return m
+}
+
+// refresh recomputes the results for the current query.
+func (m EmojiPickerModel) refresh() EmojiPickerModel {
+	limit := emojiPickerCols * emojiPickerRows
+	query := strings.Trim(m.search.Value(), ":")
+	m.cursor = 0
+
+	if query != "" {
+		m.results = emoji.Search(query, limit)
+		return m
+	}
+
+	m.results = nil
+	seen := make(map[string]bool)
+	for _, r := range m.recent {
+		m.results = append(m.results, emoji.Emoji{Code: emoji.CodeFor(r), Char: r})
+		seen[r] = true
+	}
+	for _, e := range emoji.Search("", 0) {
+		if len(m.results) >= limit {
+			break
+		}
+		if !seen[e.Char] {
+			m.results = append(m.results, e)
+		}
+	}
+	return m
+}
+
+// View renders the picker box (without full-screen placement).
+func (m EmojiPickerModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(chatListHeaderStyle.Render("Emoji") + "\n\n")
+	b.WriteString(m.search.View() + "\n\n")
+
+	selected := lipgloss.NewStyle().Reverse(true)
+	for row := 0; row < emojiPickerRows; row++ {
+		for col := 0; col < emojiPickerCols; col++ {
+			i := row*emojiPickerCols + col
+			cell := "  "
+			if i < len(m.results) {
+				cell = m.results[i].Char
+			}
+			if i == m.cursor && i < len(m.results) {
+				cell = selected.Render(cell)
+			}
+			b.WriteString(cell + " ")
+		}
+		b.WriteString("\n")
+	}
+
+	label := "no matches"
+	if len(m.results) > 0 {
+		if code := m.results[m.cursor].Code; code != "" {
+			label = ":" + code + ":"
+		} else {
+			label = m.results[m.cursor].Char
+		}
+	}
+	b.WriteString("\n" + timeStyle.Render(label))
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 3).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(b.String())
+}
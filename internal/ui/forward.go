@@ -0,0 +1,92 @@
+package ui
+
+import (
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// ForwardPickerModel renders a centered chat list for choosing where to
+// forward a message. It reuses ChatListModel, so "/" filters by title.
+type ForwardPickerModel struct {
+	visible       bool
+	fromChatID    int64
+	msgID         int
+	chats         ChatListModel
+	width, height int
+}
+
+// NewForwardPickerModel creates a hidden forward picker.
+func NewForwardPickerModel() ForwardPickerModel {
+	chats := NewChatListModel().SetTitle("Forward to").SetFocused(true)
+	return ForwardPickerModel{chats: chats}
+}
+
+// IsVisible reports whether the picker is showing.
+func (m ForwardPickerModel) IsVisible() bool {
+	return m.visible
+}
+
+// IsFiltering reports whether the user is typing a filter, during which
+// every key belongs to the picker.
+func (m ForwardPickerModel) IsFiltering() bool {
+	return m.chats.IsFiltering()
+}
+
+// Show opens the picker for a message with the given destination chats.
+func (m ForwardPickerModel) Show(msg domain.Message, chats []domain.ChatInfo) ForwardPickerModel {
+	m.visible = true
+	m.fromChatID = msg.ChatID
+	m.msgID = msg.ID
+	m.chats = m.chats.WithItems(chats).ResetFilter()
+	return m
+}
+
+// SetSize updates the terminal dimensions; the picker takes about two
+// thirds of the screen.
+func (m ForwardPickerModel) SetSize(w, h int) ForwardPickerModel {
+	m.width = w
+	m.height = h
+	m.chats = m.chats.SetSize(min(50, w-4), max(h*2/3, 6))
+	return m
+}
+
+func (m ForwardPickerModel) Update(msg tea.Msg) (ForwardPickerModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && !m.chats.IsFiltering() {
+		switch key.String() {
+		case "esc":
+			// The first Esc clears an applied filter, the next closes.
+			if m.chats.IsFiltered() {
+				m.chats = m.chats.ResetFilter()
+			} else {
+				m.visible = false
+			}
+			return m, nil
+		case "q":
+			m.visible = false
+			return m, nil
+		case "enter":
+			toChatID := m.chats.SelectedChatID()
+			if toChatID == 0 {
+				return m, nil
+			}
+			fromChatID, msgID := m.fromChatID, m.msgID
+			m.visible = false
+			return m, func() tea.Msg {
+				return forwardMsg{fromChatID: fromChatID, msgIDs: []int{msgID}, toChatID: toChatID}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.chats, cmd = m.chats.Update(msg)
+	return m, cmd
+}
+
+// View renders the picker box (without full-screen placement).
+func (m ForwardPickerModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+	return m.chats.View()
+}
@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/telegram"
+)
+
+// globalSearchLines is how many lines of results the global search
+// overlay shows at once.
+const globalSearchLines = 16
+
+// globalResult is one selectable row of global search results: a chat,
+// or a message within a chat.
+type globalResult struct {
+	chat domain.ChatInfo
+	msg  *domain.Message
+}
+
+// GlobalSearchModel renders a centered overlay searching chat names,
+// public usernames and messages across all chats, grouped by kind.
+type GlobalSearchModel struct {
+	visible   bool
+	input     textinput.Model
+	query     string
+	results   *telegram.GlobalSearchResults
+	rows      []globalResult
+	cursor    int
+	searching bool
+	err       error
+
+	width, height int
+}
+
+// NewGlobalSearchModel creates a hidden global search overlay.
+func NewGlobalSearchModel() GlobalSearchModel {
+	ti := textinput.New()
+	ti.Placeholder = "search all chats and usernames"
+	ti.Prompt = "? "
+	ti.CharLimit = 128
+	return GlobalSearchModel{input: ti}
+}
+
+// IsVisible reports whether the overlay is showing.
+func (m GlobalSearchModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the overlay, keeping the previous query and results.
+func (m GlobalSearchModel) Show() (GlobalSearchModel, tea.Cmd) {
+	m.visible = true
+	return m, m.input.Focus()
+}
+
+// SetResults shows the results of a search.
+func (m GlobalSearchModel) SetResults(query string, res telegram.GlobalSearchResults, err error) GlobalSearchModel {
+	m.searching = false
+	m.query = query
+	m.err = err
+	m.results = &res
+	m.cursor = 0
+	m.rows = nil
+	for _, c := range res.Chats {
+		m.rows = append(m.rows, globalResult{chat: c})
+	}
+	for _, c := range res.Public {
+		m.rows = append(m.rows, globalResult{chat: c})
+	}
+	for i := range res.Messages {
+		msg := &res.Messages[i]
+		chat := domain.ChatInfo{ID: msg.ChatID, Title: res.Titles[msg.ChatID]}
+		m.rows = append(m.rows, globalResult{chat: chat, msg: msg})
+	}
+	return m
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m GlobalSearchModel) SetSize(w, h int) GlobalSearchModel {
+	m.width = w
+	m.height = h
+	m.input.SetWidth(m.boxWidth() - 4)
+	return m
+}
+
+// boxWidth is the width of the overlay's content.
+func (m GlobalSearchModel) boxWidth() int {
+	return max(min(70, m.width-10), 20)
+}
+
+func (m GlobalSearchModel) Update(msg tea.Msg) (GlobalSearchModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.visible = false
+			m.input.Blur()
+			return m, nil
+		case "up", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.cursor < len(m.rows)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case "enter":
+			query := strings.TrimSpace(m.input.Value())
+			// A changed query runs a new search; otherwise Enter opens
+			// the highlighted result.
+			if query != m.query || m.results == nil {
+				if query == "" {
+					return m, nil
+				}
+				m.searching = true
+				return m, func() tea.Msg { return globalSearchMsg{query: query} }
+			}
+			if len(m.rows) == 0 {
+				return m, nil
+			}
+			row := m.rows[m.cursor]
+			m.visible = false
+			m.input.Blur()
+			return m, func() tea.Msg {
+				open := openResultMsg{chat: row.chat}
+				if row.msg != nil {
+					open.msgID = row.msg.ID
+				}
+				return open
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// View renders the overlay box (without full-screen placement).
+func (m GlobalSearchModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+	w := m.boxWidth()
+
+	var b strings.Builder
+	b.WriteString(chatListHeaderStyle.Render("Search everywhere"))
+	b.WriteString("\n\n" + m.input.View() + "\n\n")
+
+	switch {
+	case m.searching:
+		b.WriteString(timeStyle.Render("Searching…"))
+	case m.err != nil:
+		b.WriteString(timeStyle.Render("Search failed: " + m.err.Error()))
+	case m.results == nil:
+		b.WriteString(timeStyle.Render("Enter to search"))
+	case len(m.rows) == 0:
+		b.WriteString(timeStyle.Render("No results"))
+	default:
+		lines, cursorLine := m.resultLines(w)
+		start := max(0, min(cursorLine-globalSearchLines/2, len(lines)-globalSearchLines))
+		end := min(start+globalSearchLines, len(lines))
+		b.WriteString(strings.Join(lines[start:end], "\n"))
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 2).
+		Width(w + 6).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(b.String())
+}
+
+// resultLines renders the results under group headings and returns the
+// line the cursor is on.
+func (m GlobalSearchModel) resultLines(w int) ([]string, int) {
+	clamp := lipgloss.NewStyle().MaxWidth(w).MaxHeight(1)
+	var lines []string
+	cursorLine := 0
+	heading := ""
+	for i, row := range m.rows {
+		group := "Chats"
+		switch {
+		case row.msg != nil:
+			group = "Messages"
+		case i >= len(m.results.Chats):
+			group = "Public"
+		}
+		if group != heading {
+			if heading != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, chatListHeaderStyle.Render(group))
+			heading = group
+		}
+
+		cursor := "  "
+		titleStyle := lipgloss.NewStyle()
+		if i == m.cursor {
+			cursor = "> "
+			titleStyle = titleStyle.Inherit(listSelectedStyle)
+			cursorLine = len(lines)
+		}
+		if row.msg == nil {
+			lines = append(lines, clamp.Render(cursor+titleStyle.Render(row.chat.Title)))
+			continue
+		}
+		header := cursor + titleStyle.Render(row.chat.Title) + timeStyle.Render(" · ") +
+			inNameStyle.Render(row.msg.SenderName) + " " + timeStyle.Render(row.msg.Timestamp.Format("Jan 2 15:04"))
+		text := snippet(row.msg.Text, m.query, snippetContext)
+		if text == "" {
+			text = "(media)"
+		}
+		lines = append(lines, clamp.Render(header), clamp.Render("  "+highlightMatches(text, m.query)))
+	}
+	return lines, cursorLine
+}
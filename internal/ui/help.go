@@ -1,16 +1,23 @@
 package ui
 
-import "charm.land/lipgloss/v2"
+import (
+	"fmt"
+	"strings"
 
-// HelpModel renders a centered help overlay listing keyboard shortcuts.
+	"charm.land/lipgloss/v2"
+)
+
+// HelpModel renders a centered help overlay listing keyboard shortcuts,
+// generated from the action registry.
 type HelpModel struct {
 	visible       bool
+	text          string
 	width, height int
 }
 
-// NewHelpModel creates a hidden help model.
-func NewHelpModel() HelpModel {
-	return HelpModel{}
+// NewHelpModel creates a hidden help model describing the given actions.
+func NewHelpModel(actions []action) HelpModel {
+	return HelpModel{text: helpText(actions)}
 }
 
 // IsVisible reports whether the help overlay is showing.
@@ -31,34 +38,54 @@ func (h HelpModel) SetSize(w, ht int) HelpModel {
 	return h
 }
 
-const helpText = ` Keyboard Shortcuts
-
- General
-   Ctrl+C        Quit
-   h / F1        Toggle this help
-   Tab           Next pane
-   Shift+Tab     Previous pane
-   Esc           Back to chat list
-   Ctrl+B        Toggle sidebar
-   [ / ]         Resize sidebar
-
- Chat List
-   j/k / ↑/↓     Navigate chats
-   Enter         Select chat
-   /             Filter chats
+// helpText lays out the bound actions by group in two columns, with the
+// long list of message keys on the right.
+func helpText(actions []action) string {
+	keyWidth := 0
+	for _, a := range actions {
+		keyWidth = max(keyWidth, lipgloss.Width(keysLabel(a.keys)))
+	}
 
- Messages
-   j / k         Scroll down / up
-   PgUp / PgDn   Page scroll
-   b             Toggle speech bubbles
+	sections := make(map[string][]string)
+	closeKeys, paletteHint := "Esc", ""
+	for _, a := range actions {
+		switch {
+		case len(a.keys) == 0:
+		case a.name == "help":
+			closeKeys = keysLabel(a.keys) + " or Esc"
+		case a.name == "command-palette":
+			paletteHint = " · " + keyLabel(a.keys[0]) + " for all commands"
+		}
+		if len(a.keys) == 0 {
+			continue // palette only
+		}
+		label := keysLabel(a.keys)
+		pad := strings.Repeat(" ", keyWidth-lipgloss.Width(label))
+		sections[a.group] = append(sections[a.group], fmt.Sprintf("   %s%s  %s", label, pad, a.title))
+	}
 
- Input
-   Enter         Send message
+	column := func(groups ...string) string {
+		var lines []string
+		for _, g := range groups {
+			if len(sections[g]) == 0 {
+				continue
+			}
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, " "+g)
+			lines = append(lines, sections[g]...)
+		}
+		return strings.Join(lines, "\n")
+	}
+	left := column(groupGeneral, groupChatList, groupInput)
+	right := column(groupMessages)
+	body := lipgloss.JoinHorizontal(lipgloss.Top, left, "    ", right)
 
- Press h, F1, or Esc to close`
+	return " Keyboard Shortcuts\n\n" + body + "\n\n Press " + closeKeys + " to close" + paletteHint
+}
 
 // View renders the help box (without full-screen placement).
-// Use BoxOffset to get the X/Y for centering via the Layer API.
 func (h HelpModel) View() string {
 	if !h.visible || h.width == 0 || h.height == 0 {
 		return ""
@@ -69,22 +96,5 @@ func (h HelpModel) View() string {
 		Padding(1, 3).
 		BorderForegroundBlend(rainbowBlend...)
 
-	return style.Render(helpText)
-}
-
-// BoxOffset returns the (x, y) needed to center the help box
-// within the terminal dimensions.
-func (h HelpModel) BoxOffset() (int, int) {
-	box := h.View()
-	bw := lipgloss.Width(box)
-	bh := lipgloss.Height(box)
-	x := (h.width - bw) / 2
-	y := (h.height - bh) / 2
-	if x < 0 {
-		x = 0
-	}
-	if y < 0 {
-		y = 0
-	}
-	return x, y
+	return style.Render(h.text)
 }
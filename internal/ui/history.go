@@ -0,0 +1,185 @@
+package ui
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/config"
+)
+
+// historyBrowse tracks stepping through the open chat's sent messages
+// with Ctrl+P and Ctrl+N.
+type historyBrowse struct {
+	active bool
+	pos    int    // index into the chat's entries
+	saved  string // the text being composed before browsing started
+}
+
+// historySearch is a reverse incremental search through the messages sent
+// to any chat, like Ctrl+R in a shell.
+type historySearch struct {
+	active  bool
+	query   string
+	entries []string // snapshot of the history, oldest first
+	pos     int      // index of the current match, or len(entries)
+	failed  bool     // the query matches nothing older
+	saved   string   // the text being composed before the search
+}
+
+// SetHistory sets the sent messages to recall from.
+func (m InputModel) SetHistory(h *config.History) InputModel {
+	m.history = h
+	return m
+}
+
+// SetChat sets the chat whose sent messages Ctrl+P and Ctrl+N recall.
+func (m InputModel) SetChat(chatID int64) InputModel {
+	m.chatID = chatID
+	m.browse = historyBrowse{}
+	return m
+}
+
+// HistoryPrev replaces the input with the previous message sent to the
+// chat, remembering what was being typed.
+func (m InputModel) HistoryPrev() InputModel {
+	if m.history == nil {
+		return m
+	}
+	entries := m.history.Entries(m.chatID)
+	if !m.browse.active {
+		m.browse = historyBrowse{active: true, pos: len(entries), saved: m.textarea.Value()}
+	}
+	if m.browse.pos == 0 || m.browse.pos > len(entries) {
+		return m
+	}
+	m.browse.pos--
+	return m.recall(entries[m.browse.pos])
+}
+
+// HistoryNext steps forward through the chat's sent messages, back to the
+// text that was being typed.
+func (m InputModel) HistoryNext() InputModel {
+	if !m.browse.active || m.history == nil {
+		return m
+	}
+	entries := m.history.Entries(m.chatID)
+	m.browse.pos++
+	if m.browse.pos >= len(entries) {
+		saved := m.browse.saved
+		m.browse = historyBrowse{}
+		return m.recall(saved)
+	}
+	return m.recall(entries[m.browse.pos])
+}
+
+// recall shows text in the input without ending a history walk.
+func (m InputModel) recall(text string) InputModel {
+	browse := m.browse
+	m = m.SetValue(text)
+	m.browse = browse
+	return m
+}
+
+// IsSearchingHistory reports whether a reverse history search is running
+// and should receive every key.
+func (m InputModel) IsSearchingHistory() bool {
+	return m.search.active
+}
+
+// SearchHistory starts a reverse search of the messages sent to any chat,
+// or, while one is running, moves to the next older match.
+func (m InputModel) SearchHistory() InputModel {
+	if m.history == nil {
+		return m
+	}
+	if !m.search.active {
+		entries := m.history.Entries(0)
+		m.search = historySearch{
+			active:  true,
+			entries: entries,
+			pos:     len(entries),
+			saved:   m.textarea.Value(),
+		}
+		return m
+	}
+	return m.findHistory(m.search.pos-1, true)
+}
+
+// findHistory shows the newest entry at or before index from that
+// contains the query. With skipSame, entries equal to the current match
+// are passed over, so repeats across chats aren't stepped through twice.
+func (m InputModel) findHistory(from int, skipSame bool) InputModel {
+	query := strings.ToLower(m.search.query)
+	current := ""
+	if m.search.pos < len(m.search.entries) {
+		current = m.search.entries[m.search.pos]
+	}
+	for i := min(from, len(m.search.entries)-1); i >= 0; i-- {
+		e := m.search.entries[i]
+		if skipSame && e == current {
+			continue
+		}
+		if strings.Contains(strings.ToLower(e), query) {
+			m.search.pos = i
+			m.search.failed = false
+			m.textarea.SetValue(e)
+			return m
+		}
+	}
+	m.search.failed = true
+	return m
+}
+
+// updateSearch handles a key while the history search runs. Keys that
+// don't drive the search accept the match and are handled as usual.
+func (m InputModel) updateSearch(msg tea.KeyMsg) (InputModel, bool) {
+	switch msg.String() {
+	case "ctrl+r":
+		return m.SearchHistory(), true
+	case "esc", "ctrl+g":
+		saved := m.search.saved
+		m.search = historySearch{}
+		return m.SetValue(saved), true
+	case "enter", "tab":
+		m.search = historySearch{}
+		m.textarea.CursorEnd()
+		return m, true
+	case "backspace":
+		q := []rune(m.search.query)
+		if len(q) > 0 {
+			m.search.query = string(q[:len(q)-1])
+			m.search.pos = len(m.search.entries)
+			m = m.findHistory(len(m.search.entries)-1, false)
+		}
+		return m, true
+	}
+
+	key := msg.Key()
+	if key.Text != "" && key.Mod&^tea.ModShift == 0 {
+		m.search.query += key.Text
+		m = m.findHistory(len(m.search.entries)-1, false)
+		return m, true
+	}
+
+	m.search = historySearch{}
+	return m, false
+}
+
+// searchView renders the history search prompt shown above the input.
+func (m InputModel) searchView() string {
+	label := "history search"
+	if m.search.failed {
+		label = "failing history search"
+	}
+	line := timeStyle.Render(label+": ") + m.search.query + "▏"
+	hint := timeStyle.Render("Ctrl+R older · Enter use · Esc cancel")
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForegroundBlend(rainbowBlend...).
+		Padding(0, 1).
+		MaxWidth(m.width).
+		Render(line + "\n" + hint)
+}
@@ -0,0 +1,233 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/telegram"
+)
+
+// inlineQueryDelay is how long typing must pause before an inline query
+// is sent, so the bot isn't asked on every keystroke.
+const inlineQueryDelay = 400 * time.Millisecond
+
+// maxInlineRows is how many results the overlay shows at once.
+const maxInlineRows = 8
+
+// inlineQueryRe matches a message starting with a bot's username and a
+// space, as in "@gif cats". Usernames are up to 32 characters; some of
+// Telegram's own bots have just 3.
+var inlineQueryRe = regexp.MustCompile(`^@([A-Za-z][A-Za-z0-9_]{2,31}) (.*)$`)
+
+// parseInlineQuery splits input like "@gif cats" into the bot's username
+// and the query. As in other Telegram apps, the space after the username
+// starts the query, which may be empty.
+func parseInlineQuery(text string) (bot, query string, ok bool) {
+	m := inlineQueryRe.FindStringSubmatch(text)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// checkInlineQuery notices when the text starts or changes an inline bot
+// query and reports it once typing pauses. Leaving a query is reported
+// straight away, so its results close.
+func (m InputModel) checkInlineQuery() (InputModel, tea.Cmd) {
+	bot, query, _ := parseInlineQuery(m.textarea.Value())
+	if bot == m.inlineBot && query == m.inlineQuery {
+		return m, nil
+	}
+	m.inlineBot, m.inlineQuery = bot, query
+	msg := inlineQueryMsg{bot: bot, query: query}
+	if bot == "" {
+		return m, func() tea.Msg { return msg }
+	}
+	return m, tea.Tick(inlineQueryDelay, func(time.Time) tea.Msg { return msg })
+}
+
+// InlineQuery returns the inline bot query being typed, if any.
+func (m InputModel) InlineQuery() (bot, query string, ok bool) {
+	return m.inlineBot, m.inlineQuery, m.inlineBot != ""
+}
+
+// InlineResultsModel lists an inline bot's results above the input while
+// the query is typed. Up and down choose a result and Enter sends it.
+type InlineResultsModel struct {
+	visible bool
+	chatID  int64
+	bot     string
+	query   string
+	results telegram.InlineResults
+	cursor  int
+	top     int // first result in view
+	loading bool
+	width   int
+	// dismissed is the query Esc closed the overlay on; its results stay
+	// hidden until the query changes.
+	dismissed string
+}
+
+// NewInlineResultsModel creates a hidden overlay.
+func NewInlineResultsModel() InlineResultsModel {
+	return InlineResultsModel{}
+}
+
+// IsVisible reports whether the overlay is showing.
+func (m InlineResultsModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show lists the results of a query, replacing any earlier ones. Nothing
+// shows for a query without results or one closed with Esc.
+func (m InlineResultsModel) Show(chatID int64, bot, query string, results telegram.InlineResults) InlineResultsModel {
+	key := bot + " " + query
+	if m.dismissed != key {
+		m.dismissed = ""
+	}
+	m.chatID, m.bot, m.query = chatID, bot, query
+	m.results = results
+	m.cursor, m.top = 0, 0
+	m.loading = false
+	m.visible = len(results.Results) > 0 && m.dismissed == ""
+	return m
+}
+
+// Append adds a further page of results for the query on show.
+func (m InlineResultsModel) Append(results telegram.InlineResults) InlineResultsModel {
+	m.results.Results = append(m.results.Results, results.Results...)
+	m.results.NextOffset = results.NextOffset
+	m.loading = false
+	return m
+}
+
+// Hide closes the overlay.
+func (m InlineResultsModel) Hide() InlineResultsModel {
+	m.visible = false
+	m.loading = false
+	return m
+}
+
+// NextPage returns the offset to fetch more results from and marks the
+// page as loading. It reports false when there are no more or
+// a page is already on its way.
+func (m InlineResultsModel) NextPage() (InlineResultsModel, string, bool) {
+	if !m.visible || m.loading || m.results.NextOffset == "" {
+		return m, "", false
+	}
+	m.loading = true
+	return m, m.results.NextOffset, true
+}
+
+// SetWidth sets the width available above the input.
+func (m InlineResultsModel) SetWidth(w int) InlineResultsModel {
+	m.width = w
+	return m
+}
+
+func (m InlineResultsModel) Update(msg tea.Msg) (InlineResultsModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "up", "ctrl+p":
+		m = m.move(-1)
+	case "down", "ctrl+n":
+		m = m.move(1)
+		if m.cursor == len(m.results.Results)-1 {
+			return m, func() tea.Msg { return inlineMoreMsg{} }
+		}
+	case "enter", "tab":
+		chosen := sendInlineResultMsg{
+			chatID:  m.chatID,
+			queryID: m.results.QueryID,
+			result:  m.results.Results[m.cursor],
+		}
+		m.visible = false
+		return m, func() tea.Msg { return chosen }
+	case "esc":
+		m.dismissed = m.bot + " " + m.query
+		m.visible = false
+	}
+	return m, nil
+}
+
+// move shifts the cursor by delta, scrolling to keep it in view.
+func (m InlineResultsModel) move(delta int) InlineResultsModel {
+	m.cursor = min(max(m.cursor+delta, 0), len(m.results.Results)-1)
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+maxInlineRows {
+		m.top = m.cursor - maxInlineRows + 1
+	}
+	return m
+}
+
+// View renders the overlay box, to be placed just above the input.
+func (m InlineResultsModel) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	header := chatListHeaderStyle.Render("@" + m.bot)
+	if m.query == "" && m.results.Placeholder != "" {
+		header += " " + timeStyle.Render(m.results.Placeholder)
+	}
+	lines := []string{header}
+	results := m.results.Results
+	end := min(m.top+maxInlineRows, len(results))
+	for i := m.top; i < end; i++ {
+		label, detail := inlineResultLabel(results[i], i)
+		line := "  " + label
+		if i == m.cursor {
+			line = "> " + listSelectedStyle.Render(label)
+		}
+		if detail != "" {
+			line += " " + timeStyle.Render(detail)
+		}
+		lines = append(lines, line)
+	}
+	switch {
+	case m.loading:
+		lines = append(lines, timeStyle.Render("  loading…"))
+	case end < len(results) || m.results.NextOffset != "":
+		lines = append(lines, timeStyle.Render("  ↓ more"))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForegroundBlend(rainbowBlend...).
+		Padding(0, 1).
+		MaxWidth(max(m.width, 20)).
+		Render(strings.Join(lines, "\n"))
+}
+
+// inlineResultLabel is how a result reads in the list: its title, or
+// failing that its description or text. Untitled media, like GIFs, are
+// numbered. Results that aren't articles note their type.
+func inlineResultLabel(r domain.InlineResult, i int) (label, detail string) {
+	label, detail = r.Title, r.Description
+	if label == "" {
+		label, detail = detail, ""
+	}
+	if label == "" {
+		label, _, _ = strings.Cut(r.Text, "\n")
+	}
+	if label == "" {
+		label = fmt.Sprintf("%s %d", r.Type, i+1)
+	} else if r.Type != "" && r.Type != "article" {
+		if detail != "" {
+			detail += " · "
+		}
+		detail += r.Type
+	}
+	return label, detail
+}
@@ -1,17 +1,73 @@
 package ui
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
 	"charm.land/bubbles/v2/textarea"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/config"
+	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/emoji"
+)
+
+// minEmojiQuery is how many characters after ":" trigger emoji completion,
+// so times like "10:30" and smileys like ":)" don't open the popup.
+const minEmojiQuery = 2
+
+// Input box heights, including the border: it grows with its content
+// from minInputHeight up to maxInputHeight.
+const (
+	minInputHeight = 6
+	maxInputHeight = 14
 )
 
+// maxQuickReplyRows is how many rows of a bot's reply keyboard show above
+// the text at once; the rest scroll into view as the cursor moves.
+const maxQuickReplyRows = 3
+
+// pendingMention records a name inserted by completion for a user without
+// a username, so it can be sent as a MessageEntityMentionName.
+type pendingMention struct {
+	text   string
+	userID int64
+}
+
 // InputModel wraps a bubbles textarea for message composition.
 type InputModel struct {
 	textarea textarea.Model
 	focused  bool
 	width    int
 	height   int
+
+	participants []domain.Participant
+	recentEmoji  []string
+	popup        completionPopup
+	dismissed    string // token the user closed the popup for
+	mentions     []pendingMention
+
+	slashCommands []slashCommand
+	botCommands   []domain.BotCommand
+
+	// quickReplies is the active chat's reply keyboard, shown above the
+	// text, and replyCursor navigates it.
+	quickReplies [][]domain.Button
+	replyCursor  buttonCursor
+
+	// inlineBot and inlineQuery are the inline bot query last reported,
+	// as in "@gif cats".
+	inlineBot   string
+	inlineQuery string
+
+	history *config.History
+	chatID  int64 // the chat whose history Ctrl+P and Ctrl+N walk
+	browse  historyBrowse
+	search  historySearch
 }
 
 func NewInputModel() InputModel {
@@ -20,6 +76,9 @@ func NewInputModel() InputModel {
 	ta.Prompt = ""
 	ta.CharLimit = 4096
 	ta.ShowLineNumbers = false
+	// Enter sends; these keys start a new line instead. Shift+Enter needs
+	// a terminal that reports it, Alt+Enter works everywhere.
+	ta.KeyMap.InsertNewline.SetKeys("shift+enter", "alt+enter", "ctrl+j")
 
 	// Remove cursor-line background highlight.
 	s := ta.Styles()
@@ -32,22 +91,393 @@ func NewInputModel() InputModel {
 func (m InputModel) Update(msg tea.Msg) (InputModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.replyCursor.active {
+			return m.updateQuickReplies(msg)
+		}
+		if m.search.active {
+			var handled bool
+			if m, handled = m.updateSearch(msg); handled {
+				return m, nil
+			}
+		}
+		if m.popup.visible() {
+			switch msg.String() {
+			case "up", "ctrl+p":
+				m.popup = m.popup.move(-1)
+				return m, nil
+			case "down", "ctrl+n":
+				m.popup = m.popup.move(1)
+				return m, nil
+			case "tab", "enter":
+				return m.acceptCompletion()
+			case "esc":
+				m.dismissed = m.popup.token
+				m.popup = completionPopup{}
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "enter":
-			text := m.textarea.Value()
-			if text != "" {
-				m.textarea.Reset()
-				return m, func() tea.Msg {
-					return sendMessageMsg{text: text}
-				}
-			}
-			return m, nil
+			return m.send(m.textarea.Value())
 		}
 	}
 
 	var cmd tea.Cmd
 	m.textarea, cmd = m.textarea.Update(msg)
-	return m, cmd
+	var used, inline tea.Cmd
+	m, used = m.expandShortcode()
+	m = m.updateCompletion()
+	m, inline = m.checkInlineQuery()
+	return m, tea.Batch(cmd, used, inline)
+}
+
+// send clears the input and emits the text as a message, unless it's
+// blank.
+func (m InputModel) send(text string) (InputModel, tea.Cmd) {
+	if strings.TrimSpace(text) == "" {
+		return m, nil
+	}
+	mentions := resolveMentions(text, m.mentions)
+	m.textarea.Reset()
+	m.mentions = nil
+	m.popup = completionPopup{}
+	m.browse = historyBrowse{}
+	m.inlineBot, m.inlineQuery = "", ""
+	if name, arg, ok := splitSlash(text); ok {
+		if _, builtin := findSlashCommand(m.slashCommands, name); builtin {
+			return m, func() tea.Msg {
+				return slashCommandMsg{name: name, arg: arg, text: text}
+			}
+		}
+	}
+	// "//" sends text starting with a slash that would run a command.
+	if strings.HasPrefix(text, "//") {
+		text = text[1:]
+		for i := range mentions {
+			mentions[i].Offset--
+		}
+	}
+	return m, func() tea.Msg {
+		return sendMessageMsg{text: text, mentions: mentions}
+	}
+}
+
+// OpenEditor suspends the UI to edit the input in $VISUAL or $EDITOR,
+// falling back to vi. The result arrives as an editorFinishedMsg.
+func (m InputModel) OpenEditor() tea.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", "telecharm-*.md")
+	if err != nil {
+		return editorErrCmd(fmt.Errorf("create temp file: %w", err))
+	}
+	path := f.Name()
+	_, err = f.WriteString(m.textarea.Value())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return editorErrCmd(fmt.Errorf("write temp file: %w", err))
+	}
+
+	// The editor may carry arguments, as in EDITOR="code --wait".
+	args := append(strings.Fields(editor), path)
+	c := exec.Command(args[0], args[1:]...)
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editorFinishedMsg{err: fmt.Errorf("run %s: %w", args[0], err)}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return editorFinishedMsg{err: fmt.Errorf("read temp file: %w", err)}
+		}
+		return editorFinishedMsg{text: string(data)}
+	})
+}
+
+func editorErrCmd(err error) tea.Cmd {
+	return func() tea.Msg { return editorFinishedMsg{err: err} }
+}
+
+// FinishEdit sends the text written in the external editor. An empty
+// file cancels, leaving the input as it was.
+func (m InputModel) FinishEdit(text string) (InputModel, tea.Cmd) {
+	// Editors end files with a newline the user didn't mean to send.
+	return m.send(strings.TrimRight(text, "\n"))
+}
+
+// ContentHeight returns the box height that shows the whole input
+// without scrolling, clamped to the allowed range, plus any quick
+// replies.
+func (m InputModel) ContentHeight() int {
+	w := max(m.textarea.Width(), 1)
+	rows := 0
+	for _, line := range strings.Split(m.textarea.Value(), "\n") {
+		// The cursor takes a cell after the last character.
+		rows += lipgloss.Width(line)/w + 1
+	}
+	return min(max(rows+2, minInputHeight), maxInputHeight) + m.quickReplyHeight()
+}
+
+// SetQuickReplies sets the active chat's reply keyboard, or nil if it has
+// none.
+func (m InputModel) SetQuickReplies(rows [][]domain.Button) InputModel {
+	m.quickReplies = rows
+	if len(rows) == 0 {
+		m.replyCursor = buttonCursor{}
+	}
+	m.replyCursor = m.replyCursor.clamp(rows)
+	// The text area gives up or regains the keyboard's rows.
+	return m.SetSize(m.width, m.height)
+}
+
+// HasQuickReplies reports whether a bot's reply keyboard is showing.
+func (m InputModel) HasQuickReplies() bool {
+	return len(m.quickReplies) > 0
+}
+
+// FocusQuickReplies starts choosing a quick reply with the arrow keys.
+func (m InputModel) FocusQuickReplies() InputModel {
+	if len(m.quickReplies) > 0 {
+		m.replyCursor = buttonCursor{active: true}
+	}
+	return m
+}
+
+// IsChoosingQuickReply reports whether keys navigate the quick replies.
+func (m InputModel) IsChoosingQuickReply() bool {
+	return m.replyCursor.active
+}
+
+// updateQuickReplies handles a key while choosing a quick reply. Enter
+// presses the highlighted button and returns to typing.
+func (m InputModel) updateQuickReplies(key tea.KeyMsg) (InputModel, tea.Cmd) {
+	var pressed bool
+	m.replyCursor, pressed = m.replyCursor.update(m.quickReplies, key.String())
+	if !pressed {
+		return m, nil
+	}
+	b, ok := m.replyCursor.selected(m.quickReplies)
+	m.replyCursor = buttonCursor{}
+	if !ok {
+		return m, nil
+	}
+	msg := pressButtonMsg{chatID: m.chatID, button: b}
+	return m, func() tea.Msg { return msg }
+}
+
+// quickReplyHeight is how many rows the quick replies take.
+func (m InputModel) quickReplyHeight() int {
+	return min(len(m.quickReplies), maxQuickReplyRows)
+}
+
+// quickReplyView renders the rows of the reply keyboard in view, scrolled
+// to keep the cursor visible.
+func (m InputModel) quickReplyView() string {
+	first := max(0, m.replyCursor.row-maxQuickReplyRows+1)
+	rows := m.quickReplies[first:min(first+maxQuickReplyRows, len(m.quickReplies))]
+	cursor := m.replyCursor
+	cursor.row -= first
+	return renderKeyboard(rows, max(m.textarea.Width(), 1), cursor)
+}
+
+// IsCompleting reports whether the completion popup is open and should
+// receive navigation keys.
+func (m InputModel) IsCompleting() bool {
+	return m.popup.visible()
+}
+
+// PopupView renders the history search prompt, the completion popup or
+// the synopsis of the slash command being typed, or "" when none applies.
+func (m InputModel) PopupView() string {
+	if m.search.active {
+		return m.searchView()
+	}
+	if !m.popup.visible() {
+		if c, ok := m.typingCommand(); ok {
+			return slashHintView(c, m.width)
+		}
+	}
+	return m.popup.View(m.width)
+}
+
+// typingCommand returns the built-in command whose argument is being
+// typed, as in "/mute 1".
+func (m InputModel) typingCommand() (slashCommand, bool) {
+	value := m.textarea.Value()
+	name, _, ok := splitSlash(value)
+	if !ok || !strings.ContainsAny(value, " \t\n") {
+		return slashCommand{}, false
+	}
+	return findSlashCommand(m.slashCommands, name)
+}
+
+// SetSlashCommands sets the built-in commands the input runs and
+// completes.
+func (m InputModel) SetSlashCommands(cmds []slashCommand) InputModel {
+	m.slashCommands = cmds
+	return m
+}
+
+// SetBotCommands sets the active chat's bot commands for completion.
+func (m InputModel) SetBotCommands(cmds []domain.BotCommand) InputModel {
+	m.botCommands = cmds
+	m = m.updateCompletion()
+	return m
+}
+
+// SetParticipants sets the active chat's members for @mention completion.
+func (m InputModel) SetParticipants(ps []domain.Participant) InputModel {
+	m.participants = ps
+	m = m.updateCompletion()
+	return m
+}
+
+// SetRecentEmoji sets the recently used emoji, most recent first, which
+// rank first in completion.
+func (m InputModel) SetRecentEmoji(recent []string) InputModel {
+	m.recentEmoji = recent
+	return m
+}
+
+// Value returns the text being composed.
+func (m InputModel) Value() string {
+	return m.textarea.Value()
+}
+
+// SetValue replaces the text being composed, such as with a chat's draft,
+// leaving the cursor at the end.
+func (m InputModel) SetValue(text string) InputModel {
+	m.textarea.SetValue(text)
+	m.mentions = nil
+	m.dismissed = ""
+	m.popup = completionPopup{}
+	m.browse = historyBrowse{}
+	m.search = historySearch{}
+	m.inlineBot, m.inlineQuery = "", ""
+	return m
+}
+
+// InsertEmoji inserts an emoji at the cursor.
+func (m InputModel) InsertEmoji(char string) InputModel {
+	m.textarea.InsertString(char)
+	m = m.updateCompletion()
+	return m
+}
+
+// currentToken returns the word being typed when the cursor sits at the
+// very end of the input, or "" otherwise. Completion only applies there.
+func (m InputModel) currentToken() string {
+	value := m.textarea.Value()
+	lines := strings.Split(value, "\n")
+	last := lines[len(lines)-1]
+	if m.textarea.Line() != len(lines)-1 || m.textarea.Column() != utf8.RuneCountInString(last) {
+		return ""
+	}
+	if i := strings.LastIndexAny(last, " \t"); i >= 0 {
+		return last[i+1:]
+	}
+	return last
+}
+
+// updateCompletion refreshes the popup for the token under the cursor.
+func (m InputModel) updateCompletion() InputModel {
+	token := m.currentToken()
+	if token != m.dismissed {
+		m.dismissed = ""
+	}
+
+	var items []completionItem
+	if token != m.dismissed {
+		switch {
+		case strings.HasPrefix(token, "@"):
+			items = mentionCompletions(m.participants, token[1:])
+		case strings.HasPrefix(token, ":") && len(token) > minEmojiQuery:
+			items = emojiCompletions(m.recentEmoji, token[1:])
+		case strings.HasPrefix(token, "/") && token == m.textarea.Value():
+			items = slashCompletions(m.slashCommands, m.botCommands, token[1:])
+		default:
+			if c, ok := m.typingCommand(); ok && m.textarea.Value() == "/"+c.name+" "+token {
+				items = choiceCompletions(c, token)
+			}
+		}
+	}
+
+	cursor := 0
+	if token == m.popup.token && m.popup.cursor < len(items) {
+		cursor = m.popup.cursor
+	}
+	m.popup = completionPopup{token: token, items: items, cursor: cursor}
+	return m
+}
+
+// acceptCompletion replaces the token at the end of the input with the
+// selected candidate.
+func (m InputModel) acceptCompletion() (InputModel, tea.Cmd) {
+	item := m.popup.selected()
+	value := m.textarea.Value()
+	m.textarea.SetValue(strings.TrimSuffix(value, m.popup.token) + item.insert)
+	if item.userID != 0 {
+		m.mentions = append(m.mentions, pendingMention{
+			text:   strings.TrimSpace(item.insert),
+			userID: item.userID,
+		})
+	}
+	m.popup = completionPopup{}
+	if item.emoji != "" {
+		return m, emojiUsedCmd(item.emoji)
+	}
+	return m, nil
+}
+
+// expandShortcode replaces a just-completed ":shortcode:" at the end of the
+// input with its emoji.
+func (m InputModel) expandShortcode() (InputModel, tea.Cmd) {
+	token := m.currentToken()
+	if !strings.HasSuffix(token, ":") {
+		return m, nil
+	}
+	// The shortcode runs from the previous colon, which needn't start the
+	// token, as in "ok:thumbsup:".
+	open := strings.LastIndex(token[:len(token)-1], ":")
+	if open < 0 || open == len(token)-2 {
+		return m, nil
+	}
+	code := token[open+1 : len(token)-1]
+	char, ok := emoji.Lookup(code)
+	if !ok {
+		return m, nil
+	}
+	value := m.textarea.Value()
+	m.textarea.SetValue(strings.TrimSuffix(value, ":"+code+":") + char)
+	return m, emojiUsedCmd(char)
+}
+
+// resolveMentions locates each completed mention in the final text, in
+// insertion order. Mentions the user has since edited away are dropped.
+func resolveMentions(text string, pending []pendingMention) []domain.Mention {
+	var out []domain.Mention
+	from := make(map[string]int)
+	for _, pm := range pending {
+		start := from[pm.text]
+		i := strings.Index(text[start:], pm.text)
+		if i < 0 {
+			continue
+		}
+		off := start + i
+		out = append(out, domain.Mention{Offset: off, Length: len(pm.text), UserID: pm.userID})
+		from[pm.text] = off + len(pm.text)
+	}
+	return out
 }
 
 func (m InputModel) View() string {
@@ -57,7 +487,11 @@ func (m InputModel) View() string {
 		Height(m.height)
 	style = applyBorderColor(style, m.focused)
 
-	return style.Render(m.textarea.View())
+	content := m.textarea.View()
+	if len(m.quickReplies) > 0 {
+		content = m.quickReplyView() + "\n" + content
+	}
+	return style.Render(content)
 }
 
 func (m InputModel) Focus() InputModel {
@@ -80,7 +514,7 @@ func (m InputModel) SetSize(w, h int) InputModel {
 	if taWidth < 1 {
 		taWidth = 1
 	}
-	taHeight := h - 2
+	taHeight := h - 2 - m.quickReplyHeight()
 	if taHeight < 1 {
 		taHeight = 1
 	}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+func TestResolveMentions(t *testing.T) {
+	ann := pendingMention{text: "Ann Lee", userID: 1}
+	tests := []struct {
+		name    string
+		text    string
+		pending []pendingMention
+		want    []domain.Mention
+	}{
+		{"one", "hi Ann Lee", []pendingMention{ann},
+			[]domain.Mention{{Offset: 3, Length: 7, UserID: 1}}},
+		{"repeated name", "Ann Lee and Ann Lee", []pendingMention{ann, {text: "Ann Lee", userID: 2}},
+			[]domain.Mention{{Offset: 0, Length: 7, UserID: 1}, {Offset: 12, Length: 7, UserID: 2}}},
+		{"one of two left", "Ann Lee, hi", []pendingMention{ann, {text: "Ann Lee", userID: 2}},
+			[]domain.Mention{{Offset: 0, Length: 7, UserID: 1}}},
+		{"edited away", "hi Bo Kim", []pendingMention{ann, {text: "Bo Kim", userID: 3}},
+			[]domain.Mention{{Offset: 3, Length: 6, UserID: 3}}},
+		{"byte offsets", "café Ann Lee", []pendingMention{ann},
+			[]domain.Mention{{Offset: 6, Length: 7, UserID: 1}}},
+		{"none", "hi", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := resolveMentions(tt.text, tt.pending); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: resolveMentions(%q) = %+v, want %+v", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMentionCompletions(t *testing.T) {
+	ps := []domain.Participant{
+		{ID: 1, Name: "Ann Lee"},
+		{ID: 2, Name: "Ann Lee"},
+		{ID: 3, Name: "Leo Park", Username: "leo"},
+		{ID: 4, Name: "Bo Kim", Username: "annabel"},
+	}
+	tests := []struct {
+		query string
+		want  []completionItem
+	}{
+		// Username matches come first; people sharing a name stay
+		// apart by ID.
+		{"ann", []completionItem{
+			{label: "Bo Kim", detail: "@annabel", insert: "@annabel "},
+			{label: "Ann Lee", insert: "Ann Lee ", userID: 1},
+			{label: "Ann Lee", insert: "Ann Lee ", userID: 2},
+		}},
+		{"LE", []completionItem{
+			{label: "Leo Park", detail: "@leo", insert: "@leo "},
+			{label: "Ann Lee", insert: "Ann Lee ", userID: 1},
+			{label: "Ann Lee", insert: "Ann Lee ", userID: 2},
+		}},
+		{"zed", nil},
+	}
+	for _, tt := range tests {
+		got := mentionCompletions(ps, tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("mentionCompletions(%q) = %+v, want %+v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("mentionCompletions(%q)[%d] = %+v, want %+v", tt.query, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestSendShiftsMentionsPastEscape(t *testing.T) {
+	m := NewInputModel().SetParticipants([]domain.Participant{{ID: 1, Name: "Ann Lee"}})
+	m.textarea.SetValue("//start, @an")
+	m = m.updateCompletion()
+	m, _ = m.acceptCompletion()
+
+	_, cmd := m.send(m.textarea.Value())
+	msg, ok := cmd().(sendMessageMsg)
+	if !ok {
+		t.Fatalf("send returned %T, want a sendMessageMsg", cmd())
+	}
+	want := []domain.Mention{{Offset: 8, Length: 7, UserID: 1}}
+	if msg.text != "/start, Ann Lee " || !slices.Equal(msg.mentions, want) {
+		t.Errorf("sent %q with %+v, want %q with %+v", msg.text, msg.mentions, "/start, Ann Lee ", want)
+	}
+	if got := msg.text[want[0].Offset : want[0].Offset+want[0].Length]; got != "Ann Lee" {
+		t.Errorf("mention covers %q", got)
+	}
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// jumpDateLayouts are the date formats accepted by the jump prompt, in
+// local time.
+var jumpDateLayouts = []string{"2006-01-02 15:04", "2006-01-02"}
+
+var errJumpTarget = errors.New("enter a message ID or a date like 2024-05-31 or 2024-05-31 14:00")
+
+// parseJumpTarget parses a message ID or a date.
+func parseJumpTarget(s string) (jumpMsg, error) {
+	s = strings.TrimSpace(s)
+	if id, err := strconv.Atoi(strings.TrimPrefix(s, "#")); err == nil && id > 0 {
+		return jumpMsg{id: id}, nil
+	}
+	for _, layout := range jumpDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return jumpMsg{date: t}, nil
+		}
+	}
+	return jumpMsg{}, errJumpTarget
+}
+
+// JumpPromptModel renders a centered prompt for jumping to a message by
+// ID or date.
+type JumpPromptModel struct {
+	visible       bool
+	input         textinput.Model
+	err           error
+	width, height int
+}
+
+// NewJumpPromptModel creates a hidden jump prompt.
+func NewJumpPromptModel() JumpPromptModel {
+	ti := textinput.New()
+	ti.Placeholder = "message ID or YYYY-MM-DD [HH:MM]"
+	ti.Prompt = "> "
+	ti.CharLimit = 32
+	return JumpPromptModel{input: ti}
+}
+
+// IsVisible reports whether the prompt is showing.
+func (m JumpPromptModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the prompt with an empty input.
+func (m JumpPromptModel) Show() (JumpPromptModel, tea.Cmd) {
+	m.visible = true
+	m.err = nil
+	m.input.SetValue("")
+	return m, m.input.Focus()
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m JumpPromptModel) SetSize(w, h int) JumpPromptModel {
+	m.width = w
+	m.height = h
+	m.input.SetWidth(min(40, max(w-12, 10)))
+	return m
+}
+
+func (m JumpPromptModel) Update(msg tea.Msg) (JumpPromptModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.visible = false
+			m.input.Blur()
+			return m, nil
+		case "enter":
+			target, err := parseJumpTarget(m.input.Value())
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.visible = false
+			m.input.Blur()
+			return m, func() tea.Msg { return target }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.err = nil
+	return m, cmd
+}
+
+// View renders the prompt box (without full-screen placement).
+func (m JumpPromptModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	content := chatListHeaderStyle.Render("Jump to message") + "\n\n" + m.input.View()
+	if m.err != nil {
+		content += "\n\n" + errorStyle.Render(m.err.Error())
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 3).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(content)
+}
@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// minKeyboardWidth keeps buttons on a short message readable.
+const minKeyboardWidth = 30
+
+// buttonCursor is the highlighted button while navigating a keyboard.
+type buttonCursor struct {
+	active   bool
+	row, col int
+}
+
+// move shifts the cursor within rows, keeping it on a button. Moving to
+// a shorter row lands on its last button.
+func (c buttonCursor) move(rows [][]domain.Button, dRow, dCol int) buttonCursor {
+	c = c.clamp(rows)
+	if len(rows) == 0 {
+		return c
+	}
+	c.row = min(max(c.row+dRow, 0), len(rows)-1)
+	c.col = min(max(c.col+dCol, 0), len(rows[c.row])-1)
+	return c
+}
+
+// clamp keeps the cursor on a button after the keyboard changed, as when
+// a bot edits its message.
+func (c buttonCursor) clamp(rows [][]domain.Button) buttonCursor {
+	if len(rows) == 0 {
+		c.row, c.col = 0, 0
+		return c
+	}
+	c.row = min(c.row, len(rows)-1)
+	c.col = max(min(c.col, len(rows[c.row])-1), 0)
+	return c
+}
+
+// selected returns the button under the cursor.
+func (c buttonCursor) selected(rows [][]domain.Button) (domain.Button, bool) {
+	c = c.clamp(rows)
+	if len(rows) == 0 || len(rows[c.row]) == 0 {
+		return domain.Button{}, false
+	}
+	return rows[c.row][c.col], true
+}
+
+// update handles a key while navigating a keyboard. It reports whether
+// Enter pressed the highlighted button, and deactivates the cursor when
+// leaving with Esc.
+func (c buttonCursor) update(rows [][]domain.Button, key string) (buttonCursor, bool) {
+	switch key {
+	case "left", "h", "shift+tab":
+		c = c.move(rows, 0, -1)
+	case "right", "l", "tab":
+		c = c.move(rows, 0, 1)
+	case "up", "k":
+		c = c.move(rows, -1, 0)
+	case "down", "j":
+		c = c.move(rows, 1, 0)
+	case "esc", "q":
+		c.active = false
+	case "enter", "space":
+		return c, true
+	}
+	return c, false
+}
+
+// buttonLabel is how a button reads on the keyboard.
+func buttonLabel(b domain.Button) string {
+	if b.Kind == domain.ButtonURL {
+		return b.Text + " ↗"
+	}
+	return b.Text
+}
+
+// keyboardWidth is the width the keyboard needs to show every label.
+func keyboardWidth(rows [][]domain.Button) int {
+	w := 0
+	for _, row := range rows {
+		rw := 0
+		for _, b := range row {
+			rw += lipgloss.Width(buttonLabel(b)) + 3 // brackets and a gap
+		}
+		w = max(w, rw-1)
+	}
+	return w
+}
+
+// renderKeyboard renders a bot keyboard as rows of bracketed buttons that
+// share the width equally, highlighting the cursor's button when active.
+func renderKeyboard(rows [][]domain.Button, width int, cursor buttonCursor) string {
+	cursor = cursor.clamp(rows)
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		cell := (width - (len(row) - 1)) / len(row)
+		cells := make([]string, len(row))
+		for c, b := range row {
+			w := cell
+			if c == len(row)-1 {
+				w = width - (cell+1)*(len(row)-1)
+			}
+			text := lipgloss.NewStyle().
+				Width(max(w-2, 1)).
+				MaxWidth(max(w-2, 1)).
+				Align(lipgloss.Center).
+				Render(truncate(buttonLabel(b), max(w-2, 1)))
+			label := "[" + text + "]"
+			switch {
+			case cursor.active && r == cursor.row && c == cursor.col:
+				label = buttonSelectedStyle.Render(label)
+			case b.Kind == domain.ButtonUnsupported:
+				label = timeStyle.Render(label)
+			default:
+				label = buttonStyle.Render(label)
+			}
+			cells[c] = label
+		}
+		lines[r] = strings.Join(cells, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate shortens s to at most w cells, marking the cut with "…".
+func truncate(s string, w int) string {
+	if lipgloss.Width(s) <= w {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > w {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// openURL opens a web or Telegram link in the system's handler. Other
+// schemes are refused, since the URL comes from a bot.
+func openURL(link string) tea.Cmd {
+	return func() tea.Msg {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "tg") {
+			return localErrorMsg{err: fmt.Errorf("won't open %q", link)}
+		}
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", link)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+		default:
+			cmd = exec.Command("xdg-open", link)
+		}
+		if err := cmd.Start(); err != nil {
+			return localErrorMsg{err: fmt.Errorf("open %s: %w", link, err)}
+		}
+		go cmd.Wait()
+		return nil
+	}
+}
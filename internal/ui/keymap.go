@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/danhigham/telecharm/internal/config"
+)
+
+// CheckKeys checks a key configuration against the actions it names:
+// that each exists and can be rebound, and that no key is bound to two
+// actions active at once. config.Load can't, since the actions live here.
+func CheckKeys(k config.KeysConfig) error {
+	_, err := bindActions(k)
+	return err
+}
+
+// bindActions returns the action registry with a key configuration
+// applied: the preset's bindings, then the user's.
+func bindActions(k config.KeysConfig) ([]action, error) {
+	actions := defaultActions()
+	index := make(map[string]int, len(actions))
+	for i, a := range actions {
+		index[a.name] = i
+	}
+
+	for name, keys := range config.KeyPresets[k.PresetName()] {
+		actions[index[name]].keys = keys
+	}
+	for name, keys := range k.Bindings {
+		i, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("keys.%s: unknown action", name)
+		}
+		if actions[i].run == nil {
+			return nil, fmt.Errorf("keys.%s: handled by its pane and can't be rebound", name)
+		}
+		actions[i].keys = keys
+	}
+
+	// A key may be reused only by actions that can never be active in the
+	// same pane.
+	for i, a := range actions {
+		for _, b := range actions[i+1:] {
+			if a.scope != b.scope && a.scope != scopeAny && b.scope != scopeAny {
+				continue
+			}
+			for _, ka := range a.keys {
+				for _, kb := range b.keys {
+					if ka == kb {
+						return nil, fmt.Errorf("keys: %q is bound to both %s and %s", ka, a.name, b.name)
+					}
+				}
+			}
+		}
+	}
+	return actions, nil
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danhigham/telecharm/internal/config"
+)
+
+func TestCheckKeys(t *testing.T) {
+	tests := map[string]struct {
+		keys string
+		err  string // empty if the config loads
+	}{
+		"rebind":         {"  toggle-sidebar: ctrl+s\n", ""},
+		"preset":         {"  preset: emacs\n", ""},
+		"conflict":       {"  mark-read: r\n", `"r" is bound to both`},
+		"preset clash":   {"  preset: vim\n  search-chat: G\n", `"G" is bound to both`},
+		"unknown action": {"  teleport: t\n", "unknown action"},
+		"pane key":       {"  send: ctrl+s\n", "can't be rebound"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte("keys:\n"+tt.keys), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.Load(path)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			err = CheckKeys(cfg.Keys)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("CheckKeys: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("CheckKeys error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
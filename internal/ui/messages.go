@@ -1,8 +1,12 @@
 package ui
 
 import (
+	"time"
+
 	tea "charm.land/bubbletea/v2"
+
 	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/telegram"
 )
 
 // StoreUpdatedMsg signals that the store state has changed.
@@ -26,7 +30,27 @@ type HistoryLoadedMsg struct {
 
 // sendMessageMsg is emitted when the user presses Enter in the input.
 type sendMessageMsg struct {
+	text     string
+	mentions []domain.Mention
+}
+
+// slashCommandMsg is emitted when the user sends a built-in slash
+// command. text is the input as typed, for the sent history.
+type slashCommandMsg struct {
+	name string
+	arg  string
+	text string
+}
+
+// selfNameChangedMsg reports our new display name after /nick.
+type selfNameChangedMsg struct {
+	name string
+}
+
+// editorFinishedMsg delivers the text written in the external editor.
+type editorFinishedMsg struct {
 	text string
+	err  error
 }
 
 // StatusMsg updates the status bar.
@@ -40,6 +64,13 @@ type SendErrorMsg struct {
 	Err error
 }
 
+// localErrorMsg reports a failure that says nothing about the connection,
+// such as saving a file or a request Telegram turned down, so unlike
+// SendErrorMsg it leaves the status bar's connection indicator alone.
+type localErrorMsg struct {
+	err error
+}
+
 // LoadOlderHistoryMsg is emitted when the user scrolls to the top of messages.
 type LoadOlderHistoryMsg struct {
 	ChatID int64
@@ -51,6 +82,75 @@ type OlderHistoryLoadedMsg struct {
 	Messages []domain.Message
 }
 
+// LoadNewerHistoryMsg is emitted when the user scrolls to a gap in the
+// loaded history; the messages after AfterID are fetched.
+type LoadNewerHistoryMsg struct {
+	ChatID  int64
+	AfterID int
+}
+
+// NewerHistoryLoadedMsg delivers history fetched forward across a gap.
+type NewerHistoryLoadedMsg struct {
+	ChatID        int64
+	Messages      []domain.Message
+	ReachedLatest bool
+}
+
+// jumpMsg asks to show a message by ID, or the first message sent at or
+// after a date.
+type jumpMsg struct {
+	id   int
+	date time.Time
+}
+
+// historyWindowLoadedMsg delivers the history fetched around a jump
+// target that wasn't loaded.
+type historyWindowLoadedMsg struct {
+	chatID        int64
+	msgs          []domain.Message
+	reachedLatest bool
+	target        jumpMsg
+}
+
+// searchMsg asks to search the active chat.
+type searchMsg struct {
+	query  string
+	filter telegram.SearchFilter
+}
+
+// searchResultsMsg delivers the hits of a search, newest first.
+type searchResultsMsg struct {
+	chatID int64
+	query  string
+	filter telegram.SearchFilter
+	msgs   []domain.Message
+	err    error
+}
+
+// searchHitMsg is emitted when the user opens a search result.
+type searchHitMsg struct {
+	msg domain.Message
+}
+
+// globalSearchMsg asks to search across all chats.
+type globalSearchMsg struct {
+	query string
+}
+
+// globalSearchResultsMsg delivers the results of a global search.
+type globalSearchResultsMsg struct {
+	query   string
+	results telegram.GlobalSearchResults
+	err     error
+}
+
+// openResultMsg opens a chat from search results, at a message if msgID
+// is set.
+type openResultMsg struct {
+	chat  domain.ChatInfo
+	msgID int
+}
+
 // SplashDoneMsg signals that the splash screen timeout has elapsed.
 type SplashDoneMsg struct{}
 
@@ -62,7 +162,144 @@ type BubblesToggledMsg struct {
 	Enabled bool
 }
 
+// muteChatMsg is emitted when the user picks an option in the mute menu.
+// A zero until unmutes the chat.
+type muteChatMsg struct {
+	chatID int64
+	until  time.Time
+}
+
+// unreadMentionsLoadedMsg delivers the unread mention IDs for a chat.
+type unreadMentionsLoadedMsg struct {
+	chatID int64
+	ids    []int
+}
+
+// participantsLoadedMsg delivers a chat's members for mention completion.
+type participantsLoadedMsg struct {
+	chatID       int64
+	participants []domain.Participant
+}
+
+// botCommandsLoadedMsg delivers the commands of a chat's bots for slash
+// completion.
+type botCommandsLoadedMsg struct {
+	chatID   int64
+	commands []domain.BotCommand
+}
+
+// emojiPickedMsg is emitted when the user picks an emoji in the picker.
+type emojiPickedMsg struct {
+	char string
+}
+
+// emojiUsedMsg records an inserted emoji in the recently used list.
+type emojiUsedMsg struct {
+	char string
+}
+
+func emojiUsedCmd(char string) tea.Cmd {
+	return func() tea.Msg { return emojiUsedMsg{char: char} }
+}
+
+// reactionsLoadedMsg delivers the reactions allowed in a chat so the
+// picker can open for a message.
+type reactionsLoadedMsg struct {
+	msg     domain.Message
+	allowed []string
+	err     error
+}
+
+// reactMsg is emitted when the user picks a reaction. An empty emoji
+// removes our reaction.
+type reactMsg struct {
+	chatID int64
+	msgID  int
+	emoji  string
+}
+
+// forwardMsg is emitted when the user picks a destination in the forward
+// picker.
+type forwardMsg struct {
+	fromChatID int64
+	msgIDs     []int
+	toChatID   int64
+}
+
+// pressButtonMsg is emitted when the user presses a button on a bot's
+// keyboard. msgID is 0 for reply keyboard buttons.
+type pressButtonMsg struct {
+	chatID int64
+	msgID  int
+	button domain.Button
+}
+
+// buttonAnsweredMsg delivers a bot's answer to a callback button.
+type buttonAnsweredMsg struct {
+	answer telegram.CallbackAnswer
+	err    error
+}
+
+// votePollMsg asks to vote in the poll on a message.
+type votePollMsg struct {
+	chatID  int64
+	msgID   int
+	options [][]byte
+}
+
+// sendPollMsg asks to send a poll made in the poll form.
+type sendPollMsg struct {
+	chatID int64
+	poll   domain.Poll
+}
+
+// inlineQueryMsg reports the inline bot query typed in the input, once
+// typing pauses. An empty bot means the input no longer holds one.
+type inlineQueryMsg struct {
+	bot   string
+	query string
+}
+
+// inlineResultsMsg delivers a page of an inline bot's results; offset is
+// empty for the first page.
+type inlineResultsMsg struct {
+	chatID  int64
+	bot     string
+	query   string
+	offset  string
+	results telegram.InlineResults
+	err     error
+}
+
+// inlineMoreMsg asks for the next page of inline results.
+type inlineMoreMsg struct{}
+
+// sendInlineResultMsg asks to send a chosen inline result.
+type sendInlineResultMsg struct {
+	chatID  int64
+	queryID int64
+	result  domain.InlineResult
+}
+
+// pinnedLoadedMsg delivers a chat's pinned messages, newest first.
+type pinnedLoadedMsg struct {
+	chatID int64
+	msgs   []domain.Message
+}
+
+// pinMsg asks to pin or unpin a message.
+type pinMsg struct {
+	chatID int64
+	msgID  int
+	pin    bool
+}
+
 // StoreUpdatedCmd returns a command that emits StoreUpdatedMsg.
 func StoreUpdatedCmd() tea.Msg {
 	return StoreUpdatedMsg{}
 }
+
+// runActionMsg asks to run a named action, e.g. from the command palette.
+type runActionMsg struct {
+	name string
+}
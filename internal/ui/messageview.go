@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image/color"
 	"regexp"
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/viewport"
@@ -30,48 +31,62 @@ type MessageViewModel struct {
 	typingUser string
 	messages   []domain.Message
 	loading    bool // true while fetching older history
+	loadingNew bool // true while fetching newer history across a gap
 	hasMore    bool // false when history is exhausted
 	bubbles    bool // true = speech bubbles, false = flat format
+
+	// msgOffsets maps message IDs to their byte offset in the rendered
+	// content before final wrapping, and lineStarts maps the offset of
+	// each line of that content to its first line once wrapped, so the
+	// view can scroll to a given message without wrapping it again.
+	msgOffsets map[int]int
+	lineStarts map[int]int
+
+	// gaps holds the IDs of messages followed by missing history, and
+	// gapOffsets the byte offsets of their markers in the content.
+	gaps       map[int]bool
+	gapOffsets map[int]int
+
+	selected int // ID of the selected message, 0 for none
+	// buttons navigates the selected message's inline keyboard.
+	buttons buttonCursor
+	// vote is the state of voting in the selected message's poll.
+	vote pollVote
+
+	// pinned holds the active chat's pinned messages, newest first, and
+	// pinIndex the one shown in the pin bar.
+	pinned   []domain.Message
+	pinIndex int
+
+	highlight []string // search terms whose matches are highlighted
 }
 
 func NewMessageViewModel(bubbles bool) MessageViewModel {
 	vp := viewport.New()
+	// Keys are bound through the action registry so they can be
+	// configured; the viewport's own pager keys would bypass that.
+	vp.KeyMap = viewport.KeyMap{}
 	return MessageViewModel{viewport: vp, bubbles: bubbles}
 }
 
 func (m MessageViewModel) Update(msg tea.Msg) (MessageViewModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "j":
-			m.viewport.ScrollDown(1)
-			return m, nil
-		case "k":
-			m.viewport.ScrollUp(1)
-			return m, m.checkScrollTop()
-		case "pgup":
-			m.viewport.PageUp()
-			return m, m.checkScrollTop()
-		case "pgdown":
-			m.viewport.PageDown()
-			return m, nil
-		case "b":
-			m.bubbles = !m.bubbles
-			m = m.renderContent()
-			enabled := m.bubbles
-			return m, func() tea.Msg {
-				return BubblesToggledMsg{Enabled: enabled}
-			}
+		if m.buttons.active {
+			return m.updateButtons(msg)
+		}
+		if m.vote.active {
+			return m.updateVote(msg)
 		}
 	case tea.MouseWheelMsg:
 		e := msg.Mouse()
 		switch e.Button {
 		case tea.MouseWheelUp:
 			m.viewport.ScrollUp(3)
-			return m, m.checkScrollTop()
+			return m, m.checkScroll()
 		case tea.MouseWheelDown:
 			m.viewport.ScrollDown(3)
-			return m, nil
+			return m, m.checkScroll()
 		}
 	}
 
@@ -82,12 +97,103 @@ func (m MessageViewModel) Update(msg tea.Msg) (MessageViewModel, tea.Cmd) {
 	if cmd != nil {
 		cmds = append(cmds, cmd)
 	}
-	if scrollCmd := m.checkScrollTop(); scrollCmd != nil {
+	if scrollCmd := m.checkScroll(); scrollCmd != nil {
 		cmds = append(cmds, scrollCmd)
 	}
 	return m, tea.Batch(cmds...)
 }
 
+// ScrollBy scrolls the view by lines, down when positive.
+func (m MessageViewModel) ScrollBy(lines int) (MessageViewModel, tea.Cmd) {
+	if lines > 0 {
+		m.viewport.ScrollDown(lines)
+	} else {
+		m.viewport.ScrollUp(-lines)
+	}
+	return m, m.checkScroll()
+}
+
+// ScrollPage scrolls the view by a page, down when delta is positive.
+func (m MessageViewModel) ScrollPage(delta int) (MessageViewModel, tea.Cmd) {
+	if delta > 0 {
+		m.viewport.PageDown()
+	} else {
+		m.viewport.PageUp()
+	}
+	return m, m.checkScroll()
+}
+
+// ScrollHalfPage scrolls the view by half a page, down when delta is
+// positive.
+func (m MessageViewModel) ScrollHalfPage(delta int) (MessageViewModel, tea.Cmd) {
+	if delta > 0 {
+		m.viewport.HalfPageDown()
+	} else {
+		m.viewport.HalfPageUp()
+	}
+	return m, m.checkScroll()
+}
+
+// ScrollToEnd scrolls to the newest loaded message when delta is
+// positive, or to the oldest, which loads older history.
+func (m MessageViewModel) ScrollToEnd(delta int) (MessageViewModel, tea.Cmd) {
+	if delta > 0 {
+		m.viewport.GotoBottom()
+	} else {
+		m.viewport.GotoTop()
+	}
+	return m, m.checkScroll()
+}
+
+// Select moves the selection delta messages, newer when positive.
+func (m MessageViewModel) Select(delta int) (MessageViewModel, tea.Cmd) {
+	m = m.moveSelection(delta)
+	return m, m.checkScroll()
+}
+
+// ToggleBubbles switches between speech bubbles and flat lines, and
+// returns a command reporting the new preference.
+func (m MessageViewModel) ToggleBubbles() (MessageViewModel, tea.Cmd) {
+	m.bubbles = !m.bubbles
+	m = m.renderContent()
+	enabled := m.bubbles
+	return m, func() tea.Msg {
+		return BubblesToggledMsg{Enabled: enabled}
+	}
+}
+
+// checkScroll returns a command to load more history when the view has
+// reached the top of the loaded messages or a gap in them.
+func (m MessageViewModel) checkScroll() tea.Cmd {
+	if cmd := m.checkScrollTop(); cmd != nil {
+		return cmd
+	}
+	return m.checkGaps()
+}
+
+// checkGaps returns a command to load the history missing at the first
+// gap marker in view, loading forward from the message before it.
+func (m MessageViewModel) checkGaps() tea.Cmd {
+	if m.loadingNew || len(m.gaps) == 0 || len(m.messages) == 0 {
+		return nil
+	}
+	top := m.viewport.YOffset()
+	bottom := top + m.viewport.Height()
+	for _, msg := range m.messages {
+		off, ok := m.gapOffsets[msg.ID]
+		if !ok {
+			continue
+		}
+		if line := m.lineAt(off); line >= top && line < bottom {
+			chatID, afterID := msg.ChatID, msg.ID
+			return func() tea.Msg {
+				return LoadNewerHistoryMsg{ChatID: chatID, AfterID: afterID}
+			}
+		}
+	}
+	return nil
+}
+
 // checkScrollTop returns a command to load older history if scrolled to top.
 func (m MessageViewModel) checkScrollTop() tea.Cmd {
 	if m.viewport.YOffset() == 0 && !m.loading && m.hasMore && len(m.messages) > 0 {
@@ -105,7 +211,12 @@ func (m MessageViewModel) View() string {
 		contentH = 0
 	}
 
-	content := truncateHeight(m.viewport.View(), contentH)
+	var content string
+	if len(m.pinned) > 0 {
+		content = m.pinBar() + "\n" + truncateHeight(m.viewport.View(), contentH-1)
+	} else {
+		content = truncateHeight(m.viewport.View(), contentH)
+	}
 
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -121,22 +232,94 @@ func (m MessageViewModel) SetSize(w, h int) MessageViewModel {
 	m.height = h
 	// Viewport inner: subtract border (2)
 	vpW := w - 2
-	vpH := h - 2
 	if vpW < 1 {
 		vpW = 1
 	}
+	m.viewport.SetWidth(vpW)
+	m = m.sizeViewportHeight()
+	m = m.recreateRenderer()
+	m = m.renderContent()
+	return m
+}
+
+// sizeViewportHeight fits the viewport inside the border, below the pin
+// bar when there is one.
+func (m MessageViewModel) sizeViewportHeight() MessageViewModel {
+	vpH := m.height - 2
+	if len(m.pinned) > 0 {
+		vpH--
+	}
 	if vpH < 1 {
 		vpH = 1
 	}
-	m.viewport.SetWidth(vpW)
 	m.viewport.SetHeight(vpH)
-	m = m.recreateRenderer()
-	m = m.renderContent()
 	return m
 }
 
+// SetHighlight highlights matches of search terms in message text; no
+// terms clears it.
+func (m MessageViewModel) SetHighlight(terms ...string) MessageViewModel {
+	if slices.Equal(terms, m.highlight) {
+		return m
+	}
+	m.highlight = terms
+	return m.renderContentNoScroll()
+}
+
+// SetPinned sets the active chat's pinned messages, newest first.
+func (m MessageViewModel) SetPinned(pins []domain.Message) MessageViewModel {
+	hadBar := len(m.pinned) > 0
+	if len(pins) == 0 || len(m.pinned) == 0 || pins[0].ChatID != m.pinned[0].ChatID ||
+		m.pinIndex >= len(pins) {
+		m.pinIndex = 0
+	}
+	m.pinned = pins
+	if hadBar != (len(pins) > 0) {
+		atBottom := m.viewport.AtBottom()
+		m = m.sizeViewportHeight()
+		if atBottom {
+			m.viewport.GotoBottom()
+		}
+	}
+	return m
+}
+
+// CurrentPin returns the pinned message shown in the pin bar.
+func (m MessageViewModel) CurrentPin() (domain.Message, bool) {
+	if len(m.pinned) == 0 {
+		return domain.Message{}, false
+	}
+	return m.pinned[m.pinIndex], true
+}
+
+// NextPin cycles the pin bar to the next older pin, wrapping around to
+// the newest.
+func (m MessageViewModel) NextPin() MessageViewModel {
+	if len(m.pinned) > 0 {
+		m.pinIndex = (m.pinIndex + 1) % len(m.pinned)
+	}
+	return m
+}
+
+// pinBar renders the one-line bar showing the current pin.
+func (m MessageViewModel) pinBar() string {
+	pin := m.pinned[m.pinIndex]
+	label := "📌 Pinned"
+	if len(m.pinned) > 1 {
+		label = fmt.Sprintf("📌 Pinned %d/%d", m.pinIndex+1, len(m.pinned))
+	}
+	text := strings.Join(strings.Fields(pin.Text), " ")
+	bar := pinLabelStyle.Render(label) + " " + text
+	return lipgloss.NewStyle().MaxWidth(m.viewport.Width()).MaxHeight(1).Render(bar)
+}
+
 func (m MessageViewModel) SetFocused(f bool) MessageViewModel {
 	m.focused = f
+	if !f && (m.buttons.active || m.vote.active) {
+		m.buttons = buttonCursor{}
+		m.vote = pollVote{}
+		m = m.renderContentNoScroll()
+	}
 	return m
 }
 
@@ -145,12 +328,30 @@ func (m MessageViewModel) SetTypingUser(name string) MessageViewModel {
 	return m
 }
 
-func (m MessageViewModel) SetMessages(msgs []domain.Message) MessageViewModel {
+// SetMessages replaces the displayed messages. gaps lists the IDs of
+// messages followed by missing history, as reported by the store.
+func (m MessageViewModel) SetMessages(msgs []domain.Message, gaps []int) MessageViewModel {
+	// Follow new messages only if the user hasn't scrolled up in this chat
+	// (e.g. after jumping to a mention) and the bottom is the live end of
+	// the chat rather than a gap.
+	chatChanged := len(m.messages) == 0 || len(msgs) == 0 || m.messages[0].ChatID != msgs[0].ChatID
+	follow := chatChanged || (m.viewport.AtBottom() && !m.gaps[m.messages[len(m.messages)-1].ID])
+	if len(m.messages) > 0 && chatChanged {
+		m.selected = 0
+		m.buttons = buttonCursor{}
+		m.vote = pollVote{}
+	}
+	m.gaps = make(map[int]bool, len(gaps))
+	for _, id := range gaps {
+		m.gaps[id] = true
+	}
 	m.messages = msgs
 	m.hasMore = true
 	m.loading = false
-	m = m.renderContent()
-	return m
+	if follow {
+		return m.renderContent()
+	}
+	return m.renderContentNoScroll()
 }
 
 // PrependMessages adds older messages to the top and preserves scroll position.
@@ -178,12 +379,125 @@ func (m MessageViewModel) PrependMessages(msgs []domain.Message) MessageViewMode
 	return m
 }
 
+// SetLoadingNewer marks the view as loading newer history across a gap.
+func (m MessageViewModel) SetLoadingNewer(v bool) MessageViewModel {
+	m.loadingNew = v
+	return m
+}
+
 // SetLoading marks the view as loading older history.
 func (m MessageViewModel) SetLoading(v bool) MessageViewModel {
 	m.loading = v
 	return m
 }
 
+// ScrollToMessage scrolls so the given message sits near the top of the
+// view. It reports false if the message is not loaded.
+func (m MessageViewModel) ScrollToMessage(id int) (MessageViewModel, bool) {
+	line, ok := m.lineOf(id)
+	if !ok {
+		return m, false
+	}
+	m.viewport.SetYOffset(line)
+	return m, true
+}
+
+// SelectMessage selects a message and scrolls it into view. It reports
+// false if the message is not loaded.
+func (m MessageViewModel) SelectMessage(id int) (MessageViewModel, bool) {
+	if !m.HasMessage(id) {
+		return m, false
+	}
+	m.selected = id
+	m.buttons = buttonCursor{}
+	m.vote = pollVote{}
+	m = m.renderContentNoScroll()
+	return m.ScrollToMessage(id)
+}
+
+// lineOf returns the first line of a message in the wrapped content.
+func (m MessageViewModel) lineOf(id int) (int, bool) {
+	off, ok := m.msgOffsets[id]
+	if !ok {
+		return 0, false
+	}
+	return m.lineAt(off), true
+}
+
+// lineAt converts the byte offset of a message or gap marker in the
+// content to its line once wrapped, as recorded by renderContent.
+func (m MessageViewModel) lineAt(off int) int {
+	return m.lineStarts[off]
+}
+
+// SelectedMessage returns the message selected with the arrow keys.
+func (m MessageViewModel) SelectedMessage() (domain.Message, bool) {
+	if m.selected == 0 {
+		return domain.Message{}, false
+	}
+	for _, msg := range m.messages {
+		if msg.ID == m.selected {
+			return msg, true
+		}
+	}
+	return domain.Message{}, false
+}
+
+// moveSelection selects the message delta places from the current one.
+// With nothing selected, it starts from the newest message.
+func (m MessageViewModel) moveSelection(delta int) MessageViewModel {
+	if len(m.messages) == 0 {
+		return m
+	}
+	idx := len(m.messages)
+	for i, msg := range m.messages {
+		if msg.ID == m.selected {
+			idx = i
+			break
+		}
+	}
+	if idx == len(m.messages) && delta < 0 {
+		delta++ // first press selects the newest message
+		idx--
+	}
+	idx += delta
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(m.messages) {
+		idx = len(m.messages) - 1
+	}
+	m.selected = m.messages[idx].ID
+	m.buttons = buttonCursor{}
+	m.vote = pollVote{}
+	m = m.renderContentNoScroll()
+
+	// Keep the whole selected message in view where it fits.
+	top, ok := m.lineOf(m.selected)
+	if !ok {
+		return m
+	}
+	bottom := m.viewport.TotalLineCount()
+	if idx+1 < len(m.messages) {
+		if next, ok := m.lineOf(m.messages[idx+1].ID); ok {
+			bottom = next
+		}
+	}
+	switch {
+	case top < m.viewport.YOffset():
+		m.viewport.SetYOffset(top)
+	case bottom > m.viewport.YOffset()+m.viewport.Height():
+		m.viewport.SetYOffset(min(top, bottom-m.viewport.Height()))
+	}
+	return m
+}
+
+// HasMessage reports whether a message is loaded in the view.
+func (m MessageViewModel) HasMessage(id int) bool {
+	_, ok := m.msgOffsets[id]
+	return ok
+}
+
 func (m MessageViewModel) bubbleWidth() int {
 	w := m.viewport.Width() * 9 / 10
 	if w < 20 {
@@ -199,7 +513,7 @@ func (m MessageViewModel) recreateRenderer() MessageViewModel {
 		wordWrap = 10
 	}
 	r, err := glamour.NewTermRenderer(
-		glamour.WithStylePath("dark"),
+		glamour.WithStylePath(glamourStyle),
 		glamour.WithWordWrap(wordWrap),
 	)
 	if err == nil {
@@ -219,6 +533,8 @@ func (m MessageViewModel) renderContent() MessageViewModel {
 func (m MessageViewModel) renderContentInner(gotoBottom bool) MessageViewModel {
 	var b strings.Builder
 	var currentDate string
+	m.msgOffsets = make(map[int]int, len(m.messages))
+	m.gapOffsets = make(map[int]int, len(m.gaps))
 
 	if m.bubbles {
 		prevOut := (*bool)(nil)
@@ -234,6 +550,14 @@ func (m MessageViewModel) renderContentInner(gotoBottom bool) MessageViewModel {
 				prevOut = nil
 			}
 
+			if msg.Kind == domain.MessageService {
+				m.msgOffsets[msg.ID] = b.Len()
+				b.WriteString(m.renderService(msg) + "\n")
+				m = m.writeGap(&b, msg.ID)
+				prevOut = nil
+				continue
+			}
+
 			lastInRun := i+1 >= len(m.messages) || m.messages[i+1].Out != msg.Out ||
 				m.messages[i+1].Timestamp.Format("January 2, 2006") != msgDate
 
@@ -241,10 +565,25 @@ func (m MessageViewModel) renderContentInner(gotoBottom bool) MessageViewModel {
 			if msg.HasMarkdown {
 				text = m.renderMessageText(text)
 			}
+			text = highlightMatches(text, m.highlight...)
+			if msg.ForwardedFrom != "" {
+				text = forwardedStyle.Render("Forwarded from "+msg.ForwardedFrom) + "\n" + text
+			}
+			if msg.Poll != nil {
+				text += "\n\n" + renderPoll(*msg.Poll, min(m.bubbleWidth()-4, maxPollWidth), m.voteFor(msg))
+			}
 
-			result := m.renderBubble(text, msg.Out, true, lastInRun)
-			ts := timeStyle.Render(msg.Timestamp.Format("15:04"))
+			m.msgOffsets[msg.ID] = b.Len()
+			result := m.renderBubble(text, msg.Out, m.bubbleColor(msg), true, lastInRun)
+			ts := m.timestamp(msg)
 			bubbleWithTs := attachTimestamp(result.content, ts, msg.Out, true)
+			if len(msg.Reactions) > 0 {
+				bubbleWithTs += "\n" + renderReactions(msg.Reactions)
+			}
+			if len(msg.Buttons) > 0 {
+				w := min(m.bubbleWidth(), max(result.width, keyboardWidth(msg.Buttons), minKeyboardWidth))
+				bubbleWithTs += "\n" + renderKeyboard(msg.Buttons, w, m.cursorFor(msg))
+			}
 
 			if msg.Out {
 				bubbleLine := lipgloss.NewStyle().Width(m.viewport.Width()).Align(lipgloss.Right).Render(bubbleWithTs)
@@ -252,6 +591,7 @@ func (m MessageViewModel) renderContentInner(gotoBottom bool) MessageViewModel {
 			} else {
 				b.WriteString(bubbleWithTs + "\n")
 			}
+			m = m.writeGap(&b, msg.ID)
 
 			out := msg.Out
 			prevOut = &out
@@ -269,27 +609,59 @@ func (m MessageViewModel) renderContentInner(gotoBottom bool) MessageViewModel {
 				currentDate = msgDate
 			}
 
-			ts := timeStyle.Render(msg.Timestamp.Format("15:04"))
+			if msg.Kind == domain.MessageService {
+				m.msgOffsets[msg.ID] = b.Len()
+				b.WriteString(m.renderService(msg) + "\n")
+				m = m.writeGap(&b, msg.ID)
+				continue
+			}
+
+			ts := m.timestamp(msg)
 
+			m.msgOffsets[msg.ID] = b.Len()
 			var name string
-			if msg.Out {
+			switch {
+			case msg.Out:
 				name = outNameStyle.Render(msg.SenderName + ":")
-			} else {
+			case msg.Mentioned:
+				name = mentionNameStyle.Render("@ " + msg.SenderName + ":")
+			default:
 				name = inNameStyle.Render(msg.SenderName + ":")
 			}
 
+			if msg.ID != 0 && msg.ID == m.selected {
+				ts = selectedMarkerStyle.Render("▌") + ts
+			}
+			if msg.ForwardedFrom != "" {
+				b.WriteString("      " + forwardedStyle.Render("↪ Forwarded from "+msg.ForwardedFrom) + "\n")
+			}
+
 			text := msg.Text
 			multiLine := strings.Contains(text, "\n")
 			if msg.HasMarkdown {
-				rendered := m.renderMessageText(text)
+				rendered := highlightMatches(m.renderMessageText(text), m.highlight...)
 				fmt.Fprintf(&b, "%s %s\n%s\n", ts, name, rendered)
-				b.WriteString("\n")
 			} else if multiLine {
-				fmt.Fprintf(&b, "%s %s\n%s\n", ts, name, text)
-				b.WriteString("\n")
+				fmt.Fprintf(&b, "%s %s\n%s\n", ts, name, highlightMatches(text, m.highlight...))
 			} else {
-				fmt.Fprintf(&b, "%s %s %s\n", ts, name, text)
+				fmt.Fprintf(&b, "%s %s %s\n", ts, name, highlightMatches(text, m.highlight...))
 			}
+			if msg.Poll != nil {
+				poll := renderPoll(*msg.Poll, min(m.viewport.Width()-6, maxPollWidth), m.voteFor(msg))
+				b.WriteString(lipgloss.NewStyle().PaddingLeft(6).Render(poll) + "\n")
+			}
+			if len(msg.Reactions) > 0 {
+				b.WriteString("      " + renderReactions(msg.Reactions) + "\n")
+			}
+			if len(msg.Buttons) > 0 {
+				w := min(m.viewport.Width()-6, max(keyboardWidth(msg.Buttons), minKeyboardWidth))
+				kb := renderKeyboard(msg.Buttons, w, m.cursorFor(msg))
+				b.WriteString(lipgloss.NewStyle().PaddingLeft(6).Render(kb) + "\n")
+			}
+			if msg.HasMarkdown || multiLine {
+				b.WriteString("\n")
+			}
+			m = m.writeGap(&b, msg.ID)
 		}
 	}
 
@@ -298,14 +670,151 @@ func (m MessageViewModel) renderContentInner(gotoBottom bool) MessageViewModel {
 		b.WriteString(typingStyle.Render(fmt.Sprintf("%s is typing...", m.typingUser)))
 	}
 
-	wrapped := lipgloss.NewStyle().Width(m.viewport.Width()).Render(b.String())
-	m.viewport.SetContent(wrapped)
+	m = m.wrapContent(b.String())
 	if gotoBottom {
 		m.viewport.GotoBottom()
 	}
 	return m
 }
 
+// wrapContent wraps the rendered content to the viewport width a line at
+// a time and shows it, recording where each line starts once wrapped.
+func (m MessageViewModel) wrapContent(content string) MessageViewModel {
+	style := lipgloss.NewStyle().Width(m.viewport.Width())
+	lines := strings.Split(content, "\n")
+	wrapped := make([]string, len(lines))
+	m.lineStarts = make(map[int]int, len(lines))
+	off, line := 0, 0
+	for i, l := range lines {
+		m.lineStarts[off] = line
+		wrapped[i] = style.Render(l)
+		off += len(l) + 1
+		line += lipgloss.Height(wrapped[i])
+	}
+	m.viewport.SetContent(strings.Join(wrapped, "\n"))
+	return m
+}
+
+// renderService renders a service message, such as a member joining,
+// centered and dimmed like the day separators.
+func (m MessageViewModel) renderService(msg domain.Message) string {
+	text := daySeparatorStyle.Render(msg.Text)
+	if msg.ID != 0 && msg.ID == m.selected {
+		text = selectedMarkerStyle.Render("▌") + text
+	}
+	return lipgloss.NewStyle().Width(m.viewport.Width()).Align(lipgloss.Center).Render(text)
+}
+
+// timestamp renders a message's time, marked when it is selected.
+func (m MessageViewModel) timestamp(msg domain.Message) string {
+	style := timeStyle
+	if msg.ID != 0 && msg.ID == m.selected {
+		style = selectedTimeStyle
+	}
+	return style.Render(msg.Timestamp.Format("15:04"))
+}
+
+// cursorFor returns the keyboard cursor to draw on a message.
+func (m MessageViewModel) cursorFor(msg domain.Message) buttonCursor {
+	if msg.ID != 0 && msg.ID == m.selected {
+		return m.buttons
+	}
+	return buttonCursor{}
+}
+
+// voteFor returns the voting state to draw on a message's poll.
+func (m MessageViewModel) voteFor(msg domain.Message) pollVote {
+	if msg.ID != 0 && msg.ID == m.selected {
+		return m.vote
+	}
+	return pollVote{}
+}
+
+// FocusPoll starts voting in the selected message's poll.
+func (m MessageViewModel) FocusPoll() MessageViewModel {
+	msg, ok := m.SelectedMessage()
+	if !ok || msg.Poll == nil || msg.Poll.Closed {
+		return m
+	}
+	m.vote = newPollVote(*msg.Poll)
+	return m.renderContentNoScroll()
+}
+
+// IsVoting reports whether keys choose options in a poll.
+func (m MessageViewModel) IsVoting() bool {
+	return m.vote.active
+}
+
+// updateVote handles a key while voting in the selected message's poll.
+// Enter casts the vote and leaves the poll.
+func (m MessageViewModel) updateVote(key tea.KeyMsg) (MessageViewModel, tea.Cmd) {
+	msg, ok := m.SelectedMessage()
+	if !ok || msg.Poll == nil {
+		m.vote = pollVote{}
+		return m.renderContentNoScroll(), nil
+	}
+	var options [][]byte
+	var submitted bool
+	m.vote, options, submitted = m.vote.update(*msg.Poll, key.String())
+	var cmd tea.Cmd
+	if submitted {
+		vote := votePollMsg{chatID: msg.ChatID, msgID: msg.ID, options: options}
+		cmd = func() tea.Msg { return vote }
+	}
+	return m.renderContentNoScroll(), cmd
+}
+
+// FocusButtons starts navigating the selected message's inline keyboard.
+func (m MessageViewModel) FocusButtons() MessageViewModel {
+	msg, ok := m.SelectedMessage()
+	if !ok || len(msg.Buttons) == 0 {
+		return m
+	}
+	m.buttons = buttonCursor{active: true}
+	return m.renderContentNoScroll()
+}
+
+// IsPressingButtons reports whether keys navigate a message's keyboard.
+func (m MessageViewModel) IsPressingButtons() bool {
+	return m.buttons.active
+}
+
+// updateButtons handles a key while navigating the selected message's
+// keyboard. Enter presses the highlighted button and leaves the keyboard.
+func (m MessageViewModel) updateButtons(key tea.KeyMsg) (MessageViewModel, tea.Cmd) {
+	msg, ok := m.SelectedMessage()
+	if !ok || len(msg.Buttons) == 0 {
+		m.buttons = buttonCursor{}
+		return m.renderContentNoScroll(), nil
+	}
+	var pressed bool
+	m.buttons, pressed = m.buttons.update(msg.Buttons, key.String())
+	var cmd tea.Cmd
+	if pressed {
+		if b, ok := m.buttons.selected(msg.Buttons); ok {
+			press := pressButtonMsg{chatID: msg.ChatID, msgID: msg.ID, button: b}
+			cmd = func() tea.Msg { return press }
+		}
+		m.buttons = buttonCursor{}
+	}
+	return m.renderContentNoScroll(), cmd
+}
+
+// writeGap writes the marker for missing history after a message, if any,
+// recording its offset so scrolling to it can trigger a load.
+func (m MessageViewModel) writeGap(b *strings.Builder, id int) MessageViewModel {
+	if !m.gaps[id] {
+		return m
+	}
+	m.gapOffsets[id] = b.Len()
+	label := "┄┄┄ more messages ┄┄┄"
+	if m.loadingNew {
+		label = "┄┄┄ loading… ┄┄┄"
+	}
+	b.WriteString(gapStyle.Render(label) + "\n")
+	return m
+}
+
 func (m MessageViewModel) renderMessageText(text string) string {
 	if m.renderer == nil {
 		return text
@@ -422,9 +931,11 @@ func splitTableRow(line string) []string {
 	return cells
 }
 
+// Bubble colors, set by SetTheme.
 var (
-	receivedBubbleColor = lipgloss.Color("#8C6161")
-	sentBubbleColor     = lipgloss.Color("#7B5EA7")
+	receivedBubbleColor color.Color
+	sentBubbleColor     color.Color
+	selectedBubbleColor color.Color
 )
 
 // attachTimestamp places the timestamp next to the first line of the bubble.
@@ -454,15 +965,41 @@ type bubbleResult struct {
 	width   int
 }
 
+// bubbleColor picks a message's bubble border: the selection highlight,
+// then mentions of us, then sent vs received.
+func (m MessageViewModel) bubbleColor(msg domain.Message) color.Color {
+	switch {
+	case msg.ID != 0 && msg.ID == m.selected:
+		return selectedBubbleColor
+	case msg.Out:
+		return sentBubbleColor
+	case msg.Mentioned:
+		return mentionColor
+	default:
+		return receivedBubbleColor
+	}
+}
+
+// renderReactions renders reaction tallies as "👍 3  ❤ 1", highlighting the
+// ones we chose.
+func renderReactions(reactions []domain.Reaction) string {
+	parts := make([]string, len(reactions))
+	for i, r := range reactions {
+		tally := fmt.Sprintf("%s %d", r.Emoji, r.Count)
+		if r.Chosen {
+			parts[i] = reactionChosenStyle.Render(tally)
+		} else {
+			parts[i] = reactionStyle.Render(tally)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
 // renderBubble wraps text in a speech bubble segment.
 // showTop controls the top border, showTail controls whether the tail is drawn
 // on the bottom border.
-func (m MessageViewModel) renderBubble(text string, sent bool, showTop bool, showTail bool) bubbleResult {
+func (m MessageViewModel) renderBubble(text string, sent bool, borderColor color.Color, showTop bool, showTail bool) bubbleResult {
 	maxW := m.bubbleWidth()
-	borderColor := receivedBubbleColor
-	if sent {
-		borderColor = sentBubbleColor
-	}
 
 	sc := func(ch string) string {
 		return lipgloss.NewStyle().Foreground(borderColor).Render(ch)
@@ -601,15 +1138,14 @@ func renderJoinLine(prevW, currW int, sent bool, borderColor color.Color) string
 	return sc("│") + strings.Repeat(" ", narrow-2) + sc("╭") + sc(strings.Repeat("─", gap-1)) + sc("╯")
 }
 
+// Table colors and styles, set by SetTheme.
 var (
-	tablePurple    = lipgloss.Color("99")
-	tableGray      = lipgloss.Color("245")
-	tableLightGray = lipgloss.Color("241")
+	tableBorderColor color.Color
 
-	tableHeaderStyle  = lipgloss.NewStyle().Foreground(tablePurple).Bold(true).Align(lipgloss.Center)
+	tableHeaderStyle  lipgloss.Style
 	tableCellStyle    = lipgloss.NewStyle().Padding(0, 1)
-	tableOddRowStyle  = tableCellStyle.Foreground(tableGray)
-	tableEvenRowStyle = tableCellStyle.Foreground(tableLightGray)
+	tableOddRowStyle  lipgloss.Style
+	tableEvenRowStyle lipgloss.Style
 )
 
 // renderTable parses a markdown table block and renders it using lipgloss table.
@@ -626,7 +1162,7 @@ func renderTable(block string) string {
 
 	t := table.New().
 		Border(lipgloss.NormalBorder()).
-		BorderStyle(lipgloss.NewStyle().Foreground(tablePurple)).
+		BorderStyle(lipgloss.NewStyle().Foreground(tableBorderColor)).
 		StyleFunc(func(row, col int) lipgloss.Style {
 			switch {
 			case row == table.HeaderRow:
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+func TestMessageView_LineOfWrappedContent(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	long := strings.Repeat("word ", 40)
+	msgs := []domain.Message{
+		{ID: 1, ChatID: 1, SenderName: "Ana", Text: long, Timestamp: ts},
+		{ID: 2, ChatID: 1, SenderName: "Bea", Text: long, Timestamp: ts},
+		{ID: 3, ChatID: 1, SenderName: "Cid", Text: "short", Timestamp: ts},
+	}
+	m := NewMessageViewModel(false).SetSize(30, 10).SetMessages(msgs, []int{2})
+
+	lines := strings.Split(m.viewport.GetContent(), "\n")
+	for _, msg := range msgs {
+		line, ok := m.lineOf(msg.ID)
+		if !ok {
+			t.Fatalf("message %d has no line", msg.ID)
+		}
+		if line >= len(lines) || !strings.Contains(lines[line], msg.SenderName) {
+			t.Errorf("message %d: line %d doesn't start it", msg.ID, line)
+		}
+	}
+	gap := m.lineAt(m.gapOffsets[2])
+	if !strings.Contains(lines[gap], "more messages") {
+		t.Errorf("gap marker: line %d = %q", gap, lines[gap])
+	}
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// muteOption is a single entry in the mute menu. A zero duration with
+// forever unset means "unmute".
+type muteOption struct {
+	label    string
+	duration time.Duration
+	forever  bool
+}
+
+var muteOptions = []muteOption{
+	{label: "Mute for 1 hour", duration: time.Hour},
+	{label: "Mute for 8 hours", duration: 8 * time.Hour},
+	{label: "Mute for 1 day", duration: 24 * time.Hour},
+	{label: "Mute for 1 week", duration: 7 * 24 * time.Hour},
+	{label: "Mute forever", forever: true},
+}
+
+// muteForever is the mute_until value Telegram clients use for "forever".
+var muteForever = time.Unix(math.MaxInt32, 0)
+
+// MuteMenuModel renders a centered overlay for choosing how long to mute
+// a chat, or unmuting it.
+type MuteMenuModel struct {
+	visible       bool
+	chatID        int64
+	chatTitle     string
+	options       []muteOption
+	cursor        int
+	width, height int
+}
+
+// NewMuteMenuModel creates a hidden mute menu.
+func NewMuteMenuModel() MuteMenuModel {
+	return MuteMenuModel{}
+}
+
+// IsVisible reports whether the mute menu is showing.
+func (m MuteMenuModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the menu for a chat. Muted chats get an extra "Unmute" entry
+// at the top.
+func (m MuteMenuModel) Show(chatID int64, title string, muted bool) MuteMenuModel {
+	m.visible = true
+	m.chatID = chatID
+	m.chatTitle = title
+	m.cursor = 0
+	m.options = nil
+	if muted {
+		m.options = append(m.options, muteOption{label: "Unmute"})
+	}
+	m.options = append(m.options, muteOptions...)
+	return m
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m MuteMenuModel) SetSize(w, h int) MuteMenuModel {
+	m.width = w
+	m.height = h
+	return m
+}
+
+func (m MuteMenuModel) Update(msg tea.Msg) (MuteMenuModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "j", "down":
+		if m.cursor < len(m.options)-1 {
+			m.cursor++
+		}
+	case "k", "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "esc", "q", "m":
+		m.visible = false
+	case "enter":
+		opt := m.options[m.cursor]
+		var until time.Time
+		switch {
+		case opt.forever:
+			until = muteForever
+		case opt.duration > 0:
+			until = time.Now().Add(opt.duration)
+		}
+		chatID := m.chatID
+		m.visible = false
+		return m, func() tea.Msg {
+			return muteChatMsg{chatID: chatID, until: until}
+		}
+	}
+	return m, nil
+}
+
+// View renders the menu box (without full-screen placement).
+func (m MuteMenuModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(chatListHeaderStyle.Render("Notifications: "+m.chatTitle) + "\n\n")
+	for i, opt := range m.options {
+		if i == m.cursor {
+			b.WriteString("> " + listSelectedStyle.Render(opt.label))
+		} else {
+			b.WriteString("  " + opt.label)
+		}
+		if i < len(m.options)-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 3).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(b.String())
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"slices"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// overlay is a box drawn centered over the panes. While one is visible it
+// takes every key, so the panes below don't see them.
+type overlay interface {
+	IsVisible() bool
+	View() string
+}
+
+// modal pairs an overlay with how the root model hands it a key.
+type modal struct {
+	overlay
+	handleKey func(Model, tea.KeyMsg) (Model, tea.Cmd)
+}
+
+// modals returns the overlays in priority order: the first visible one
+// takes keys and is the one drawn. The alert comes early, since a bot's
+// answer may arrive while another overlay is open.
+func (m Model) modals() []modal {
+	return []modal{
+		{m.splash, func(m Model, _ tea.KeyMsg) (Model, tea.Cmd) { return m, nil }},
+		{m.alert, updating(func(m *Model) *AlertModel { return &m.alert })},
+		{m.help, Model.helpKey},
+		{m.muteMenu, updating(func(m *Model) *MuteMenuModel { return &m.muteMenu })},
+		{m.emojiPicker, updating(func(m *Model) *EmojiPickerModel { return &m.emojiPicker })},
+		{m.global, updating(func(m *Model) *GlobalSearchModel { return &m.global })},
+		{m.search, Model.searchKey},
+		{m.jumpPrompt, updating(func(m *Model) *JumpPromptModel { return &m.jumpPrompt })},
+		{m.reactions, updating(func(m *Model) *ReactionPickerModel { return &m.reactions })},
+		{m.forward, updating(func(m *Model) *ForwardPickerModel { return &m.forward })},
+		{m.switcher, updating(func(m *Model) *SwitcherModel { return &m.switcher })},
+		{m.palette, updating(func(m *Model) *CommandPaletteModel { return &m.palette })},
+		{m.pollForm, updating(func(m *Model) *PollFormModel { return &m.pollForm })},
+	}
+}
+
+// activeModal returns the visible overlay, if any.
+func (m Model) activeModal() (modal, bool) {
+	for _, o := range m.modals() {
+		if o.IsVisible() {
+			return o, true
+		}
+	}
+	return modal{}, false
+}
+
+// updating adapts the Update method of one of the model's overlays to a
+// modal's key handler.
+func updating[T interface{ Update(tea.Msg) (T, tea.Cmd) }](field func(*Model) *T) func(Model, tea.KeyMsg) (Model, tea.Cmd) {
+	return func(m Model, key tea.KeyMsg) (Model, tea.Cmd) {
+		f := field(&m)
+		var cmd tea.Cmd
+		*f, cmd = (*f).Update(key)
+		return m, cmd
+	}
+}
+
+// helpKey closes the help overlay on Esc, q or the help key. The help
+// action's bindings are checked directly: actionForKey skips printable
+// keys while the input has focus, which would leave "h" dead here.
+func (m Model) helpKey(key tea.KeyMsg) (Model, tea.Cmd) {
+	switch key.String() {
+	case "esc", "q":
+		m.help = m.help.Toggle()
+		return m, nil
+	}
+	for _, a := range m.actions {
+		if a.name == "help" && slices.Contains(a.keys, key.String()) {
+			m.help = m.help.Toggle()
+			break
+		}
+	}
+	return m, nil
+}
+
+// searchKey passes a key to the search overlay, searching cached messages
+// on every keystroke.
+func (m Model) searchKey(key tea.KeyMsg) (Model, tea.Cmd) {
+	typed := m.search.Input()
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(key)
+	if q := m.search.Input(); q != typed {
+		m.search = m.search.SetLocalResults(q, m.store.Search(m.store.GetActiveChat(), q, localSearchLimit))
+	}
+	return m, cmd
+}
+
+// centerOffset returns the (x, y) that centers a box in a w×h area.
+func centerOffset(box string, w, h int) (int, int) {
+	return max((w-lipgloss.Width(box))/2, 0), max((h-lipgloss.Height(box))/2, 0)
+}
+
+// drawOver composites a box over the background at (x, y).
+func drawOver(bg, box string, x, y int) string {
+	fg := lipgloss.NewLayer(box).X(x).Y(y).Z(1)
+	return lipgloss.NewCompositor(lipgloss.NewLayer(bg), fg).Render()
+}
@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// paletteVisible is how many commands the palette lists at once.
+const paletteVisible = 12
+
+// paletteEntry is an action offered by the command palette.
+type paletteEntry struct {
+	name  string
+	title string
+	keys  string // formatted bindings, empty if unbound
+}
+
+// CommandPaletteModel renders a centered overlay listing the actions
+// available right now, fuzzy filtered by title.
+type CommandPaletteModel struct {
+	visible bool
+	input   textinput.Model
+	entries []paletteEntry
+	matches []paletteEntry
+	cursor  int
+
+	width, height int
+}
+
+// NewCommandPaletteModel creates a hidden command palette.
+func NewCommandPaletteModel() CommandPaletteModel {
+	ti := textinput.New()
+	ti.Placeholder = "type a command"
+	ti.Prompt = ": "
+	ti.CharLimit = 64
+	return CommandPaletteModel{input: ti}
+}
+
+// IsVisible reports whether the palette is showing.
+func (m CommandPaletteModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the palette with the given commands and an empty filter.
+func (m CommandPaletteModel) Show(entries []paletteEntry) (CommandPaletteModel, tea.Cmd) {
+	m.visible = true
+	m.entries = entries
+	m.input.SetValue("")
+	m = m.filter()
+	return m, m.input.Focus()
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m CommandPaletteModel) SetSize(w, h int) CommandPaletteModel {
+	m.width = w
+	m.height = h
+	m.input.SetWidth(m.boxWidth() - 4)
+	return m
+}
+
+// boxWidth is the width of the overlay's content.
+func (m CommandPaletteModel) boxWidth() int {
+	return max(min(70, m.width-10), 20)
+}
+
+func (m CommandPaletteModel) Update(msg tea.Msg) (CommandPaletteModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.visible = false
+			m.input.Blur()
+			return m, nil
+		case "up", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down", "ctrl+n", "tab":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case "enter":
+			if len(m.matches) == 0 {
+				return m, nil
+			}
+			name := m.matches[m.cursor].name
+			m.visible = false
+			m.input.Blur()
+			return m, func() tea.Msg { return runActionMsg{name: name} }
+		}
+	}
+
+	query := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != query {
+		m = m.filter()
+	}
+	return m, cmd
+}
+
+// filter narrows the commands to those matching the typed text, best
+// match first.
+func (m CommandPaletteModel) filter() CommandPaletteModel {
+	query := strings.TrimSpace(m.input.Value())
+	m.cursor = 0
+	if query == "" {
+		m.matches = m.entries
+		return m
+	}
+	type scored struct {
+		entry paletteEntry
+		score int
+	}
+	var hits []scored
+	for _, e := range m.entries {
+		s, ok := fuzzyScore(query, e.title)
+		if ns, nok := fuzzyScore(query, e.name); nok && (!ok || ns > s) {
+			s, ok = ns, true
+		}
+		if ok {
+			hits = append(hits, scored{e, s})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	m.matches = make([]paletteEntry, len(hits))
+	for i, h := range hits {
+		m.matches[i] = h.entry
+	}
+	return m
+}
+
+// View renders the overlay box (without full-screen placement).
+func (m CommandPaletteModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+	w := m.boxWidth()
+
+	var b strings.Builder
+	b.WriteString(chatListHeaderStyle.Render("Commands"))
+	b.WriteString("\n\n" + m.input.View() + "\n\n")
+
+	if len(m.matches) == 0 {
+		b.WriteString(timeStyle.Render("No matching commands"))
+	} else {
+		start := max(0, min(m.cursor-paletteVisible/2, len(m.matches)-paletteVisible))
+		end := min(start+paletteVisible, len(m.matches))
+		var lines []string
+		for i := start; i < end; i++ {
+			e := m.matches[i]
+			cursor := "  "
+			titleStyle := lipgloss.NewStyle()
+			if i == m.cursor {
+				cursor = "> "
+				titleStyle = titleStyle.Inherit(listSelectedStyle)
+			}
+			// The name is what the keys: config section binds.
+			keys := timeStyle.Render(e.keys)
+			title := titleStyle.Render(e.title) + timeStyle.Render("  "+e.name)
+			title = lipgloss.NewStyle().MaxWidth(max(w-2-lipgloss.Width(keys)-1, 1)).Render(title)
+			gap := max(w-2-lipgloss.Width(title)-lipgloss.Width(keys), 1)
+			lines = append(lines, cursor+title+strings.Repeat(" ", gap)+keys)
+		}
+		b.WriteString(strings.Join(lines, "\n"))
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 2).
+		Width(w + 6).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(b.String())
+}
@@ -0,0 +1,377 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// maxPollWidth keeps poll bars short on wide terminals.
+const maxPollWidth = 40
+
+// Limits Telegram puts on polls.
+const (
+	maxPollOptions  = 10
+	maxPollQuestion = 255
+	maxPollOption   = 100
+)
+
+// pollVote is the state of voting in the selected message's poll.
+type pollVote struct {
+	active bool
+	cursor int
+	picked []bool // options picked so far, for multiple-choice polls
+}
+
+// newPollVote starts voting in p, with our current choices picked.
+func newPollVote(p domain.Poll) pollVote {
+	v := pollVote{active: true, picked: make([]bool, len(p.Options))}
+	for i, o := range p.Options {
+		v.picked[i] = o.Chosen
+		if o.Chosen && v.cursor == 0 {
+			v.cursor = i
+		}
+	}
+	return v
+}
+
+// update handles a key while voting. It returns the options to vote for
+// once Enter submits: the one under the cursor, or those picked in a
+// multiple-choice poll, where picking none retracts our vote.
+func (v pollVote) update(p domain.Poll, key string) (pollVote, [][]byte, bool) {
+	switch key {
+	case "up", "k":
+		v.cursor = max(v.cursor-1, 0)
+	case "down", "j":
+		v.cursor = min(v.cursor+1, len(p.Options)-1)
+	case "space", "x":
+		if p.Multiple && v.cursor < len(v.picked) {
+			// Copies of the vote share picked; change our own.
+			v.picked = slices.Clone(v.picked)
+			v.picked[v.cursor] = !v.picked[v.cursor]
+		}
+	case "esc", "q":
+		v.active = false
+	case "enter":
+		v.active = false
+		if !p.Multiple {
+			return v, [][]byte{p.Options[v.cursor].Option}, true
+		}
+		options := [][]byte{}
+		for i, picked := range v.picked {
+			if picked {
+				options = append(options, p.Options[i].Option)
+			}
+		}
+		return v, options, true
+	}
+	return v, nil, false
+}
+
+// renderPoll renders a poll's options under its question, with vote
+// counts once we've voted or the poll has closed, as in other Telegram
+// apps. vote shows the cursor and picks while voting.
+func renderPoll(p domain.Poll, width int, vote pollVote) string {
+	showResults := p.HasResults && (p.Voted() || p.Closed)
+	width = max(width, 10)
+
+	var lines []string
+	for i, o := range p.Options {
+		chosen := o.Chosen
+		if vote.active && i < len(vote.picked) {
+			chosen = vote.picked[i]
+		}
+		mark := "○"
+		switch {
+		case p.Multiple && chosen:
+			mark = "☑"
+		case p.Multiple:
+			mark = "☐"
+		case chosen:
+			mark = "●"
+		}
+		if p.Quiz && o.Correct {
+			mark = "✓"
+		}
+
+		line := mark + " " + o.Text
+		pct := 0
+		if showResults && p.Voters > 0 {
+			pct = (o.Voters*100 + p.Voters/2) / p.Voters
+		}
+		if showResults {
+			label := fmt.Sprintf("%d%%", pct)
+			text := truncate(line, width-lipgloss.Width(label)-1)
+			line = text + strings.Repeat(" ", max(width-lipgloss.Width(text)-lipgloss.Width(label), 1)) + label
+		} else {
+			line = truncate(line, width)
+		}
+		if vote.active && i == vote.cursor {
+			line = buttonSelectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+		if showResults {
+			bar := (width - 2) * pct / 100
+			lines = append(lines, "  "+buttonStyle.Render(strings.Repeat("█", bar))+timeStyle.Render(strings.Repeat("░", width-2-bar)))
+		}
+	}
+
+	lines = append(lines, timeStyle.Render(pollFooter(p, vote)))
+	return strings.Join(lines, "\n")
+}
+
+// pollFooter describes a poll's kind and vote count, or how to vote while
+// voting.
+func pollFooter(p domain.Poll, vote pollVote) string {
+	if vote.active {
+		if p.Multiple {
+			return "space to pick, enter to vote"
+		}
+		return "enter to vote, esc to cancel"
+	}
+	kind := "Poll"
+	if p.Quiz {
+		kind = "Quiz"
+	}
+	if !p.Public {
+		kind = "Anonymous " + strings.ToLower(kind)
+	}
+	votes := fmt.Sprintf("%d votes", p.Voters)
+	if p.Voters == 1 {
+		votes = "1 vote"
+	}
+	footer := kind + " · " + votes
+	if p.Closed {
+		footer += " · closed"
+	}
+	return footer
+}
+
+// Settings rows of the poll form, after its text fields.
+const (
+	pollFieldMultiple = iota
+	pollFieldAnonymous
+	pollToggleFields
+)
+
+// PollFormModel renders a centered form for creating a poll: a question,
+// options that grow as they are filled, and settings.
+type PollFormModel struct {
+	visible       bool
+	chatID        int64
+	question      textinput.Model
+	options       []textinput.Model
+	multiple      bool
+	anonymous     bool
+	focus         int // question, then options, then the toggles
+	err           error
+	width, height int
+}
+
+// NewPollFormModel creates a hidden poll form.
+func NewPollFormModel() PollFormModel {
+	return PollFormModel{}
+}
+
+// IsVisible reports whether the form is showing.
+func (m PollFormModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens an empty form for a poll in a chat, with the question
+// filled in if given.
+func (m PollFormModel) Show(chatID int64, question string) (PollFormModel, tea.Cmd) {
+	m.visible = true
+	m.chatID = chatID
+	m.err = nil
+	m.multiple = false
+	m.anonymous = true
+	m.question = newPollInput("Question", maxPollQuestion)
+	m.question.SetValue(question)
+	m.options = []textinput.Model{
+		newPollInput("Option 1", maxPollOption),
+		newPollInput("Option 2", maxPollOption),
+	}
+	m.focus = 0
+	if question != "" {
+		m.focus = 1
+	}
+	m = m.SetSize(m.width, m.height)
+	return m, m.focusField()
+}
+
+func newPollInput(placeholder string, limit int) textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.Prompt = "> "
+	ti.CharLimit = limit
+	return ti
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m PollFormModel) SetSize(w, h int) PollFormModel {
+	m.width = w
+	m.height = h
+	fieldWidth := min(50, max(w-16, 10))
+	m.question.SetWidth(fieldWidth)
+	for i := range m.options {
+		m.options[i].SetWidth(fieldWidth)
+	}
+	return m
+}
+
+// fields is how many rows the form's focus moves through.
+func (m PollFormModel) fields() int {
+	return 1 + len(m.options) + pollToggleFields
+}
+
+// toggleField returns which toggle has focus, or -1 for a text field.
+func (m PollFormModel) toggleField() int {
+	if m.focus <= len(m.options) {
+		return -1
+	}
+	return m.focus - len(m.options) - 1
+}
+
+// focusField focuses the text field under the form's focus, blurring the
+// others.
+func (m *PollFormModel) focusField() tea.Cmd {
+	m.question.Blur()
+	for i := range m.options {
+		m.options[i].Blur()
+	}
+	switch {
+	case m.focus == 0:
+		return m.question.Focus()
+	case m.focus <= len(m.options):
+		return m.options[m.focus-1].Focus()
+	}
+	return nil
+}
+
+func (m PollFormModel) Update(msg tea.Msg) (PollFormModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.visible = false
+			return m, nil
+		case "ctrl+s":
+			poll, err := m.poll()
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.visible = false
+			send := sendPollMsg{chatID: m.chatID, poll: poll}
+			return m, func() tea.Msg { return send }
+		case "tab", "down", "enter":
+			if key.String() == "enter" && m.toggleField() >= 0 {
+				return m.toggle(), nil
+			}
+			m.focus = (m.focus + 1) % m.fields()
+			return m, m.focusField()
+		case "shift+tab", "up":
+			m.focus = (m.focus - 1 + m.fields()) % m.fields()
+			return m, m.focusField()
+		case "space":
+			if m.toggleField() >= 0 {
+				return m.toggle(), nil
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	switch {
+	case m.focus == 0:
+		m.question, cmd = m.question.Update(msg)
+	case m.focus <= len(m.options):
+		m.options[m.focus-1], cmd = m.options[m.focus-1].Update(msg)
+		// Filling the last option offers another.
+		last := m.options[len(m.options)-1]
+		if last.Value() != "" && len(m.options) < maxPollOptions {
+			m.options = append(m.options, newPollInput(fmt.Sprintf("Option %d", len(m.options)+1), maxPollOption))
+			m = m.SetSize(m.width, m.height)
+		}
+	}
+	m.err = nil
+	return m, cmd
+}
+
+// toggle flips the setting under the form's focus.
+func (m PollFormModel) toggle() PollFormModel {
+	switch m.toggleField() {
+	case pollFieldMultiple:
+		m.multiple = !m.multiple
+	case pollFieldAnonymous:
+		m.anonymous = !m.anonymous
+	}
+	return m
+}
+
+// poll builds the poll to send, checking it has a question and at least
+// two options. Blank options are skipped.
+func (m PollFormModel) poll() (domain.Poll, error) {
+	p := domain.Poll{
+		Question: strings.TrimSpace(m.question.Value()),
+		Multiple: m.multiple,
+		Public:   !m.anonymous,
+	}
+	if p.Question == "" {
+		return domain.Poll{}, errors.New("the poll needs a question")
+	}
+	for _, o := range m.options {
+		if text := strings.TrimSpace(o.Value()); text != "" {
+			p.Options = append(p.Options, domain.PollOption{Text: text})
+		}
+	}
+	if len(p.Options) < 2 {
+		return domain.Poll{}, errors.New("the poll needs at least 2 options")
+	}
+	return p, nil
+}
+
+// View renders the form box (without full-screen placement).
+func (m PollFormModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	lines := []string{chatListHeaderStyle.Render("New poll"), "", m.question.View(), ""}
+	for _, o := range m.options {
+		lines = append(lines, o.View())
+	}
+	lines = append(lines, "",
+		m.toggleView(pollFieldMultiple, "Multiple answers", m.multiple),
+		m.toggleView(pollFieldAnonymous, "Anonymous voting", m.anonymous))
+	if m.err != nil {
+		lines = append(lines, "", errorStyle.Render(m.err.Error()))
+	}
+	lines = append(lines, "", timeStyle.Render("tab to move, space to toggle, ctrl+s to send, esc to cancel"))
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 3).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(strings.Join(lines, "\n"))
+}
+
+// toggleView renders a setting as a checkbox, highlighted under focus.
+func (m PollFormModel) toggleView(field int, label string, on bool) string {
+	box := "[ ]"
+	if on {
+		box = "[x]"
+	}
+	line := box + " " + label
+	if m.toggleField() == field {
+		return "> " + listSelectedStyle.Render(line)
+	}
+	return "  " + line
+}
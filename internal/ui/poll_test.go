@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+func TestPollVote_PickCopies(t *testing.T) {
+	p := domain.Poll{Multiple: true, Options: []domain.PollOption{{Text: "a"}, {Text: "b"}}}
+	v := newPollVote(p)
+	next, _, _ := v.update(p, "space")
+	if !next.picked[0] {
+		t.Fatal("space didn't pick the option under the cursor")
+	}
+	if v.picked[0] {
+		t.Error("picking changed the earlier copy of the vote")
+	}
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// defaultReactions is offered when a chat allows any reaction. It mirrors
+// the quick-reaction bar of the official clients.
+var defaultReactions = []string{
+	"👍", "👎", "❤", "🔥", "🥰", "👏", "😁", "🤔",
+	"🤯", "😱", "🤬", "😢", "🎉", "🤩", "🙏", "👌",
+}
+
+// reactionPickerCols is how many reactions fit on one row of the picker.
+const reactionPickerCols = 8
+
+// ReactionPickerModel renders a centered overlay for reacting to a message.
+// Picking a reaction we already chose removes it.
+type ReactionPickerModel struct {
+	visible       bool
+	chatID        int64
+	msgID         int
+	options       []string
+	chosen        map[string]bool
+	cursor        int
+	width, height int
+}
+
+// NewReactionPickerModel creates a hidden reaction picker.
+func NewReactionPickerModel() ReactionPickerModel {
+	return ReactionPickerModel{}
+}
+
+// IsVisible reports whether the picker is showing.
+func (m ReactionPickerModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the picker for a message. A nil allowed list means the chat
+// accepts any reaction, so the default quick reactions are offered.
+func (m ReactionPickerModel) Show(msg domain.Message, allowed []string) ReactionPickerModel {
+	m.visible = true
+	m.chatID = msg.ChatID
+	m.msgID = msg.ID
+	m.cursor = 0
+	m.options = allowed
+	if m.options == nil {
+		m.options = defaultReactions
+	}
+	m.chosen = make(map[string]bool)
+	for _, r := range msg.Reactions {
+		if r.Chosen {
+			m.chosen[r.Emoji] = true
+		}
+	}
+	return m
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m ReactionPickerModel) SetSize(w, h int) ReactionPickerModel {
+	m.width = w
+	m.height = h
+	return m
+}
+
+func (m ReactionPickerModel) Update(msg tea.Msg) (ReactionPickerModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "l", "right":
+		m = m.moveCursor(1)
+	case "h", "left":
+		m = m.moveCursor(-1)
+	case "j", "down":
+		m = m.moveCursor(reactionPickerCols)
+	case "k", "up":
+		m = m.moveCursor(-reactionPickerCols)
+	case "esc", "q", "r":
+		m.visible = false
+	case "enter":
+		if len(m.options) == 0 {
+			return m, nil
+		}
+		emoji := m.options[m.cursor]
+		if m.chosen[emoji] {
+			emoji = ""
+		}
+		chatID, msgID := m.chatID, m.msgID
+		m.visible = false
+		return m, func() tea.Msg {
+			return reactMsg{chatID: chatID, msgID: msgID, emoji: emoji}
+		}
+	}
+	return m, nil
+}
+
+func (m ReactionPickerModel) moveCursor(delta int) ReactionPickerModel {
+	next := m.cursor + delta
+	if next >= 0 && next < len(m.options) {
+		m.cursor = next
+	}
+	return m
+}
+
+// View renders the picker box (without full-screen placement).
+func (m ReactionPickerModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(chatListHeaderStyle.Render("React") + "\n\n")
+	if len(m.options) == 0 {
+		b.WriteString(timeStyle.Render("Reactions are disabled in this chat"))
+	}
+
+	selected := lipgloss.NewStyle().Reverse(true)
+	for i, r := range m.options {
+		cell := r
+		if m.chosen[r] {
+			cell = reactionChosenStyle.Render(cell)
+		}
+		if i == m.cursor {
+			cell = selected.Render(cell)
+		}
+		b.WriteString(cell + " ")
+		if (i+1)%reactionPickerCols == 0 && i < len(m.options)-1 {
+			b.WriteString("\n")
+		}
+	}
+	if len(m.options) > 0 && m.chosen[m.options[m.cursor]] {
+		b.WriteString("\n\n" + timeStyle.Render("enter removes your reaction"))
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 3).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(b.String())
+}
@@ -0,0 +1,429 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+	"github.com/danhigham/telecharm/internal/state"
+	"github.com/danhigham/telecharm/internal/telegram"
+)
+
+const (
+	// searchVisible is how many results the search overlay lists at once.
+	searchVisible = 8
+	// snippetContext is how many characters of context surround a match
+	// in a result snippet.
+	snippetContext = 30
+	// localSearchLimit caps the cached results listed while typing.
+	localSearchLimit = 100
+)
+
+// searchFilters is the order Tab cycles through.
+var searchFilters = []telegram.SearchFilter{
+	telegram.SearchAll,
+	telegram.SearchPhotos,
+	telegram.SearchVideos,
+	telegram.SearchDocuments,
+	telegram.SearchLinks,
+	telegram.SearchVoice,
+}
+
+// SearchModel renders a centered overlay for searching the active chat.
+// While the user types, results come from the local index of cached
+// messages; the server is searched on request. Results stay available
+// after the overlay closes so n/N can step through them.
+type SearchModel struct {
+	visible   bool
+	input     textinput.Model
+	filter    int // index into searchFilters
+	query     string
+	searched  telegram.SearchFilter
+	results   []domain.Message
+	local     bool // results come from the local index
+	cursor    int
+	visited   bool // the hit under the cursor has been jumped to
+	searching bool
+	err       error
+
+	width, height int
+}
+
+// NewSearchModel creates a hidden search overlay.
+func NewSearchModel() SearchModel {
+	ti := textinput.New()
+	ti.Placeholder = "search this chat"
+	ti.Prompt = "/ "
+	ti.CharLimit = 128
+	return SearchModel{input: ti}
+}
+
+// IsVisible reports whether the overlay is showing.
+func (m SearchModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the overlay, keeping the previous query and results.
+func (m SearchModel) Show() (SearchModel, tea.Cmd) {
+	m.visible = true
+	return m, m.input.Focus()
+}
+
+// SetQuery replaces the query being typed.
+func (m SearchModel) SetQuery(query string) SearchModel {
+	m.input.SetValue(query)
+	return m
+}
+
+// Reset forgets the query and results, e.g. when switching chats.
+func (m SearchModel) Reset() SearchModel {
+	m.visible = false
+	m.input.Blur()
+	m.input.SetValue("")
+	m.filter = 0
+	m.query = ""
+	m.results = nil
+	m.local = false
+	m.cursor = 0
+	m.visited = false
+	m.searching = false
+	m.err = nil
+	return m
+}
+
+// Input returns the query as typed so far.
+func (m SearchModel) Input() string {
+	return m.input.Value()
+}
+
+// Terms returns the words and phrases of the current results' query, for
+// highlighting.
+func (m SearchModel) Terms() []string {
+	return state.ParseQuery(m.query).Terms()
+}
+
+// SetResults shows the results of a server search, unless the query has
+// been edited since it was sent.
+func (m SearchModel) SetResults(query string, filter telegram.SearchFilter, msgs []domain.Message, err error) SearchModel {
+	if query != strings.TrimSpace(m.input.Value()) {
+		return m
+	}
+	m.searching = false
+	m.query = query
+	m.searched = filter
+	m.results = msgs
+	m.local = false
+	m.cursor = 0
+	m.visited = false
+	m.err = err
+	return m
+}
+
+// SetLocalResults shows cached messages matching the query being typed.
+// Content filters need the server, so it does nothing unless the filter
+// is "all".
+func (m SearchModel) SetLocalResults(query string, msgs []domain.Message) SearchModel {
+	if searchFilters[m.filter] != telegram.SearchAll {
+		return m
+	}
+	m.searching = false
+	m.query = strings.TrimSpace(query)
+	m.searched = telegram.SearchAll
+	m.results = msgs
+	if m.query != "" && msgs == nil {
+		m.results = []domain.Message{}
+	}
+	m.local = true
+	m.cursor = 0
+	m.visited = false
+	m.err = nil
+	return m
+}
+
+// Step moves to the next (delta 1, older) or previous (delta -1, newer)
+// hit and returns it. The first step after the results are listed goes
+// to the hit under the cursor, since it hasn't been visited yet.
+func (m SearchModel) Step(delta int) (SearchModel, domain.Message, bool) {
+	if len(m.results) == 0 {
+		return m, domain.Message{}, false
+	}
+	if m.visited {
+		m.cursor = (m.cursor + delta + len(m.results)) % len(m.results)
+	}
+	m.visited = true
+	return m, m.results[m.cursor], true
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m SearchModel) SetSize(w, h int) SearchModel {
+	m.width = w
+	m.height = h
+	m.input.SetWidth(m.boxWidth() - 4)
+	return m
+}
+
+// boxWidth is the width of the overlay's content.
+func (m SearchModel) boxWidth() int {
+	return max(min(70, m.width-10), 20)
+}
+
+func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.visible = false
+			m.input.Blur()
+			return m, nil
+		case "up", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+				m.visited = false
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.cursor < len(m.results)-1 {
+				m.cursor++
+				m.visited = false
+			}
+			return m, nil
+		case "tab":
+			m.filter = (m.filter + 1) % len(searchFilters)
+			return m, nil
+		case "shift+tab":
+			m.filter = (m.filter + len(searchFilters) - 1) % len(searchFilters)
+			return m, nil
+		case "ctrl+s":
+			return m.searchServer()
+		case "enter":
+			query := strings.TrimSpace(m.input.Value())
+			filter := searchFilters[m.filter]
+			// A changed query or filter, or no cached matches, runs a
+			// server search; otherwise Enter opens the highlighted result.
+			if query != m.query || filter != m.searched || (m.results == nil && m.err == nil) ||
+				(m.local && len(m.results) == 0) {
+				return m.searchServer()
+			}
+			if len(m.results) == 0 {
+				return m, nil
+			}
+			hit := m.results[m.cursor]
+			m.visited = true
+			m.visible = false
+			m.input.Blur()
+			return m, func() tea.Msg { return searchHitMsg{msg: hit} }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// searchServer asks for a server search with the typed query and filter.
+func (m SearchModel) searchServer() (SearchModel, tea.Cmd) {
+	query := strings.TrimSpace(m.input.Value())
+	filter := searchFilters[m.filter]
+	if query == "" && filter == telegram.SearchAll {
+		return m, nil
+	}
+	m.searching = true
+	return m, func() tea.Msg { return searchMsg{query: query, filter: filter} }
+}
+
+// View renders the overlay box (without full-screen placement).
+func (m SearchModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+	w := m.boxWidth()
+
+	var b strings.Builder
+	b.WriteString(chatListHeaderStyle.Render("Search"))
+	b.WriteString(timeStyle.Render("  filter: " + searchFilters[m.filter].String() + " (Tab)"))
+	b.WriteString("\n\n" + m.input.View() + "\n\n")
+
+	switch {
+	case m.searching:
+		b.WriteString(timeStyle.Render("Searching…"))
+	case m.err != nil:
+		b.WriteString(timeStyle.Render("Search failed: " + m.err.Error()))
+	case m.results == nil:
+		b.WriteString(timeStyle.Render("Enter to search"))
+	case len(m.results) == 0 && m.local:
+		b.WriteString(timeStyle.Render("No cached matches · Enter searches the server"))
+	case len(m.results) == 0:
+		b.WriteString(timeStyle.Render("No results"))
+	default:
+		summary := fmt.Sprintf("%d results", len(m.results))
+		if m.local {
+			summary = fmt.Sprintf("%d cached results · Ctrl+S searches the server", len(m.results))
+		}
+		b.WriteString(timeStyle.Render(summary))
+		start := max(0, min(m.cursor-searchVisible/2, len(m.results)-searchVisible))
+		end := min(start+searchVisible, len(m.results))
+		for i := start; i < end; i++ {
+			b.WriteString("\n" + m.renderResult(m.results[i], i == m.cursor, w))
+		}
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 2).
+		Width(w + 6).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(b.String())
+}
+
+// renderResult renders a hit as a header line and a snippet of its text
+// around the first match.
+func (m SearchModel) renderResult(msg domain.Message, selected bool, w int) string {
+	cursor := "  "
+	nameStyle := inNameStyle
+	if selected {
+		cursor = "> "
+		nameStyle = nameStyle.Bold(true)
+	}
+	header := cursor + timeStyle.Render(msg.Timestamp.Format("Jan 2 15:04")) + " " + nameStyle.Render(msg.SenderName)
+
+	terms := m.Terms()
+	first := ""
+	if len(terms) > 0 {
+		first = terms[0]
+	}
+	text := snippet(msg.Text, first, snippetContext)
+	if text == "" {
+		text = "(media)"
+	}
+	line := "  " + highlightMatches(text, terms...)
+	clamp := lipgloss.NewStyle().MaxWidth(w).MaxHeight(1)
+	return clamp.Render(header) + "\n" + clamp.Render(line)
+}
+
+// snippet flattens text to one line and trims it to the first match of
+// query with some context either side.
+func snippet(text, query string, context int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	start, _, ok := findFold(runes, []rune(query), 0)
+	if !ok {
+		start = 0
+	}
+	from := max(0, start-context)
+	to := min(len(runes), start+len([]rune(query))+context)
+	out := string(runes[from:to])
+	if from > 0 {
+		out = "…" + out
+	}
+	if to < len(runes) {
+		out += "…"
+	}
+	return out
+}
+
+// findFold finds needle in haystack from index from, ignoring case, and
+// returns the match's rune range.
+func findFold(haystack, needle []rune, from int) (int, int, bool) {
+	if len(needle) == 0 {
+		return 0, 0, false
+	}
+	for i := from; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j, r := range needle {
+			if unicode.ToLower(haystack[i+j]) != unicode.ToLower(r) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i, i + len(needle), true
+		}
+	}
+	return 0, 0, false
+}
+
+// highlightMatches shows each case-insensitive match of the terms in s in
+// reverse video. s may already contain ANSI styling, which is skipped
+// when matching and left intact.
+func highlightMatches(s string, terms ...string) string {
+	for _, term := range terms {
+		s = highlightTerm(s, term)
+	}
+	return s
+}
+
+// highlightTerm highlights the matches of a single term.
+func highlightTerm(s, query string) string {
+	if query == "" {
+		return s
+	}
+
+	// Collect the visible runes and where each starts in s.
+	var visible []rune
+	var starts []int
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			i += escapeLen(s[i:])
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		visible = append(visible, r)
+		starts = append(starts, i)
+		i += size
+	}
+	starts = append(starts, len(s))
+
+	var b strings.Builder
+	last := 0
+	needle := []rune(query)
+	for from := 0; ; {
+		lo, hi, ok := findFold(visible, needle, from)
+		if !ok {
+			break
+		}
+		// End the highlight right after the match's last rune, before
+		// any escape sequence that follows it.
+		_, size := utf8.DecodeRuneInString(s[starts[hi-1]:])
+		end := starts[hi-1] + size
+		b.WriteString(s[last:starts[lo]])
+		b.WriteString("\x1b[7m" + s[starts[lo]:end] + "\x1b[27m")
+		last = end
+		from = hi
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// escapeLen returns the length of the escape sequence at the start of s:
+// CSI sequences run to a final byte in 0x40–0x7E, OSC sequences to BEL
+// or ST.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
@@ -0,0 +1,103 @@
+package ui
+
+import "testing"
+
+func TestFindFold(t *testing.T) {
+	tests := []struct {
+		haystack, needle string
+		from             int
+		lo, hi           int
+		ok               bool
+	}{
+		{"Deploy done", "deploy", 0, 0, 6, true},
+		{"deploy DONE", "done", 0, 7, 11, true},
+		{"ÉTÉ été", "été", 0, 0, 3, true}, // case folding beyond ASCII
+		{"ÉTÉ été", "été", 1, 4, 7, true},
+		{"日本語のテキスト", "テキスト", 0, 4, 8, true},
+		{"short", "longer needle", 0, 0, 0, false},
+		{"anything", "", 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		lo, hi, ok := findFold([]rune(tt.haystack), []rune(tt.needle), tt.from)
+		if lo != tt.lo || hi != tt.hi || ok != tt.ok {
+			t.Errorf("findFold(%q, %q, %d) = %d, %d, %v; want %d, %d, %v",
+				tt.haystack, tt.needle, tt.from, lo, hi, ok, tt.lo, tt.hi, tt.ok)
+		}
+	}
+}
+
+func TestEscapeLen(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"\x1b[1mbold", 4},
+		{"\x1b[38;5;205mpink", 11},
+		{"\x1b]8;;https://x.io\atext", 18},     // OSC ended by BEL
+		{"\x1b]8;;https://x.io\x1b\\text", 19}, // OSC ended by ST
+		{"\x1bMrest", 2},
+		{"\x1b[12", 4}, // unterminated
+		{"\x1b", 1},
+	}
+	for _, tt := range tests {
+		if got := escapeLen(tt.s); got != tt.want {
+			t.Errorf("escapeLen(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	const on, off = "\x1b[7m", "\x1b[27m"
+	tests := []struct {
+		name  string
+		s     string
+		terms []string
+		want  string
+	}{
+		{"plain", "deploy done", []string{"done"}, "deploy " + on + "done" + off},
+		{"case folded", "Deploy DONE", []string{"deploy"}, on + "Deploy" + off + " DONE"},
+		{"every match", "ab ab", []string{"ab"}, on + "ab" + off + " " + on + "ab" + off},
+		{"several terms", "red and blue", []string{"red", "blue"}, on + "red" + off + " and " + on + "blue" + off},
+		{"multibyte", "café ÉTÉ", []string{"été"}, "café " + on + "ÉTÉ" + off},
+		{"wide runes", "日本語です", []string{"本語"}, "日" + on + "本語" + off + "です"},
+		{
+			"styled text keeps its escapes",
+			"\x1b[1mde\x1b[0mploy",
+			[]string{"deploy"},
+			"\x1b[1m" + on + "de\x1b[0mploy" + off,
+		},
+		{
+			"match ends before a following escape",
+			"done\x1b[0m!",
+			[]string{"done"},
+			on + "done" + off + "\x1b[0m!",
+		},
+		{"no terms", "deploy", nil, "deploy"},
+		{"empty term", "deploy", []string{""}, "deploy"},
+		{"no match", "deploy", []string{"rollback"}, "deploy"},
+	}
+	for _, tt := range tests {
+		if got := highlightMatches(tt.s, tt.terms...); got != tt.want {
+			t.Errorf("%s: highlightMatches(%q, %q) = %q, want %q", tt.name, tt.s, tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		text, query string
+		context     int
+		want        string
+	}{
+		{"so the deploy is done", "deploy", 4, "…the deploy is …"},
+		{"Deploy\n\tnow", "deploy", 10, "Deploy now"}, // whitespace flattened
+		{"été chaud", "ÉTÉ", 2, "été c…"},
+		{"no match here", "xyz", 4, "no matc…"},
+		{"", "deploy", 4, ""},
+	}
+	for _, tt := range tests {
+		if got := snippet(tt.text, tt.query, tt.context); got != tt.want {
+			t.Errorf("snippet(%q, %q, %d) = %q, want %q", tt.text, tt.query, tt.context, got, tt.want)
+		}
+	}
+}
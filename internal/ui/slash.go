@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+// slashCommand is a command typed in the input as "/name arg", which runs
+// in telecharm instead of being sent. Other slash-prefixed text, such as
+// a bot's commands, is sent as usual.
+type slashCommand struct {
+	name string
+	// args is the argument synopsis shown in completion; "<x>" marks a
+	// required argument and "[x]" an optional one.
+	args    string
+	help    string
+	choices []string // completions for the argument, if it has a fixed set
+	// needsChat marks commands that act on the open chat.
+	needsChat bool
+	run       func(m Model, arg string) (Model, tea.Cmd)
+}
+
+// argRequired reports whether the command can't run without an argument.
+func (c slashCommand) argRequired() bool {
+	return strings.HasPrefix(c.args, "<")
+}
+
+// usage is the command as it should be typed.
+func (c slashCommand) usage() string {
+	if c.args == "" {
+		return "/" + c.name
+	}
+	return "/" + c.name + " " + c.args
+}
+
+// shrug is appended by /shrug.
+const shrug = `¯\_(ツ)_/¯`
+
+// defaultSlashCommands returns the built-in slash commands, in the order
+// completion lists them.
+func defaultSlashCommands() []slashCommand {
+	return []slashCommand{
+		{name: "me", args: "<action>", help: "Send an action, like \"/me waves\"", needsChat: true,
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				return m, m.sendText(m.store.GetActiveChat(), m.client.GetSelfName()+" "+arg, nil)
+			}},
+		{name: "shrug", args: "[text]", help: "Send text followed by " + shrug, needsChat: true,
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				return m, m.sendText(m.store.GetActiveChat(), strings.TrimSpace(arg+" "+shrug), nil)
+			}},
+		{name: "file", args: "<path>", help: "Send a file; further lines are its caption", needsChat: true,
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				path, caption, _ := strings.Cut(arg, "\n")
+				return m, m.sendFile(m.store.GetActiveChat(), expandHome(strings.TrimSpace(path)), strings.TrimSpace(caption))
+			}},
+		{name: "poll", args: "[question]", help: "Create a poll in this chat", needsChat: true,
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.pollForm, cmd = m.pollForm.Show(m.store.GetActiveChat(), strings.TrimSpace(arg))
+				return m, cmd
+			}},
+		{name: "search", args: "[query]", help: "Search this chat", needsChat: true,
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.search, cmd = m.search.SetQuery(arg).Show()
+				if arg != "" {
+					m.search = m.search.SetLocalResults(arg, m.store.Search(m.store.GetActiveChat(), arg, localSearchLimit))
+				}
+				return m, cmd
+			}},
+		{name: "mute", args: "[1h|8h|1d|1w|forever]", help: "Mute notifications for this chat", needsChat: true,
+			choices: []string{"1h", "8h", "1d", "1w", "forever"},
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				until, err := parseMuteUntil(arg, time.Now())
+				if err != nil {
+					m.status.text = fmt.Sprintf("/mute: %v", err)
+					return m, nil
+				}
+				msg := muteChatMsg{chatID: m.store.GetActiveChat(), until: until}
+				return m, func() tea.Msg { return msg }
+			}},
+		{name: "unmute", help: "Unmute this chat", needsChat: true,
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				msg := muteChatMsg{chatID: m.store.GetActiveChat()}
+				return m, func() tea.Msg { return msg }
+			}},
+		{name: "join", args: "<link|@username>", help: "Join a group or channel and open it",
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				client := m.client
+				target := strings.TrimSpace(arg)
+				return m, func() tea.Msg {
+					chat, err := client.JoinChat(context.Background(), target)
+					if err != nil {
+						return localErrorMsg{err: fmt.Errorf("join: %w", err)}
+					}
+					return openResultMsg{chat: chat}
+				}
+			}},
+		{name: "nick", args: "<first> [last]", help: "Change your name",
+			run: func(m Model, arg string) (Model, tea.Cmd) {
+				first, last, _ := strings.Cut(strings.TrimSpace(arg), " ")
+				client := m.client
+				return m, func() tea.Msg {
+					if err := client.SetName(context.Background(), first, strings.TrimSpace(last)); err != nil {
+						return localErrorMsg{err: fmt.Errorf("set name: %w", err)}
+					}
+					return selfNameChangedMsg{name: client.GetSelfName()}
+				}
+			}},
+	}
+}
+
+// findSlashCommand looks up a built-in command by name.
+func findSlashCommand(cmds []slashCommand, name string) (slashCommand, bool) {
+	for _, c := range cmds {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return slashCommand{}, false
+}
+
+// splitSlash splits input like "/mute 1h" into the command name and its
+// argument. It reports false for text that isn't a command.
+func splitSlash(text string) (name, arg string, ok bool) {
+	rest, ok := strings.CutPrefix(text, "/")
+	if !ok || rest == "" || strings.HasPrefix(rest, "/") {
+		return "", "", false
+	}
+	end := strings.IndexAny(rest, " \t\n")
+	if end < 0 {
+		return rest, "", true
+	}
+	return rest[:end], strings.TrimLeft(rest[end:], " \t"), true
+}
+
+// parseMuteUntil turns a /mute argument into when the mute ends. Units
+// beyond Go durations are accepted: "1d" and "1w". No argument mutes
+// forever, like the mute menu's last option.
+func parseMuteUntil(arg string, now time.Time) (time.Time, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "" || arg == "forever" {
+		return muteForever, nil
+	}
+	var d time.Duration
+	if n, err := strconv.Atoi(strings.TrimRight(arg, "dw")); err == nil && n > 0 {
+		switch {
+		case strings.HasSuffix(arg, "d"):
+			d = time.Duration(n) * 24 * time.Hour
+		case strings.HasSuffix(arg, "w"):
+			d = time.Duration(n) * 7 * 24 * time.Hour
+		}
+	}
+	if d == 0 {
+		var err error
+		if d, err = time.ParseDuration(arg); err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("can't mute for %q; try 1h, 8h, 1d, 1w or forever", arg)
+		}
+	}
+	return now.Add(d), nil
+}
+
+// expandHome expands a leading "~/" in a path typed by the user.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+// slashCompletions returns the bot and built-in commands starting with
+// query (the text after "/"). The chat's bot commands list first; those
+// named like a built-in are inserted with the "//" escape so they still
+// reach the bot.
+func slashCompletions(builtins []slashCommand, bots []domain.BotCommand, query string) []completionItem {
+	q := strings.ToLower(query)
+	var items []completionItem
+	for _, b := range bots {
+		if !strings.HasPrefix(strings.ToLower(b.Command), q) {
+			continue
+		}
+		insert := "/" + b.Command
+		if _, builtin := findSlashCommand(builtins, b.Command); builtin {
+			insert = "/" + insert
+		}
+		items = append(items, completionItem{label: insert, detail: b.Description, insert: insert})
+	}
+	for _, c := range builtins {
+		if strings.HasPrefix(c.name, q) {
+			items = append(items, completionItem{
+				label:  "/" + c.name,
+				detail: strings.TrimSpace(c.args + "  " + c.help),
+				insert: "/" + c.name + " ",
+			})
+		}
+	}
+	if len(items) > maxCompletions {
+		items = items[:maxCompletions]
+	}
+	return items
+}
+
+// choiceCompletions returns a command's argument choices starting with
+// query. A choice typed in full isn't offered, so Enter runs the command.
+func choiceCompletions(c slashCommand, query string) []completionItem {
+	var items []completionItem
+	q := strings.ToLower(query)
+	for _, choice := range c.choices {
+		if choice == q {
+			return nil
+		}
+		if strings.HasPrefix(choice, q) {
+			items = append(items, completionItem{label: choice, detail: c.help, insert: choice})
+		}
+	}
+	return items
+}
+
+// slashHintView renders the synopsis of a built-in command while its
+// argument is typed.
+func slashHintView(c slashCommand, width int) string {
+	line := c.usage()
+	if c.help != "" {
+		line += "  " + timeStyle.Render(c.help)
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForegroundBlend(rainbowBlend...).
+		Padding(0, 1).
+		MaxWidth(width).
+		Render(line)
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSplitSlash(t *testing.T) {
+	tests := []struct {
+		text, name, arg string
+		ok              bool
+	}{
+		{"/mute 1h", "mute", "1h", true},
+		{"/mute  1h", "mute", "1h", true},
+		{"/unmute", "unmute", "", true},
+		{"/file notes.txt\nthe caption", "file", "notes.txt\nthe caption", true},
+		{"//x", "", "", false}, // escaped, sent as "/x"
+		{"/", "", "", false},
+		{"hello /mute", "", "", false},
+	}
+	for _, tt := range tests {
+		name, arg, ok := splitSlash(tt.text)
+		if name != tt.name || arg != tt.arg || ok != tt.ok {
+			t.Errorf("splitSlash(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.text, name, arg, ok, tt.name, tt.arg, tt.ok)
+		}
+	}
+}
+
+func TestParseMuteUntil(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		arg  string
+		want time.Time
+		ok   bool
+	}{
+		{"1h", now.Add(time.Hour), true},
+		{"90m", now.Add(90 * time.Minute), true},
+		{"2d", now.Add(48 * time.Hour), true},
+		{"1w", now.Add(7 * 24 * time.Hour), true},
+		{" 1W ", now.Add(7 * 24 * time.Hour), true},
+		{"forever", muteForever, true},
+		{"", muteForever, true},
+		{"0h", time.Time{}, false},
+		{"0d", time.Time{}, false},
+		{"-1h", time.Time{}, false},
+		{"abc", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseMuteUntil(tt.arg, now)
+		if (err == nil) != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseMuteUntil(%q) = %v, %v; want %v (ok %v)", tt.arg, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestChoiceCompletions(t *testing.T) {
+	mute, _ := findSlashCommand(defaultSlashCommands(), "mute")
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"1h", "8h", "1d", "1w", "forever"}},
+		{"1", []string{"1h", "1d", "1w"}},
+		{"F", []string{"forever"}},
+		{"1h", nil}, // already complete
+		{"2d", nil},
+	}
+	for _, tt := range tests {
+		items := choiceCompletions(mute, tt.query)
+		var got []string
+		for _, it := range items {
+			got = append(got, it.insert)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("choiceCompletions(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -82,7 +82,6 @@ func (s SplashModel) ConnReady() SplashModel {
 }
 
 // View renders the splash box (without full-screen placement).
-// Use BoxOffset to get the X/Y for centering via the Layer API.
 func (s SplashModel) View() string {
 	if !s.visible || s.width == 0 || s.height == 0 {
 		return ""
@@ -96,20 +95,3 @@ func (s SplashModel) View() string {
 
 	return style.Render(splashArt)
 }
-
-// BoxOffset returns the (x, y) needed to center the splash box
-// within the terminal dimensions.
-func (s SplashModel) BoxOffset() (int, int) {
-	box := s.View()
-	bw := lipgloss.Width(box)
-	bh := lipgloss.Height(box)
-	x := (s.width - bw) / 2
-	y := (s.height - bh) / 2
-	if x < 0 {
-		x = 0
-	}
-	if y < 0 {
-		y = 0
-	}
-	return x, y
-}
@@ -1,20 +1,22 @@
 package ui
 
 import (
+	"image/color"
 	"strings"
 	"time"
 
 	"charm.land/lipgloss/v2"
 )
 
+// Status bar colors, set by SetTheme.
 var (
-	// Dark gray background matching the lipgloss example
-	statusBarBg = lipgloss.Color("#353533")
-	// Bright magenta for the status pill and time highlight
-	statusPillBg    = lipgloss.Color("#FF5FAF")
-	statusPillBgOff = lipgloss.Color("#6C5098")
-	// Teal/cyan for the time pill
-	statusTimeBg = lipgloss.Color("#6124DF")
+	statusBarBg     color.Color
+	statusTextColor color.Color
+	statusPillBg    color.Color
+	statusPillBgOff color.Color
+	statusTimeBg    color.Color
+	statusUserBg    color.Color
+	pillTextColor   color.Color
 )
 
 type statusModel struct {
@@ -60,7 +62,7 @@ func (m statusModel) View() string {
 	}
 	pillStyle := lipgloss.NewStyle().
 		Background(pillBg).
-		Foreground(lipgloss.Color("#FFFFFF")).
+		Foreground(pillTextColor).
 		Bold(true).
 		Padding(0, 1)
 	pill := pillStyle.Render(strings.ToUpper(m.text))
@@ -68,7 +70,7 @@ func (m statusModel) View() string {
 	// Chat title
 	titleStyle := lipgloss.NewStyle().
 		Background(statusBarBg).
-		Foreground(lipgloss.Color("#FFFFFF")).
+		Foreground(statusTextColor).
 		Bold(true).
 		Padding(0, 1)
 	title := titleStyle.Render(m.chatTitle)
@@ -76,15 +78,15 @@ func (m statusModel) View() string {
 	// Current time pill
 	timeStyle := lipgloss.NewStyle().
 		Background(statusTimeBg).
-		Foreground(lipgloss.Color("#FFFFFF")).
+		Foreground(pillTextColor).
 		Bold(true).
 		Padding(0, 1)
 	timePill := timeStyle.Render(time.Now().Format("15:04"))
 
 	// User name — medium purple highlight, distinct from bar background
 	userStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#7B5EA7")).
-		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(statusUserBg).
+		Foreground(pillTextColor).
 		Bold(true).
 		Padding(0, 1)
 	userPill := userStyle.Render(m.userName)
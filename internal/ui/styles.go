@@ -7,28 +7,44 @@ import (
 	"charm.land/lipgloss/v2"
 )
 
+// The colors and styles below are set from the active theme by SetTheme.
 var (
-	daySeparatorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	timeStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	typingStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
-	outNameStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Bold(true)
-	inNameStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("4")).Bold(true)
+	daySeparatorStyle   lipgloss.Style
+	timeStyle           lipgloss.Style
+	selectedTimeStyle   lipgloss.Style // the selected message's timestamp
+	typingStyle         lipgloss.Style
+	outNameStyle        lipgloss.Style
+	inNameStyle         lipgloss.Style
+	chatListHeaderStyle = lipgloss.NewStyle().Bold(true)
+	mentionNameStyle    lipgloss.Style
+	mentionBadgeStyle   lipgloss.Style
+	selectedMarkerStyle lipgloss.Style
+	reactionStyle       lipgloss.Style
+	reactionChosenStyle lipgloss.Style
+	forwardedStyle      lipgloss.Style
+	pinLabelStyle       lipgloss.Style
+	gapStyle            lipgloss.Style
+	errorStyle          lipgloss.Style
+	draftLabelStyle     lipgloss.Style
+	listSelectedStyle   lipgloss.Style // the item under the cursor in lists and menus
+	buttonStyle         lipgloss.Style // bot keyboard buttons
+	buttonSelectedStyle = lipgloss.NewStyle().Reverse(true)
 
-	dimColor = lipgloss.Color("240") // gray
+	dimColor     color.Color
+	subtleColor  color.Color
+	mentionColor color.Color
 
-	// Rainbow gradient colors for focused borders (wraps back to start).
-	rainbowBlend = []color.Color{
-		lipgloss.Color("#FF6B9D"), // pink
-		lipgloss.Color("#9B59B6"), // purple
-		lipgloss.Color("#3498DB"), // blue
-		lipgloss.Color("#2ECC71"), // green
-		lipgloss.Color("#FF6B9D"), // pink (wrap)
-	}
+	// Gradient colors for focused borders (wraps back to start).
+	rainbowBlend []color.Color
 )
 
 // applyBorderColor applies either the rainbow blend (focused) or dim border color.
+// Without color, the focused pane gets a thick border instead.
 func applyBorderColor(s lipgloss.Style, focused bool) lipgloss.Style {
 	if focused {
+		if monochrome {
+			s = s.BorderStyle(lipgloss.ThickBorder())
+		}
 		return s.BorderForegroundBlend(rainbowBlend...)
 	}
 	return s.BorderForeground(dimColor)
@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/danhigham/telecharm/internal/config"
+	"github.com/danhigham/telecharm/internal/domain"
+)
+
+const (
+	// switcherVisible is how many chats the switcher lists at once.
+	switcherVisible = 10
+	// frecencyWeight scales a chat's frecency into fuzzy match points, so
+	// often-used chats win between similar matches.
+	frecencyWeight = 10
+)
+
+// switcherMatch is a chat ranked against the switcher's query.
+type switcherMatch struct {
+	chat  domain.ChatInfo
+	score float64
+}
+
+// SwitcherModel renders a centered overlay for jumping to any chat by
+// fuzzy matching its title or username. With no query it lists recently
+// and frequently opened chats first.
+type SwitcherModel struct {
+	visible  bool
+	input    textinput.Model
+	chats    []domain.ChatInfo
+	frecency map[int64]float64
+	matches  []switcherMatch
+	cursor   int
+
+	width, height int
+}
+
+// NewSwitcherModel creates a hidden chat switcher.
+func NewSwitcherModel() SwitcherModel {
+	ti := textinput.New()
+	ti.Placeholder = "jump to chat"
+	ti.Prompt = "→ "
+	ti.CharLimit = 64
+	return SwitcherModel{input: ti}
+}
+
+// IsVisible reports whether the switcher is showing.
+func (m SwitcherModel) IsVisible() bool {
+	return m.visible
+}
+
+// Show opens the switcher over the given chats with an empty query.
+func (m SwitcherModel) Show(chats []domain.ChatInfo, frecency *config.Frecency) (SwitcherModel, tea.Cmd) {
+	m.visible = true
+	m.chats = chats
+	now := time.Now()
+	m.frecency = make(map[int64]float64, len(chats))
+	for _, c := range chats {
+		if s := frecency.Score(c.ID, now); s > 0 {
+			m.frecency[c.ID] = s
+		}
+	}
+	m.input.SetValue("")
+	m = m.rank()
+	return m, m.input.Focus()
+}
+
+// SetSize updates the terminal dimensions for centering.
+func (m SwitcherModel) SetSize(w, h int) SwitcherModel {
+	m.width = w
+	m.height = h
+	m.input.SetWidth(m.boxWidth() - 4)
+	return m
+}
+
+// boxWidth is the width of the overlay's content.
+func (m SwitcherModel) boxWidth() int {
+	return max(min(50, m.width-10), 20)
+}
+
+func (m SwitcherModel) Update(msg tea.Msg) (SwitcherModel, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc", "ctrl+k":
+			m.visible = false
+			m.input.Blur()
+			return m, nil
+		case "up", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down", "ctrl+n", "tab":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case "enter":
+			if len(m.matches) == 0 {
+				return m, nil
+			}
+			chatID := m.matches[m.cursor].chat.ID
+			m.visible = false
+			m.input.Blur()
+			return m, func() tea.Msg { return ChatSelectedMsg{ChatID: chatID} }
+		}
+	}
+
+	query := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != query {
+		m = m.rank()
+	}
+	return m, cmd
+}
+
+// rank orders the chats against the current query. Without a query,
+// chats opened before come first by frecency, then the rest in chat list
+// order.
+func (m SwitcherModel) rank() SwitcherModel {
+	query := strings.TrimPrefix(strings.TrimSpace(m.input.Value()), "@")
+	m.matches = nil
+	for _, c := range m.chats {
+		score := m.frecency[c.ID] * frecencyWeight
+		if query != "" {
+			s, ok := fuzzyScore(query, c.Title)
+			if us, uok := fuzzyScore(query, c.Username); uok && (!ok || us > s) {
+				s, ok = us, true
+			}
+			if !ok {
+				continue
+			}
+			score += float64(s)
+		}
+		m.matches = append(m.matches, switcherMatch{chat: c, score: score})
+	}
+	sort.SliceStable(m.matches, func(i, j int) bool { return m.matches[i].score > m.matches[j].score })
+	m.cursor = 0
+	return m
+}
+
+// fuzzyScore matches pattern against s as a case-insensitive subsequence.
+// Matches score higher when their characters are consecutive, start
+// words, or start s; skipped characters cost a little.
+func fuzzyScore(pattern, s string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	r := []rune(s)
+	if len(p) == 0 || len(r) == 0 {
+		return 0, false
+	}
+
+	// Try every starting position of the first character and keep the
+	// best greedy match.
+	best, found := 0, false
+	for start := range r {
+		if unicode.ToLower(r[start]) != p[0] {
+			continue
+		}
+		score, pi, prev := 0, 0, -1
+		for i := start; i < len(r) && pi < len(p); i++ {
+			if unicode.ToLower(r[i]) != p[pi] {
+				continue
+			}
+			score++
+			switch {
+			case i == 0:
+				score += 10
+			case !unicode.IsLetter(r[i-1]) && !unicode.IsDigit(r[i-1]):
+				score += 8
+			case unicode.IsLower(r[i-1]) && unicode.IsUpper(r[i]):
+				score += 6
+			}
+			if prev >= 0 {
+				if i == prev+1 {
+					score += 5
+				} else {
+					score -= min(i-prev-1, 3)
+				}
+			}
+			prev = i
+			pi++
+		}
+		if pi == len(p) && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// View renders the overlay box (without full-screen placement).
+func (m SwitcherModel) View() string {
+	if !m.visible || m.width == 0 || m.height == 0 {
+		return ""
+	}
+	w := m.boxWidth()
+
+	var b strings.Builder
+	b.WriteString(chatListHeaderStyle.Render("Switch chat"))
+	b.WriteString("\n\n" + m.input.View() + "\n\n")
+
+	if len(m.matches) == 0 {
+		b.WriteString(timeStyle.Render("No matching chats"))
+	} else {
+		clamp := lipgloss.NewStyle().MaxWidth(w).MaxHeight(1)
+		start := max(0, min(m.cursor-switcherVisible/2, len(m.matches)-switcherVisible))
+		end := min(start+switcherVisible, len(m.matches))
+		var lines []string
+		for i := start; i < end; i++ {
+			c := m.matches[i].chat
+			cursor := "  "
+			titleStyle := lipgloss.NewStyle()
+			if i == m.cursor {
+				cursor = "> "
+				titleStyle = titleStyle.Inherit(listSelectedStyle)
+			}
+			title := c.Title
+			if c.UnreadCount > 0 {
+				title = fmt.Sprintf("%s (%d)", c.Title, c.UnreadCount)
+			}
+			line := cursor + titleStyle.Render(title)
+			if c.Username != "" {
+				line += timeStyle.Render(" @" + c.Username)
+			}
+			lines = append(lines, clamp.Render(line))
+		}
+		b.WriteString(strings.Join(lines, "\n"))
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 2).
+		Width(w + 6).
+		BorderForegroundBlend(rainbowBlend...)
+
+	return style.Render(b.String())
+}
@@ -0,0 +1,56 @@
+package ui
+
+import "testing"
+
+func TestFuzzyScore_Match(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"ops", "Ops Team", true},
+		{"OPS", "ops team", true},          // case-insensitive
+		{"otm", "Ops Team", true},          // subsequence across words
+		{"élo", "Équipe Lyon Ouest", true}, // multibyte case folding
+		{"日本", "日本語チャット", true},
+		{"spo", "Ops", false}, // order matters
+		{"opss", "Ops", false},
+		{"", "Ops", false},
+		{"ops", "", false},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.pattern, tt.s); ok != tt.want {
+			t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tt.pattern, tt.s, ok, tt.want)
+		}
+	}
+}
+
+func TestFuzzyScore_Ranking(t *testing.T) {
+	// Each pair lists a better match first.
+	tests := []struct {
+		pattern, better, worse string
+	}{
+		{"ops", "Ops", "Dev Ops"},    // start of the name
+		{"ops", "Dev Ops", "Devops"}, // start of a word
+		{"ops", "Lopsy", "Loxpxsy"},  // consecutive characters
+		{"dt", "DevTeam", "Devteam"}, // camel-case boundary
+		{"ab", "a-b", "a----b"},      // fewer skipped characters
+	}
+	for _, tt := range tests {
+		b, okB := fuzzyScore(tt.pattern, tt.better)
+		w, okW := fuzzyScore(tt.pattern, tt.worse)
+		if !okB || !okW {
+			t.Errorf("%q: expected both %q and %q to match", tt.pattern, tt.better, tt.worse)
+			continue
+		}
+		if b <= w {
+			t.Errorf("%q: %q scored %d, not above %q at %d", tt.pattern, tt.better, b, tt.worse, w)
+		}
+	}
+
+	// A weak early match of the first character doesn't hide a better
+	// one later on.
+	early, _ := fuzzyScore("team", "tx team")
+	if later, _ := fuzzyScore("team", "x team"); early != later {
+		t.Errorf("tx team scored %d, x team %d; want the best start to win", early, later)
+	}
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"image/color"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/colorprofile"
+
+	"github.com/danhigham/telecharm/internal/config"
+)
+
+var (
+	// glamourStyle is the glamour style used to render markdown.
+	glamourStyle string
+
+	activeTheme  config.Theme
+	colorProfile = colorprofile.TrueColor
+
+	// monochrome is set when the terminal shows no colors; styles then
+	// fall back on bold, underline and reverse video.
+	monochrome bool
+)
+
+func init() {
+	t, _ := config.BuiltinTheme(config.ThemeDark)
+	SetTheme(t)
+}
+
+// themeColor converts a theme color; empty means the terminal default.
+func themeColor(s string) color.Color {
+	if s == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(s)
+}
+
+// SetTheme sets the colors used by every view. Call it before the
+// program starts; views already rendered are not restyled.
+func SetTheme(t config.Theme) {
+	activeTheme = t
+	applyTheme()
+}
+
+// setColorProfile adapts the styles to what the terminal can show.
+func setColorProfile(p colorprofile.Profile) {
+	colorProfile = p
+	applyTheme()
+}
+
+// applyTheme builds the styles from the active theme and color profile.
+func applyTheme() {
+	t := activeTheme
+	glamourStyle = t.Glamour
+	if glamourStyle == "" {
+		glamourStyle = config.ThemeDark
+	}
+
+	dimColor = themeColor(t.Dim)
+	subtleColor = themeColor(t.Subtle)
+	mentionColor = themeColor(t.Mention)
+	receivedBubbleColor = themeColor(t.ReceivedBubble)
+	sentBubbleColor = themeColor(t.SentBubble)
+	selectedBubbleColor = themeColor(t.SelectedBubble)
+
+	// A single color gives a solid border; none leaves borders uncolored.
+	rainbowBlend = nil
+	for _, c := range t.Border {
+		rainbowBlend = append(rainbowBlend, themeColor(c))
+	}
+
+	daySeparatorStyle = lipgloss.NewStyle().Foreground(dimColor)
+	timeStyle = lipgloss.NewStyle().Foreground(dimColor)
+	selectedTimeStyle = timeStyle
+	typingStyle = lipgloss.NewStyle().Foreground(dimColor).Italic(true)
+	outNameStyle = lipgloss.NewStyle().Foreground(themeColor(t.Outgoing)).Bold(true)
+	inNameStyle = lipgloss.NewStyle().Foreground(themeColor(t.Incoming)).Bold(true)
+	mentionNameStyle = lipgloss.NewStyle().Foreground(mentionColor).Bold(true)
+	mentionBadgeStyle = lipgloss.NewStyle().Foreground(mentionColor).Bold(true)
+	selectedMarkerStyle = lipgloss.NewStyle().Foreground(selectedBubbleColor)
+	reactionStyle = lipgloss.NewStyle().Foreground(subtleColor)
+	reactionChosenStyle = lipgloss.NewStyle().Foreground(sentBubbleColor).Bold(true)
+	forwardedStyle = lipgloss.NewStyle().Foreground(themeColor(t.Forward)).Italic(true)
+	pinLabelStyle = lipgloss.NewStyle().Foreground(themeColor(t.Accent)).Bold(true)
+	gapStyle = lipgloss.NewStyle().Foreground(dimColor).Italic(true)
+	errorStyle = lipgloss.NewStyle().Foreground(themeColor(t.Error))
+	draftLabelStyle = lipgloss.NewStyle().Foreground(themeColor(t.Error)).Italic(true)
+	listSelectedStyle = lipgloss.NewStyle().Foreground(themeColor(t.Accent)).Bold(true)
+	buttonStyle = lipgloss.NewStyle().Foreground(themeColor(t.Accent))
+
+	statusBarBg = themeColor(t.StatusBar)
+	statusTextColor = themeColor(t.StatusText)
+	statusPillBg = themeColor(t.StatusPill)
+	statusPillBgOff = themeColor(t.StatusPillOff)
+	statusTimeBg = themeColor(t.StatusClock)
+	statusUserBg = themeColor(t.StatusUser)
+	pillTextColor = themeColor(t.PillText)
+
+	tableBorderColor = themeColor(t.TableBorder)
+	tableHeaderStyle = lipgloss.NewStyle().Foreground(tableBorderColor).Bold(true).Align(lipgloss.Center)
+	tableOddRowStyle = tableCellStyle.Foreground(themeColor(t.TableOddRow))
+	tableEvenRowStyle = tableCellStyle.Foreground(themeColor(t.TableEvenRow))
+
+	// A gradient quantized to 16 colors bands badly; use its first color.
+	if colorProfile <= colorprofile.ANSI && len(rainbowBlend) > 1 {
+		rainbowBlend = rainbowBlend[:1]
+	}
+
+	// Without color, keep our messages, the selection and the focused
+	// pane apart with text attributes; unread chats are already bold.
+	monochrome = colorProfile <= colorprofile.ASCII
+	if monochrome {
+		outNameStyle = outNameStyle.Underline(true)
+		listSelectedStyle = listSelectedStyle.Reverse(true)
+		selectedTimeStyle = timeStyle.Reverse(true)
+	}
+}